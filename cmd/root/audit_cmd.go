@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+
+	"tokenwatch/internal/config"
+	"tokenwatch/pkg/models"
+	"tokenwatch/pkg/pricing"
+	"tokenwatch/pkg/providers"
+	"tokenwatch/pkg/tokenwatch"
+	"tokenwatch/pkg/utils"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// defaultAuditThreshold is the percentage difference between billed and
+// estimated cost above which a model is flagged
+const defaultAuditThreshold = 10.0
+
+// auditEntry compares the billed cost of one model with the cost estimated
+// from the local rate table
+type auditEntry struct {
+	Model      string
+	Billed     float64
+	Estimated  float64
+	Difference float64 // Billed - Estimated
+	Percent    float64 // Difference as a percentage of Estimated; 0 when Estimated is 0
+	Priced     bool    // false when the model is not in the rate table
+	Flagged    bool
+}
+
+// auditModels estimates each model's cost from its token counts and compares
+// it with the billed cost. A model is flagged when the difference exceeds
+// threshold percent of the estimate, or when it was billed but estimates to
+// nothing. Models missing from the rate table are never flagged.
+func auditModels(stats []models.ModelStats, rates *pricing.RateTable, threshold float64) []auditEntry {
+	entries := make([]auditEntry, 0, len(stats))
+	for _, m := range stats {
+		entry := auditEntry{Model: m.Model, Billed: m.Cost}
+
+		rate, err := rates.Lookup(m.Model)
+		if err != nil {
+			entries = append(entries, entry)
+			continue
+		}
+
+		entry.Priced = true
+		entry.Estimated = rate.Cost(m.InputTokens, m.OutputTokens)
+		entry.Difference = entry.Billed - entry.Estimated
+		if entry.Estimated > 0 {
+			entry.Percent = entry.Difference / entry.Estimated * 100
+			entry.Flagged = math.Abs(entry.Percent) > threshold
+		} else {
+			entry.Flagged = entry.Billed > 0
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Compare billed costs with local estimates",
+	Long: `Compare each model's billed cost with the cost estimated from its token
+counts and the local rate table (see 'tokenwatch rates').
+
+Models whose billed and estimated costs differ by more than --threshold
+percent are flagged. A large gap usually means the local rates are out of
+date, or that usage was billed at a different tier (batch, cached input,
+fine-tuned models) than the list price.
+
+Examples:
+  tokenwatch audit                     # Last 7 days
+  tokenwatch audit --period 30d --threshold 5`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Init(); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		if config.GetAPIKey("openai") == "" {
+			return utils.NewAuthError("OpenAI not configured", "openai")
+		}
+
+		period, _ := cmd.Flags().GetString("period")
+		threshold, _ := cmd.Flags().GetFloat64("threshold")
+		debug, _ := cmd.Flags().GetBool("debug")
+
+		if err := validatePeriod(period); err != nil {
+			return err
+		}
+		if period == "" {
+			period = "7d"
+		}
+		if threshold < 0 {
+			return utils.NewValidationError("threshold", "must not be negative")
+		}
+
+		provider := getProvider("openai")
+		if provider == nil {
+			return fmt.Errorf("OpenAI provider not available")
+		}
+
+		startTime, endTime := providers.GetPeriodTimeRange(period, false)
+		consumptions, err := provider.GetConsumption(startTime, endTime, false, debug)
+		if err != nil {
+			return fmt.Errorf("failed to get consumption data: %w", err)
+		}
+		// Without billed costs there is nothing to audit
+		pricings, err := provider.GetPricing(startTime, endTime, false, debug)
+		if err != nil {
+			return fmt.Errorf("failed to get pricing data: %w", err)
+		}
+
+		stats, err := tokenwatch.AggregateModelStats(consumptions, pricings)
+		if err != nil {
+			return err
+		}
+
+		displayAudit(auditModels(stats, rateTable(), threshold), period, threshold)
+		return nil
+	},
+}
+
+// displayAudit prints the audit table followed by a one-line verdict
+func displayAudit(entries []auditEntry, period string, threshold float64) {
+	fmt.Printf(icon("audit")+"COST AUDIT - Last %s\n", period)
+
+	columns := []tableColumn{
+		{header: "Model"},
+		{header: "Billed", numeric: true},
+		{header: "Estimated", numeric: true},
+		{header: "Difference", numeric: true},
+		{header: "Diff %", numeric: true},
+		{header: "Status"},
+	}
+	table := newTable(os.Stdout, columns)
+	numbers := newNumberFormat(config.DisplaySettings())
+
+	flagged, unpriced := 0, 0
+	for _, e := range entries {
+		if !e.Priced {
+			unpriced++
+			table.AddRow([]string{
+				color.YellowString("%s", e.Model),
+				color.CyanString("%s", numbers.cost(e.Billed, 4)),
+				color.HiBlackString("-"),
+				color.HiBlackString("-"),
+				color.HiBlackString("-"),
+				color.HiBlackString("no local rate"),
+			})
+			continue
+		}
+
+		status := color.GreenString("ok")
+		if e.Flagged {
+			flagged++
+			status = color.RedString("mismatch")
+		}
+		percent := color.HiBlackString("-")
+		if e.Estimated > 0 {
+			percent = color.HiBlackString("%+.1f%%", e.Percent)
+		}
+		table.AddRow([]string{
+			color.YellowString("%s", e.Model),
+			color.CyanString("%s", numbers.cost(e.Billed, 4)),
+			color.CyanString("%s", numbers.cost(e.Estimated, 4)),
+			color.HiBlackString("%s", numbers.signedCost(e.Difference)),
+			percent,
+			status,
+		})
+	}
+	if err := table.Render(); err != nil {
+		fmt.Printf(icon("error")+"Error: %v\n", err)
+	}
+
+	fmt.Println()
+	if flagged > 0 {
+		fmt.Printf(icon("warning")+"%d of %d models differ from the local estimate by more than %.1f%%\n", flagged, len(entries)-unpriced, threshold)
+	} else {
+		fmt.Printf(icon("ok")+"All priced models are within %.1f%% of the local estimate\n", threshold)
+	}
+	if unpriced > 0 {
+		fmt.Println(color.HiBlackString(icon("info")+"%d models have no local rate and were not audited", unpriced))
+	}
+}
+
+func init() {
+	auditCmd.Flags().StringP("period", "p", "7d", "Time period: 1d, 7d, 30d, 90d, 1y, all, q1-q4, last-quarter")
+	auditCmd.Flags().Float64("threshold", defaultAuditThreshold, "Flag models whose billed cost differs from the estimate by more than this percentage")
+	auditCmd.Flags().BoolP("debug", "d", false, "Enable debug logging for API calls")
+	RootCmd.AddCommand(auditCmd)
+}
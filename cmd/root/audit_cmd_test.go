@@ -0,0 +1,54 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"tokenwatch/pkg/models"
+	"tokenwatch/pkg/pricing"
+)
+
+func TestAuditModels(t *testing.T) {
+	rates := pricing.NewRateTable([]pricing.Rate{
+		{Model: "gpt-4o", InputPer1M: 2.5, OutputPer1M: 10},
+		{Model: "o1", InputPer1M: 15, OutputPer1M: 60},
+	})
+	stats := []models.ModelStats{
+		// 1M input + 100K output at gpt-4o rates is $3.50
+		{Model: "gpt-4o-2024-08-06", InputTokens: 1000000, OutputTokens: 100000, Cost: 3.60},
+		{Model: "gpt-4o", InputTokens: 1000000, OutputTokens: 100000, Cost: 4.20},
+		{Model: "o1", InputTokens: 100000, OutputTokens: 10000, Cost: 1.80},
+		{Model: "o1", Cost: 0.25}, // billed without tokens
+		{Model: "o1", Cost: 0},
+		{Model: "claude-3", InputTokens: 1000, Cost: 9},
+	}
+
+	want := []auditEntry{
+		{Model: "gpt-4o-2024-08-06", Billed: 3.60, Estimated: 3.50, Difference: 0.10, Percent: 2.857142857, Priced: true},
+		{Model: "gpt-4o", Billed: 4.20, Estimated: 3.50, Difference: 0.70, Percent: 20, Priced: true, Flagged: true},
+		{Model: "o1", Billed: 1.80, Estimated: 2.10, Difference: -0.30, Percent: -14.285714286, Priced: true, Flagged: true},
+		{Model: "o1", Billed: 0.25, Difference: 0.25, Priced: true, Flagged: true},
+		{Model: "o1", Priced: true},
+		{Model: "claude-3", Billed: 9},
+	}
+	got := auditModels(stats, rates, 10)
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(got), len(want))
+	}
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-6 }
+	for i, w := range want {
+		g := got[i]
+		if g.Model != w.Model || g.Priced != w.Priced || g.Flagged != w.Flagged ||
+			!near(g.Billed, w.Billed) || !near(g.Estimated, w.Estimated) ||
+			!near(g.Difference, w.Difference) || !near(g.Percent, w.Percent) {
+			t.Errorf("entry %d = %+v, want %+v", i, g, w)
+		}
+	}
+
+	// At 15% the 14% difference passes while the 20% one is still flagged
+	for _, e := range auditModels(stats[1:3], rates, 15) {
+		if e.Flagged != (e.Model == "gpt-4o") {
+			t.Errorf("threshold 15%%: %s flagged = %v", e.Model, e.Flagged)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"tokenwatch/internal/config"
+	"tokenwatch/pkg/export"
+	"tokenwatch/pkg/providers"
+	"tokenwatch/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// batchRequest is one parsed line of a batch file
+type batchRequest struct {
+	spec      string // the line as written, used as the report period
+	startTime time.Time
+	endTime   time.Time
+}
+
+// parseBatchSpec parses a single request spec: a period (7d), a Go duration
+// (36h), or an inclusive UTC date range (2024-01-01..2024-01-31)
+func parseBatchSpec(spec string) (batchRequest, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 1 {
+		if strings.HasPrefix(spec, "--org") {
+			return batchRequest{}, fmt.Errorf("--org is not supported: only one organization can be configured")
+		}
+		return batchRequest{}, fmt.Errorf("expected a single period, duration, or date range, got %q", spec)
+	}
+	spec = fields[0]
+
+	if providers.IsDateRange(spec) {
+		start, end, err := providers.ParseDateRange(spec)
+		if err != nil {
+			return batchRequest{}, err
+		}
+		return batchRequest{spec: spec, startTime: start, endTime: end}, nil
+	}
+
+	if validatePeriod(spec) != nil {
+		if _, err := providers.ParseSince(spec); err != nil {
+			return batchRequest{}, fmt.Errorf("invalid period %q: use 1d, 7d, 30d, 90d, 1y, all, q1-q4, last-quarter, a Go duration such as 36h, or a date range such as 2024-01-01..2024-01-31", spec)
+		}
+	}
+	startTime, endTime := providers.GetPeriodTimeRange(spec, false)
+	return batchRequest{spec: spec, startTime: startTime, endTime: endTime}, nil
+}
+
+// readBatchRequests parses one request spec per line of r. Blank lines and
+// lines starting with # are skipped; errors name the offending line.
+func readBatchRequests(r io.Reader) ([]batchRequest, error) {
+	var requests []batchRequest
+
+	scanner := bufio.NewScanner(r)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		request, err := parseBatchSpec(line)
+		if err != nil {
+			return nil, utils.NewValidationError("batch spec", fmt.Sprintf("line %d: %v", lineNum, err))
+		}
+		requests = append(requests, request)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read batch specs: %w", err)
+	}
+
+	return requests, nil
+}
+
+var batchCmd = &cobra.Command{
+	Use:   "batch [file]",
+	Short: "Run many usage reports in one process",
+	Long: `Read one request spec per line from a file (or stdin) and write a JSON array
+with one usage report per spec, in order.
+
+Each line is one of:
+  7d                        A period: 1d, 7d, 30d, 90d, 1y, all, q1-q4, last-quarter
+  36h                       A Go duration up to now
+  2024-01-01..2024-01-31    An inclusive range of UTC dates
+
+Blank lines and lines starting with # are ignored. All reports share one API
+client and cache, so overlapping requests are only fetched once.
+
+Examples:
+  tokenwatch batch periods.txt
+  printf '1d\n7d\n2024-01-01..2024-01-31\n' | tokenwatch batch`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Init(); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		if config.GetAPIKey("openai") == "" {
+			return utils.NewAuthError("OpenAI not configured", "openai")
+		}
+
+		input := cmd.InOrStdin()
+		if len(args) == 1 {
+			file, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open batch file: %w", err)
+			}
+			defer file.Close()
+			input = file
+		}
+
+		requests, err := readBatchRequests(input)
+		if err != nil {
+			return err
+		}
+
+		provider := getProvider("openai")
+		if provider == nil {
+			return fmt.Errorf("OpenAI provider not available")
+		}
+
+		debug, _ := cmd.Flags().GetBool("debug")
+		reports, err := runBatch(provider, requests, debug)
+		if err != nil {
+			return err
+		}
+		return export.WriteJSON(os.Stdout, reports, jsonPretty(cmd))
+	},
+}
+
+// runBatch builds a report for each request in order
+func runBatch(provider providers.Provider, requests []batchRequest, debug bool) ([]export.UsageReport, error) {
+	reports := make([]export.UsageReport, 0, len(requests))
+	for _, request := range requests {
+		report, err := buildUsageReportRange(provider, request.spec, request.startTime, request.endTime, false, debug)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", request.spec, err)
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+func init() {
+	batchCmd.Flags().BoolP("debug", "d", false, "Enable debug logging for API calls")
+	batchCmd.Flags().Bool("json-pretty", false, "Indent JSON output (default when stdout is a terminal)")
+	batchCmd.Flags().Bool("compact-json", false, "Write JSON output on a single line (default when piped)")
+	batchCmd.MarkFlagsMutuallyExclusive("json-pretty", "compact-json")
+	RootCmd.AddCommand(batchCmd)
+}
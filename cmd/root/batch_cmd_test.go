@@ -0,0 +1,95 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"tokenwatch/pkg/utils"
+)
+
+func TestReadBatchRequests(t *testing.T) {
+	input := `# nightly report periods
+7d
+
+  1d  
+36h
+# a fixed month
+2024-01-01..2024-01-31
+`
+	requests, err := readBatchRequests(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("readBatchRequests: %v", err)
+	}
+
+	specs := make([]string, len(requests))
+	for i, r := range requests {
+		specs[i] = r.spec
+	}
+	if got, want := strings.Join(specs, ","), "7d,1d,36h,2024-01-01..2024-01-31"; got != want {
+		t.Fatalf("specs = %s, want %s (comments and blank lines skipped)", got, want)
+	}
+
+	// A Go duration runs up to now
+	if d := requests[2].endTime.Sub(requests[2].startTime); d < 35*time.Hour || d > 37*time.Hour {
+		t.Errorf("36h request spans %v", d)
+	}
+
+	// A date range is inclusive of its last day
+	dateRange := requests[3]
+	wantStart := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	wantEnd := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	if !dateRange.startTime.Equal(wantStart) || !dateRange.endTime.Equal(wantEnd) {
+		t.Errorf("date range = %v..%v, want %v..%v", dateRange.startTime, dateRange.endTime, wantStart, wantEnd)
+	}
+}
+
+func TestReadBatchRequestsEmptyInput(t *testing.T) {
+	requests, err := readBatchRequests(strings.NewReader("# nothing to do\n\n"))
+	if err != nil {
+		t.Fatalf("readBatchRequests: %v", err)
+	}
+	if len(requests) != 0 {
+		t.Errorf("got %d requests, want none", len(requests))
+	}
+}
+
+func TestReadBatchRequestsErrorsNameTheLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"bad period", "7d\n# comment\nfortnight\n", "line 3: invalid period \"fortnight\""},
+		{"org flag", "7d\n--org org-123\n", "line 2: --org is not supported"},
+		{"two fields", "\n7d 30d\n", "line 2: expected a single period"},
+		{"bad date", "2024-13-01..2024-12-31\n", "line 1: invalid"},
+		{"negative duration", "1d\n-5h\n", "line 2: invalid period"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := readBatchRequests(strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.want)
+			}
+			var se *utils.StructuredError
+			if !errors.As(err, &se) {
+				t.Errorf("error %v is not a validation error", err)
+			}
+		})
+	}
+}
+
+func TestParseBatchSpecPeriods(t *testing.T) {
+	for _, spec := range []string{"1d", "7d", "30d", "90d", "1y", "all", "q1", "last-quarter", "90m"} {
+		request, err := parseBatchSpec(spec)
+		if err != nil {
+			t.Errorf("parseBatchSpec(%q): %v", spec, err)
+			continue
+		}
+		if request.spec != spec || !request.startTime.Before(request.endTime) {
+			t.Errorf("parseBatchSpec(%q) = %+v, want a non-empty range labelled %q", spec, request, spec)
+		}
+	}
+}
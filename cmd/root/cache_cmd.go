@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"tokenwatch/internal/config"
+	"tokenwatch/pkg/cache"
+	"tokenwatch/pkg/utils"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// startupPruneLimit caps how many cache files are examined by the automatic prune
+const startupPruneLimit = 50
+
+// startupPrune makes pruneCacheOnce run at most once per process
+var startupPrune sync.Once
+
+// pruneCacheOnce opportunistically prunes a bounded number of expired entries
+// from dir. It runs from the commands that use the disk cache, the first time
+// they enable it, so other commands never touch the cache directory.
+func pruneCacheOnce(dir string) {
+	startupPrune.Do(func() {
+		if result, err := cache.Prune(dir, time.Now(), startupPruneLimit); err == nil && result.Removed > 0 {
+			utils.Debug("Pruned expired cache entries", map[string]interface{}{
+				"removed": result.Removed,
+				"bytes":   result.BytesReclaimed,
+			})
+		}
+	})
+}
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the TokenWatch disk cache",
+	Long: `Manage cached API responses stored on disk.
+
+This command allows you to:
+• Remove expired cache entries
+• Remove every cache entry`,
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete expired cache entries",
+	Long: `Walk the cache directory and delete entries that are past their expiry time.
+
+Examples:
+  tokenwatch cache prune`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Init(); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		dir := config.GetCacheDir()
+		result, err := cache.Prune(dir, time.Now(), 0)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(icon("prune") + "CACHE PRUNE")
+		fmt.Println("─" + strings.Repeat("─", 50))
+		fmt.Printf(icon("folder")+"Cache directory: %s\n", color.CyanString(dir))
+		fmt.Printf(icon("check")+"Entries scanned: %d\n", result.Scanned)
+		fmt.Printf(icon("delete")+"Entries removed: %d\n", result.Removed)
+		fmt.Printf(icon("disk")+"Space reclaimed: %s\n", color.GreenString(formatBytes(result.BytesReclaimed)))
+		return nil
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete all cache entries",
+	Long: `Delete every entry in the cache directory, including ones that haven't
+expired, so the next run fetches fresh data from the API.
+
+Examples:
+  tokenwatch cache clear`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Init(); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		dir := config.GetCacheDir()
+		result, err := cache.Clear(dir)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(icon("delete") + "CACHE CLEAR")
+		fmt.Println("─" + strings.Repeat("─", 50))
+		fmt.Printf(icon("folder")+"Cache directory: %s\n", color.CyanString(dir))
+		fmt.Printf(icon("delete")+"Entries removed: %d\n", result.Removed)
+		fmt.Printf(icon("disk")+"Space reclaimed: %s\n", color.GreenString(formatBytes(result.BytesReclaimed)))
+		return nil
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cachePruneCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+	RootCmd.AddCommand(cacheCmd)
+}
+
+// formatBytes renders a byte count in human-readable units
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
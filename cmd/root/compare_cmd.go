@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"tokenwatch/internal/config"
+	"tokenwatch/pkg/models"
+	"tokenwatch/pkg/providers"
+	"tokenwatch/pkg/tokenwatch"
+	"tokenwatch/pkg/utils"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var compareCmd = &cobra.Command{
+	Use:   "compare",
+	Short: "Compare costs with the previous period",
+	Long: `Compare each model's cost in a period with the period of the same length
+just before it, e.g. the last 7 days with the 7 days before that.
+
+With --delta-threshold the command exits non-zero when total cost grew by
+more than the threshold, so it can gate CI. The threshold is a percentage of
+the previous cost (10%) or an amount in USD (5.00). A percentage threshold
+trips on any increase when the previous period cost nothing.
+
+Examples:
+  tokenwatch compare                            # Last 7 days vs the 7 days before
+  tokenwatch compare --period 30d
+  tokenwatch compare --delta-threshold 10%      # Fail if cost grew by more than 10%
+  tokenwatch compare --delta-threshold 5.00     # Fail if cost grew by more than $5`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Init(); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		period, _ := cmd.Flags().GetString("period")
+		debug, _ := cmd.Flags().GetBool("debug")
+		thresholdFlag, _ := cmd.Flags().GetString("delta-threshold")
+
+		if err := validatePeriod(period); err != nil {
+			return err
+		}
+		if period == "" {
+			period = "7d"
+		}
+		var threshold *tokenwatch.DeltaThreshold
+		if thresholdFlag != "" {
+			t, err := tokenwatch.ParseDeltaThreshold(thresholdFlag)
+			if err != nil {
+				return utils.NewValidationError("delta-threshold", err.Error())
+			}
+			threshold = &t
+		}
+
+		if config.GetAPIKey("openai") == "" {
+			return utils.NewAuthError("OpenAI not configured", "openai")
+		}
+		provider := getProvider("openai")
+		if provider == nil {
+			return fmt.Errorf("OpenAI provider not available")
+		}
+
+		startTime, endTime := providers.GetPeriodTimeRange(period, false)
+		previousStart := startTime.Add(-endTime.Sub(startTime))
+
+		current, err := fetchModelStats(provider, startTime, endTime, debug)
+		if err != nil {
+			return err
+		}
+		previous, err := fetchModelStats(provider, previousStart, startTime, debug)
+		if err != nil {
+			return err
+		}
+
+		// A tripped threshold is about the data, not how the command was called
+		cmd.SilenceUsage = true
+
+		diff := tokenwatch.DiffSummaries(previous, current)
+		displayComparison(os.Stdout, diff, period)
+		return checkDeltaThreshold(os.Stdout, diff, threshold)
+	},
+}
+
+// fetchModelStats fetches usage and costs between startTime and endTime and
+// aggregates them per model
+func fetchModelStats(provider providers.Provider, startTime, endTime time.Time, debug bool) ([]models.ModelStats, error) {
+	consumptions, err := provider.GetConsumption(startTime, endTime, false, debug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consumption data: %w", err)
+	}
+	pricings, err := provider.GetPricing(startTime, endTime, false, debug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pricing data: %w", err)
+	}
+	return tokenwatch.AggregateModelStats(consumptions, pricings)
+}
+
+// displayComparison prints each model's cost in both periods and the change
+func displayComparison(w io.Writer, diff tokenwatch.SummaryDiff, period string) {
+	fmt.Fprintf(w, icon("average")+"COST COMPARISON - Last %s vs the %s before\n", period, period)
+
+	columns := []tableColumn{
+		{header: "Model"},
+		{header: "Previous", numeric: true},
+		{header: "Current", numeric: true},
+		{header: "Change", numeric: true},
+		{header: "Change %", numeric: true},
+	}
+	table := newTable(w, columns)
+	numbers := newNumberFormat(config.DisplaySettings())
+	for _, d := range diff.Models {
+		table.AddRow([]string{
+			color.YellowString("%s", d.Model),
+			color.CyanString("%s", numbers.cost(d.PreviousCost, 4)),
+			color.CyanString("%s", numbers.cost(d.CurrentCost, 4)),
+			changeColor(d.CostChange())("%s", numbers.signedCost(d.CostChange())),
+			color.HiBlackString("%s", changePercent(d.CostChange(), d.PreviousCost)),
+		})
+	}
+	table.AddRow([]string{
+		color.HiWhiteString("TOTAL"),
+		color.HiYellowString("%s", numbers.cost(diff.PreviousCost, 4)),
+		color.HiYellowString("%s", numbers.cost(diff.CurrentCost, 4)),
+		changeColor(diff.CostChange())("%s", numbers.signedCost(diff.CostChange())),
+		color.HiBlackString("%s", changePercent(diff.CostChange(), diff.PreviousCost)),
+	})
+	if err := table.Render(); err != nil {
+		fmt.Fprintf(w, icon("error")+"Error: %v\n", err)
+	}
+}
+
+// changeColor returns red for cost increases and green for decreases
+func changeColor(change float64) func(string, ...interface{}) string {
+	switch {
+	case change > 0:
+		return color.RedString
+	case change < 0:
+		return color.GreenString
+	default:
+		return color.HiBlackString
+	}
+}
+
+// changePercent formats change as a percentage of previous, or "new" when
+// there was no previous cost to compare with
+func changePercent(change, previous float64) string {
+	if previous == 0 {
+		if change > 0 {
+			return "new"
+		}
+		return "-"
+	}
+	return fmt.Sprintf("%+.1f%%", change/previous*100)
+}
+
+// checkDeltaThreshold prints the verdict for threshold and returns an error,
+// making the command exit non-zero, when cost grew by more than it. A nil
+// threshold never fails.
+func checkDeltaThreshold(w io.Writer, diff tokenwatch.SummaryDiff, threshold *tokenwatch.DeltaThreshold) error {
+	if threshold == nil {
+		return nil
+	}
+
+	fmt.Fprintln(w)
+	if threshold.Exceeded(diff) {
+		numbers := newNumberFormat(config.DisplaySettings())
+		return fmt.Errorf("cost grew by %s (%s), more than the --delta-threshold of %s",
+			numbers.signedCost(diff.CostChange()), changePercent(diff.CostChange(), diff.PreviousCost), threshold)
+	}
+	fmt.Fprintf(w, icon("ok")+"Cost change is within the --delta-threshold of %s\n", threshold)
+	return nil
+}
+
+func init() {
+	compareCmd.Flags().StringP("period", "p", "7d", "Time period: 1d, 7d, 30d, 90d, 1y, all, q1-q4, last-quarter")
+	compareCmd.Flags().String("delta-threshold", "", "Exit non-zero when cost grew by more than this percentage (10%) or USD amount (5.00)")
+	compareCmd.Flags().BoolP("debug", "d", false, "Enable debug logging for API calls")
+	RootCmd.AddCommand(compareCmd)
+}
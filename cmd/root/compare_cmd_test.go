@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"tokenwatch/pkg/tokenwatch"
+)
+
+func TestCheckDeltaThreshold(t *testing.T) {
+	tests := []struct {
+		name              string
+		threshold         string
+		previous, current float64
+		wantErr           bool
+	}{
+		{name: "percent within", threshold: "10%", previous: 100, current: 105},
+		{name: "percent over", threshold: "10%", previous: 100, current: 120, wantErr: true},
+		{name: "percent from zero", threshold: "10%", previous: 0, current: 0.5, wantErr: true},
+		{name: "absolute within", threshold: "5.00", previous: 100, current: 104},
+		{name: "absolute over", threshold: "5.00", previous: 100, current: 106, wantErr: true},
+		{name: "decrease", threshold: "0%", previous: 100, current: 50},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			threshold, err := tokenwatch.ParseDeltaThreshold(tt.threshold)
+			if err != nil {
+				t.Fatal(err)
+			}
+			diff := tokenwatch.SummaryDiff{PreviousCost: tt.previous, CurrentCost: tt.current}
+
+			var buf bytes.Buffer
+			err = checkDeltaThreshold(&buf, diff, &threshold)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "--delta-threshold of "+threshold.String()) {
+					t.Errorf("checkDeltaThreshold error = %v, want one naming the threshold", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("checkDeltaThreshold: unexpected error %v", err)
+			}
+			if !strings.Contains(buf.String(), "within") {
+				t.Errorf("expected a within-threshold verdict, got %q", buf.String())
+			}
+		})
+	}
+
+	// Without a threshold the comparison never fails
+	diff := tokenwatch.SummaryDiff{PreviousCost: 0, CurrentCost: 1000}
+	if err := checkDeltaThreshold(&bytes.Buffer{}, diff, nil); err != nil {
+		t.Errorf("checkDeltaThreshold without a threshold: %v", err)
+	}
+}
+
+func TestChangePercent(t *testing.T) {
+	tests := []struct {
+		change, previous float64
+		want             string
+	}{
+		{change: 10, previous: 100, want: "+10.0%"},
+		{change: -25, previous: 100, want: "-25.0%"},
+		{change: 3, previous: 0, want: "new"},
+		{change: 0, previous: 0, want: "-"},
+	}
+	for _, tt := range tests {
+		if got := changePercent(tt.change, tt.previous); got != tt.want {
+			t.Errorf("changePercent(%v, %v) = %q, want %q", tt.change, tt.previous, got, tt.want)
+		}
+	}
+}
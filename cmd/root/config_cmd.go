@@ -20,7 +20,8 @@ var configCmd = &cobra.Command{
 This command allows you to:
 • Check configuration status and API keys
 • Reload configuration from file
-• Reset settings to defaults`,
+• Reset settings to defaults
+• Export and import configuration between machines`,
 }
 
 var checkCmd = &cobra.Command{
@@ -33,36 +34,36 @@ var checkCmd = &cobra.Command{
 			return fmt.Errorf("failed to load config: %w", err)
 		}
 
-		fmt.Println("🔍 CONFIGURATION STATUS")
+		fmt.Println(icon("check") + "CONFIGURATION STATUS")
 		fmt.Println("─" + strings.Repeat("─", 50))
 
 		// Check config file
 		configFile := config.GetConfigFile()
 		if configFile != "" && configFile != "none" {
-			fmt.Printf("✅ Config file: %s\n", color.GreenString(configFile))
+			fmt.Printf(icon("ok")+"Config file: %s\n", color.GreenString(configFile))
 		} else {
-			fmt.Printf("ℹ️  Config file: %s\n", color.CyanString("Using default configuration"))
+			fmt.Printf(icon("info")+"Config file: %s\n", color.CyanString("Using default configuration"))
 		}
 
 		// Check API keys
-		fmt.Println("\n🔑 API KEYS:")
+		fmt.Println("\n" + icon("keys") + "API KEYS:")
 		platforms := []string{"openai"}
 		hasKeys := false
 		for _, platform := range platforms {
 			key := config.GetAPIKey(platform)
 			if key != "" {
-				fmt.Printf("   ✅ %s: %s\n", strings.Title(platform), color.GreenString("Configured"))
+				fmt.Printf("   "+icon("ok")+"%s: %s\n", strings.Title(platform), color.GreenString("Configured"))
 				hasKeys = true
 			} else {
-				fmt.Printf("   ❌ %s: %s\n", strings.Title(platform), color.RedString("Not configured"))
+				fmt.Printf("   "+icon("error")+"%s: %s\n", strings.Title(platform), color.RedString("Not configured"))
 			}
 		}
 
 		if !hasKeys {
-			fmt.Printf("\n💡 Run 'tokenwatch setup' to configure your OpenAI API key\n")
+			fmt.Printf("\n" + icon("tip") + "Run 'tokenwatch setup' to configure your OpenAI API key\n")
 		}
 
-		fmt.Println("\n✅ Configuration check complete!")
+		fmt.Println("\n" + icon("ok") + "Configuration check complete!")
 		return nil
 	},
 }
@@ -83,10 +84,10 @@ Examples:
 
 		if len(args) == 0 {
 			// Reset all settings
-			fmt.Println("⚠️  This will reset ALL configuration to defaults!")
+			fmt.Println(icon("warning") + "This will reset ALL configuration to defaults!")
 
 			if !utils.ConfirmPrompt("Are you sure?", false) {
-				fmt.Println("❌ Reset cancelled")
+				fmt.Println(icon("error") + "Reset cancelled")
 				return nil
 			}
 
@@ -96,29 +97,100 @@ Examples:
 				if err := os.Remove(configFile); err != nil {
 					return fmt.Errorf("failed to remove config file: %w", err)
 				}
-				fmt.Println("✅ Configuration reset to defaults")
-				fmt.Println("💡 Run 'tokenwatch config check' to verify the reset")
+				fmt.Println(icon("ok") + "Configuration reset to defaults")
+				fmt.Println(icon("tip") + "Run 'tokenwatch config check' to verify the reset")
 			} else {
-				fmt.Println("ℹ️  Already using default configuration")
+				fmt.Println(icon("info") + "Already using default configuration")
 			}
 		} else {
 			// Reset specific key
 			key := args[0]
-			config.Set(key, nil) // Set to nil to remove
-
-			if err := config.WriteConfig(); err != nil {
+			if _, err := config.ResetKey(key); err != nil {
 				return fmt.Errorf("failed to save config: %w", err)
 			}
 
-			fmt.Printf("✅ Reset %s to default\n", color.CyanString(key))
+			fmt.Printf(icon("ok")+"Reset %s to default\n", color.CyanString(key))
+		}
+
+		return nil
+	},
+}
+
+var exportConfigCmd = &cobra.Command{
+	Use:   "export <file>",
+	Short: "Write the effective configuration to a file",
+	Long: `Write the effective configuration (defaults, system config and user config)
+to a file for use on another machine. The format follows the file extension
+(.yaml, .yml or .json).
+
+API keys and other secrets are redacted unless --include-secrets is given, in
+which case the file is written with 0600 permissions.
+
+Examples:
+  tokenwatch config export tokenwatch.yaml
+  tokenwatch config export tokenwatch.yaml --include-secrets`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Init(); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		includeSecrets, _ := cmd.Flags().GetBool("include-secrets")
+		if err := config.ExportFile(args[0], includeSecrets); err != nil {
+			return err
+		}
+
+		fmt.Printf(icon("ok")+"Configuration exported to %s\n", color.CyanString(args[0]))
+		if !includeSecrets {
+			fmt.Println(icon("info") + "Secrets were redacted; use --include-secrets to export them")
+		}
+		return nil
+	},
+}
+
+var importConfigCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Load a configuration file into the standard location",
+	Long: `Validate a configuration file written by 'config export' and save it as the
+user config, replacing the current one. Unknown keys are rejected. Redacted
+secrets keep the value already configured on this machine.
+
+Examples:
+  tokenwatch config import tokenwatch.yaml
+  tokenwatch config import tokenwatch.yaml --force`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Init(); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		force, _ := cmd.Flags().GetBool("force")
+		if configFile := config.GetConfigFile(); configFile != "none" && !force {
+			fmt.Printf(icon("warning")+"This will replace %s\n", configFile)
+			if !utils.ConfirmPrompt("Continue?", false) {
+				fmt.Println(icon("error") + "Import cancelled")
+				return nil
+			}
+		}
+
+		configPath, err := config.ImportFile(args[0])
+		if err != nil {
+			return utils.NewValidationError("file", err.Error())
 		}
 
+		fmt.Printf(icon("ok")+"Configuration imported to %s\n", color.CyanString(configPath))
+		fmt.Println(icon("tip") + "Run 'tokenwatch config check' to verify the import")
 		return nil
 	},
 }
 
 func init() {
+	exportConfigCmd.Flags().Bool("include-secrets", false, "Include API keys and other secrets in the exported file")
+	importConfigCmd.Flags().Bool("force", false, "Replace the existing config without asking")
+
 	configCmd.AddCommand(checkCmd)
 	configCmd.AddCommand(resetCmd)
+	configCmd.AddCommand(exportConfigCmd)
+	configCmd.AddCommand(importConfigCmd)
 	RootCmd.AddCommand(configCmd)
 }
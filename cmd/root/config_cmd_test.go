@@ -0,0 +1,32 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"tokenwatch/internal/config"
+)
+
+func TestConfigCheckCreatesNoDirectories(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg"))
+	t.Setenv("OPENAI_API_KEY", "")
+	saved := config.SystemConfigPath
+	config.SystemConfigPath = filepath.Join(home, "system", "config.yaml")
+	t.Cleanup(func() { config.SystemConfigPath = saved })
+
+	if err := checkCmd.RunE(checkCmd, nil); err != nil {
+		t.Fatalf("config check: %v", err)
+	}
+
+	// Checking the config must work on a read-only home, so nothing is created
+	entries, err := os.ReadDir(home)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		t.Errorf("config check created %s", filepath.Join(home, entry.Name()))
+	}
+}
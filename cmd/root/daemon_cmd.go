@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"tokenwatch/internal/config"
+	"tokenwatch/pkg/export"
+	"tokenwatch/pkg/providers"
+	"tokenwatch/pkg/tokenwatch"
+	"tokenwatch/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// latestReportFile is the file in data_dir the daemon keeps up to date
+const latestReportFile = "latest.json"
+
+// minDaemonInterval is the shortest refresh interval the daemon accepts
+const minDaemonInterval = time.Minute
+
+// defaultShutdownGrace is how long an in-flight refresh may run after a stop signal
+const defaultShutdownGrace = 30 * time.Second
+
+// latestReportPath returns where the daemon writes the latest usage report
+func latestReportPath() string {
+	return filepath.Join(config.GetDataDir(), latestReportFile)
+}
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Keep the latest usage report on disk for status integrations",
+	Long: `Periodically fetch OpenAI usage and write it as JSON to latest.json in the
+config directory (e.g. ~/.tokenwatch/latest.json).
+
+Status bars and scripts can then read the file, or run 'tokenwatch total
+--from-daemon', without calling the API. The file is replaced atomically, so
+readers never see a partial write. Stop the daemon with Ctrl+C or SIGTERM: a
+refresh in progress is given --grace to finish before the daemon exits, and a
+second signal exits immediately.
+
+Examples:
+  tokenwatch daemon                        # Refresh the last 7 days every 5 minutes
+  tokenwatch daemon -p 1d --interval 10m   # Refresh the last day every 10 minutes`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Init(); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		if config.GetAPIKey("openai") == "" {
+			return utils.NewAuthError("OpenAI not configured", "openai")
+		}
+
+		period, _ := cmd.Flags().GetString("period")
+		interval, _ := cmd.Flags().GetDuration("interval")
+		debug, _ := cmd.Flags().GetBool("debug")
+		grace, _ := cmd.Flags().GetDuration("grace")
+
+		if err := validatePeriod(period); err != nil {
+			return err
+		}
+		if period == "" {
+			period = "7d"
+		}
+		if interval < minDaemonInterval {
+			return utils.NewValidationError("interval", fmt.Sprintf("must be at least %s", minDaemonInterval))
+		}
+		if grace < 0 {
+			return utils.NewValidationError("grace", "must not be negative")
+		}
+
+		// Fail now rather than on every refresh if the report can't be written
+		if _, err := config.EnsureDataDir(); err != nil {
+			return err
+		}
+
+		provider := getProvider("openai")
+		if provider == nil {
+			return fmt.Errorf("OpenAI provider not available")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		go func() {
+			// Restore default signal handling so a second signal exits immediately
+			<-ctx.Done()
+			stop()
+		}()
+
+		return runDaemon(ctx, provider, period, interval, grace, latestReportPath(), debug)
+	},
+}
+
+// runDaemon writes the report for period to path every interval until ctx is
+// cancelled. Failed refreshes are logged and leave the previous file in place.
+// A refresh in progress when ctx is cancelled is given grace to finish; one
+// that runs longer is abandoned, which is safe because the file is only ever
+// replaced atomically.
+func runDaemon(ctx context.Context, provider providers.Provider, period string, interval, grace time.Duration, path string, debug bool) error {
+	fmt.Printf(icon("start")+"Writing the last %s of usage to %s every %s\n", period, path, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// The provider's cache and rate limiter are shared across refreshes
+		done := make(chan error, 1)
+		go func() {
+			done <- writeLatestReport(provider, period, path, debug)
+		}()
+
+		select {
+		case err := <-done:
+			logRefresh(err, path)
+		case <-ctx.Done():
+			fmt.Printf(icon("wait")+"Stopping: waiting up to %s for the current refresh to finish\n", grace)
+			select {
+			case err := <-done:
+				logRefresh(err, path)
+			case <-time.After(grace):
+				utils.Warn("Refresh did not finish within the shutdown grace period; previous report left in place", map[string]interface{}{
+					"grace": grace.String(),
+				})
+			}
+			fmt.Println(icon("done") + "Daemon stopped")
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Println(icon("done") + "Daemon stopped")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// logRefresh logs the outcome of a daemon refresh
+func logRefresh(err error, path string) {
+	if err != nil {
+		utils.Warn("Failed to refresh usage report", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	utils.Debug("Refreshed usage report", map[string]interface{}{
+		"path": path,
+	})
+}
+
+// writeLatestReport fetches the report for period and atomically replaces path with it
+func writeLatestReport(provider providers.Provider, period, path string, debug bool) error {
+	report, err := buildUsageReport(provider, period, tokenwatch.ModelFilter{}, false, false, debug)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	return export.WriteFileAtomic(path, append(data, '\n'), 0600)
+}
+
+// readLatestReport reads the report last written by the daemon
+func readLatestReport(path string) (export.UsageReport, error) {
+	var report export.UsageReport
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return report, fmt.Errorf("no daemon report at %s; start one with 'tokenwatch daemon'", path)
+		}
+		return report, fmt.Errorf("failed to read daemon report: %w", err)
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return report, fmt.Errorf("failed to parse daemon report %s: %w", path, err)
+	}
+	return report, nil
+}
+
+func init() {
+	daemonCmd.Flags().StringP("period", "p", "7d", "Time period: 1d, 7d, 30d, 90d, 1y, all, q1-q4, last-quarter")
+	daemonCmd.Flags().Duration("interval", 5*time.Minute, "How often to refresh the report (minimum 1m)")
+	daemonCmd.Flags().Duration("grace", defaultShutdownGrace, "How long a refresh in progress may run after SIGTERM or Ctrl+C")
+	daemonCmd.Flags().BoolP("debug", "d", false, "Enable debug logging for API calls")
+	RootCmd.AddCommand(daemonCmd)
+}
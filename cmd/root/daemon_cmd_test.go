@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"tokenwatch/pkg/export"
+	"tokenwatch/pkg/models"
+	"tokenwatch/pkg/utils"
+)
+
+// blockingProvider is a stubProvider whose usage fetch waits for release
+type blockingProvider struct {
+	stubProvider
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingProvider) GetConsumption(startTime, endTime time.Time, bypassCache, debug bool) ([]*models.Consumption, error) {
+	close(b.started)
+	<-b.release
+	return b.consumptions, nil
+}
+
+func (b *blockingProvider) GetConsumptionContext(ctx context.Context, startTime, endTime time.Time, bypassCache, debug bool) ([]*models.Consumption, error) {
+	return b.GetConsumption(startTime, endTime, bypassCache, debug)
+}
+
+func daemonStubProvider() stubProvider {
+	now := time.Now().UTC()
+	return stubProvider{
+		consumptions: []*models.Consumption{{Platform: "openai", Model: "gpt-4o", InputTokens: 100, OutputTokens: 50, TotalTokens: 150, RequestCount: 2, StartTime: now.Add(-time.Hour), EndTime: now}},
+		pricings:     []*models.Pricing{{Platform: "openai", Model: "gpt-4o", Amount: 0.5, Currency: "usd", StartTime: now.Add(-time.Hour), EndTime: now}},
+	}
+}
+
+func TestWriteLatestReportReplacesFileAtomically(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, latestReportFile)
+	if err := os.WriteFile(path, []byte(`{"period":"old"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := daemonStubProvider()
+	if err := writeLatestReport(&provider, "7d", path, false); err != nil {
+		t.Fatalf("writeLatestReport: %v", err)
+	}
+
+	report, err := readLatestReport(path)
+	if err != nil {
+		t.Fatalf("readLatestReport: %v", err)
+	}
+	if report.Period != "7d" || report.Totals.TotalTokens != 150 {
+		t.Errorf("report = period %q, %d tokens; want the new 7d report with 150 tokens", report.Period, report.Totals.TotalTokens)
+	}
+
+	// Only the report is left behind; the temporary file was renamed into place
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Name() != latestReportFile {
+		names := make([]string, len(entries))
+		for i, e := range entries {
+			names[i] = e.Name()
+		}
+		t.Errorf("directory holds %v, want only %s", names, latestReportFile)
+	}
+	if info, err := os.Stat(path); err == nil && info.Mode().Perm() != 0600 {
+		t.Errorf("report mode = %o, want 0600", info.Mode().Perm())
+	}
+}
+
+func TestReadLatestReportMissingFile(t *testing.T) {
+	_, err := readLatestReport(filepath.Join(t.TempDir(), latestReportFile))
+	if err == nil || !strings.Contains(err.Error(), "tokenwatch daemon") {
+		t.Errorf("error = %v, want one suggesting 'tokenwatch daemon'", err)
+	}
+}
+
+func TestTotalFromDaemonChecksPeriod(t *testing.T) {
+	path := filepath.Join(t.TempDir(), latestReportFile)
+	data, _ := json.Marshal(export.UsageReport{Platform: "openai", Period: "1d", Totals: export.UsageTotals{TotalTokens: 42, Requests: 3, Cost: 1.5}})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := totalFromDaemon(path, "7d", true)
+	var se *utils.StructuredError
+	if !errors.As(err, &se) || !strings.Contains(err.Error(), "restart it with --period 7d") {
+		t.Errorf("error = %v, want a validation error about the daemon's 1d period", err)
+	}
+
+	// Without an explicit --period the daemon's own period is used
+	total, err := totalFromDaemon(path, "7d", false)
+	if err != nil {
+		t.Fatalf("totalFromDaemon: %v", err)
+	}
+	if total.Period != "1d" || total.TotalTokens != 42 || total.TotalRequests != 3 || total.TotalCost != 1.5 {
+		t.Errorf("total = %+v, want the daemon's 1d totals", total)
+	}
+}
+
+func TestRunDaemonDrainsRefreshWithinGrace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), latestReportFile)
+	provider := &blockingProvider{stubProvider: daemonStubProvider(), started: make(chan struct{}), release: make(chan struct{})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- runDaemon(ctx, provider, "7d", time.Hour, 5*time.Second, path, false) }()
+
+	// Stop while the first refresh is in flight, then let it finish
+	<-provider.started
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	close(provider.release)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("runDaemon = %v, want nil on shutdown", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("runDaemon didn't return after the refresh finished")
+	}
+	if _, err := readLatestReport(path); err != nil {
+		t.Errorf("the drained refresh didn't write its report: %v", err)
+	}
+}
+
+func TestRunDaemonAbandonsRefreshAfterGrace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), latestReportFile)
+	// The refresh is never released, as the daemon has stopped waiting for it
+	provider := &blockingProvider{stubProvider: daemonStubProvider(), started: make(chan struct{}), release: make(chan struct{})}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- runDaemon(ctx, provider, "7d", time.Hour, 50*time.Millisecond, path, false) }()
+
+	<-provider.started
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("runDaemon = %v, want nil on shutdown", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("runDaemon waited past its 50ms grace period")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("the abandoned refresh left a report behind (stat error %v)", err)
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"tokenwatch/internal/config"
+	"tokenwatch/pkg/utils"
+
+	"golang.org/x/term"
+)
+
+// newTable creates a table for columns using the configured display.table_style
+func newTable(w io.Writer, columns []tableColumn) *utils.Table {
+	numeric := make([]bool, len(columns))
+	for i, c := range columns {
+		numeric[i] = c.numeric
+	}
+	table := utils.NewTable(w, config.DisplaySettings().TableStyle, numeric)
+	table.AddHeader(columnHeaders(columns))
+	return table
+}
+
+// tableColumn describes a table column: its header and whether it holds numbers
+type tableColumn struct {
+	header  string
+	numeric bool
+}
+
+// columnHeaders returns the headers of columns
+func columnHeaders(columns []tableColumn) []string {
+	headers := make([]string, len(columns))
+	for i, c := range columns {
+		headers[i] = c.header
+	}
+	return headers
+}
+
+// formatTimestamp formats t with layout and appends its time zone abbreviation
+// (e.g. "UTC" or "CET"), unless the layout already shows the zone
+func formatTimestamp(t time.Time, layout string) string {
+	if layoutShowsZone(layout) {
+		return t.Format(layout)
+	}
+	return t.Format(layout) + " " + t.Format("MST")
+}
+
+// layoutShowsZone reports whether layout prints the time zone, by formatting the
+// same wall-clock time in two different zones
+func layoutShowsZone(layout string) bool {
+	utc := time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC)
+	other := time.Date(2001, time.February, 3, 4, 5, 6, 0, time.FixedZone("XYZ", 3600))
+	return utc.Format(layout) != other.Format(layout)
+}
+
+// numberFormat formats the counts and costs in a report. It is resolved once
+// per render from the display settings and passed to the renderers, rather
+// than read from the config for every cell.
+type numberFormat struct {
+	grouping bool // thousands separators, from display.number_grouping
+}
+
+// newNumberFormat returns the number format for the given display settings
+func newNumberFormat(display config.Display) numberFormat {
+	return numberFormat{grouping: display.NumberGrouping}
+}
+
+// count formats n, with thousands separators when grouping is set
+func (f numberFormat) count(n int64) string {
+	s := fmt.Sprintf("%d", n)
+	if !f.grouping {
+		return s
+	}
+	return groupDigits(s)
+}
+
+// cost formats a dollar amount with the given number of decimals, grouping
+// the whole dollars when grouping is set
+func (f numberFormat) cost(v float64, decimals int) string {
+	s := fmt.Sprintf("%.*f", decimals, v)
+	if f.grouping {
+		s = groupDigits(s)
+	}
+	return "$" + s
+}
+
+// signedCost formats a cost difference with an explicit sign, e.g. +$0.1200
+func (f numberFormat) signedCost(v float64) string {
+	if v < 0 {
+		return "-" + f.cost(-v, 4)
+	}
+	return "+" + f.cost(v, 4)
+}
+
+// groupDigits inserts commas every three digits into the integer part of a
+// formatted number such as "-1234567.89"
+func groupDigits(s string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i:]
+	}
+
+	var b strings.Builder
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	return sign + b.String() + frac
+}
+
+// progress shows a transient status line on stderr while data is being fetched
+type progress struct {
+	enabled bool
+}
+
+// startProgress prints msg when progress display is enabled and stderr is a terminal
+func startProgress(enabled bool, msg string) *progress {
+	p := &progress{enabled: enabled && term.IsTerminal(int(os.Stderr.Fd()))}
+	if p.enabled {
+		fmt.Fprintf(os.Stderr, icon("wait")+"%s", msg)
+	}
+	return p
+}
+
+// Done clears the progress line
+func (p *progress) Done() {
+	if p.enabled {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+	}
+}
+
+// terminalWidth returns the width of stdout, or 0 when it is not a terminal
+func terminalWidth() int {
+	width, _, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 0
+	}
+	return width
+}
+
+// ansiPattern matches the color escape sequences added by fatih/color
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// visibleWidth returns the number of terminal columns s occupies, ignoring color codes
+func visibleWidth(s string) int {
+	return utf8.RuneCountInString(ansiPattern.ReplaceAllString(s, ""))
+}
+
+// fitColumns returns the indexes of the columns to keep so that a bordered table
+// of header and rows fits in width. Columns are dropped in dropOrder until the
+// table fits or nothing droppable is left. A width of 0 keeps every column.
+func fitColumns(header []string, rows [][]string, width int, dropOrder []int) []int {
+	// Each column takes its content plus one space of padding on both sides and a border
+	colWidths := make([]int, len(header))
+	for i, h := range header {
+		colWidths[i] = visibleWidth(h) + 3
+	}
+	for _, row := range rows {
+		for i, c := range row {
+			if w := visibleWidth(c) + 3; i < len(colWidths) && w > colWidths[i] {
+				colWidths[i] = w
+			}
+		}
+	}
+
+	dropped := make(map[int]bool)
+	tableWidth := func() int {
+		total := 1 // leading border
+		for i, w := range colWidths {
+			if !dropped[i] {
+				total += w
+			}
+		}
+		return total
+	}
+
+	if width > 0 {
+		for _, i := range dropOrder {
+			if tableWidth() <= width {
+				break
+			}
+			dropped[i] = true
+		}
+	}
+
+	var keep []int
+	for i := range header {
+		if !dropped[i] {
+			keep = append(keep, i)
+		}
+	}
+	return keep
+}
+
+// selectColumns returns the cells of row at the given column indexes
+func selectColumns(row []string, keep []int) []string {
+	selected := make([]string, 0, len(keep))
+	for _, i := range keep {
+		selected = append(selected, row[i])
+	}
+	return selected
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"tokenwatch/pkg/utils"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var estimateCmd = &cobra.Command{
+	Use:   "estimate",
+	Short: "Estimate the cost of a prospective prompt",
+	Long: `Estimate the projected cost of a call using the local rate table.
+
+This is independent of historical usage and does not call the OpenAI API.
+
+Examples:
+  tokenwatch estimate --model gpt-4o --input-tokens 1000 --output-tokens 500
+  tokenwatch estimate -m gpt-4o-mini --input-tokens 2000 --output-tokens 300 --count 1000`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		model, _ := cmd.Flags().GetString("model")
+		inputTokens, _ := cmd.Flags().GetInt64("input-tokens")
+		outputTokens, _ := cmd.Flags().GetInt64("output-tokens")
+		count, _ := cmd.Flags().GetInt64("count")
+
+		if model == "" {
+			return utils.NewValidationError("model", "a model is required (use --model)")
+		}
+		if inputTokens < 0 {
+			return utils.NewValidationError("input-tokens", "must not be negative")
+		}
+		if outputTokens < 0 {
+			return utils.NewValidationError("output-tokens", "must not be negative")
+		}
+		if count < 1 {
+			return utils.NewValidationError("count", "must be at least 1")
+		}
+
+		rate, err := rateTable().Lookup(model)
+		if err != nil {
+			return err
+		}
+
+		perCall := rate.Cost(inputTokens, outputTokens)
+
+		fmt.Println(icon("estimate") + "COST ESTIMATE")
+		fmt.Println("─" + strings.Repeat("─", 50))
+		fmt.Printf(icon("usage")+"Model: %s", color.YellowString(model))
+		if rate.Model != model {
+			fmt.Printf(" (priced as %s)", rate.Model)
+		}
+		fmt.Println()
+		fmt.Printf(icon("rates")+"Rates: %s input, %s output per 1M tokens\n",
+			color.CyanString("$%.2f", rate.InputPer1M),
+			color.CyanString("$%.2f", rate.OutputPer1M))
+		fmt.Printf(icon("input")+"Input Tokens: %d\n", inputTokens)
+		fmt.Printf(icon("output")+"Output Tokens: %d\n", outputTokens)
+		fmt.Printf(icon("cost")+"Cost per Call: %s\n", color.YellowString("$%.6f", perCall))
+		if count > 1 {
+			fmt.Printf(icon("repeat")+"Calls: %d\n", count)
+			fmt.Printf(icon("cost")+"Total Cost: %s\n", color.HiYellowString("$%.4f", perCall*float64(count)))
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	estimateCmd.Flags().StringP("model", "m", "", "Model to price (e.g. gpt-4o)")
+	estimateCmd.Flags().Int64("input-tokens", 0, "Number of input (prompt) tokens per call")
+	estimateCmd.Flags().Int64("output-tokens", 0, "Number of output (completion) tokens per call")
+	estimateCmd.Flags().Int64("count", 1, "Number of identical calls")
+	RootCmd.AddCommand(estimateCmd)
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"tokenwatch/internal/config"
+	"tokenwatch/pkg/export"
+	"tokenwatch/pkg/models"
+	"tokenwatch/pkg/providers"
+	"tokenwatch/pkg/tokenwatch"
+	"tokenwatch/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export OpenAI usage data for other tools",
+	Long: `Export aggregated OpenAI usage data in machine-readable formats.
+
+Available formats:
+• jsonl  - Newline-delimited JSON, one object per model plus a summary
+• influx - InfluxDB line protocol, one point per model per day`,
+}
+
+var exportJSONLCmd = &cobra.Command{
+	Use:   "jsonl",
+	Short: "Write usage as newline-delimited JSON",
+	Long: `Write OpenAI usage as newline-delimited JSON (JSON Lines).
+
+One JSON object is written per model, followed by a single summary object.
+Each line is a complete JSON document, which makes the output suitable for
+piping into jq or log pipelines. Usage for the whole period is fetched and
+aggregated before the first line is written, since each model's line holds
+its totals.
+
+Examples:
+  tokenwatch export jsonl                          # Last 7 days
+  tokenwatch export jsonl --period 30d             # Last 30 days
+  tokenwatch export jsonl -p 30d | jq 'select(.type == "model")'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Init(); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		if config.GetAPIKey("openai") == "" {
+			return utils.NewAuthError("OpenAI not configured", "openai")
+		}
+
+		period, _ := cmd.Flags().GetString("period")
+		debug, _ := cmd.Flags().GetBool("debug")
+
+		if err := validatePeriod(period); err != nil {
+			return err
+		}
+		if period == "" {
+			period = "7d"
+		}
+
+		provider := getProvider("openai")
+		if provider == nil {
+			return fmt.Errorf("OpenAI provider not available")
+		}
+
+		return exportJSONL(provider, period, debug)
+	},
+}
+
+var exportInfluxCmd = &cobra.Command{
+	Use:   "influx",
+	Short: "Write usage as InfluxDB line protocol",
+	Long: `Write OpenAI usage as InfluxDB line protocol for time-series databases.
+
+One point is written per model per daily bucket, timestamped with the start of
+the day, so the series lines up with the API's own buckets:
+
+  tokenwatch,platform=openai,model=gpt-4o input_tokens=123i,output_tokens=45i,total_tokens=168i,requests=3i,cost=4.5 1704067200000000000
+
+Examples:
+  tokenwatch export influx --period 30d
+  tokenwatch export influx -p 30d | influx write --bucket llm --precision ns`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Init(); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		if config.GetAPIKey("openai") == "" {
+			return utils.NewAuthError("OpenAI not configured", "openai")
+		}
+
+		period, _ := cmd.Flags().GetString("period")
+		debug, _ := cmd.Flags().GetBool("debug")
+
+		if err := validatePeriod(period); err != nil {
+			return err
+		}
+		if period == "" {
+			period = "7d"
+		}
+
+		provider := getProvider("openai")
+		if provider == nil {
+			return fmt.Errorf("OpenAI provider not available")
+		}
+
+		return exportInflux(provider, period, debug)
+	},
+}
+
+func init() {
+	exportJSONLCmd.Flags().StringP("period", "p", "7d", "Time period: 1d, 7d, 30d, 90d, 1y, all, q1-q4, last-quarter")
+	exportJSONLCmd.Flags().BoolP("debug", "d", false, "Enable debug logging for API calls")
+	exportCmd.AddCommand(exportJSONLCmd)
+	exportInfluxCmd.Flags().StringP("period", "p", "7d", "Time period: 1d, 7d, 30d, 90d, 1y, all, q1-q4, last-quarter")
+	exportInfluxCmd.Flags().BoolP("debug", "d", false, "Enable debug logging for API calls")
+	exportCmd.AddCommand(exportInfluxCmd)
+	RootCmd.AddCommand(exportCmd)
+}
+
+// exportJSONL fetches usage for the period and writes it to stdout as JSON lines
+func exportJSONL(provider providers.Provider, period string, debug bool) error {
+	startTime, endTime := providers.GetPeriodTimeRange(period, false)
+
+	consumptions, err := provider.GetConsumption(startTime, endTime, false, debug)
+	if err != nil {
+		return fmt.Errorf("failed to get consumption data: %w", err)
+	}
+
+	pricings, err := provider.GetPricing(startTime, endTime, false, debug)
+	pricingAvailable := err == nil
+	if err != nil {
+		// Keep stdout clean for consumers - report the problem on stderr
+		warnf(os.Stderr, "Could not fetch pricing data: %v", err)
+	}
+
+	writer := export.NewJSONLWriter(os.Stdout)
+
+	models, err := tokenwatch.AggregateModelStats(consumptions, pricings)
+	if err != nil {
+		return err
+	}
+	for _, m := range models {
+		if err := writer.WriteModel(export.ModelRecord{
+			Platform:     provider.GetPlatform(),
+			Model:        m.Model,
+			InputTokens:  m.InputTokens,
+			OutputTokens: m.OutputTokens,
+			TotalTokens:  m.TotalTokens,
+			Requests:     m.Requests,
+			Cost:         m.Cost,
+		}); err != nil {
+			return err
+		}
+	}
+
+	totals := tokenwatch.CalculateTotals(models)
+	return writer.WriteSummary(export.SummaryRecord{
+		Platform:      provider.GetPlatform(),
+		Period:        period,
+		StartTime:     startTime,
+		EndTime:       endTime,
+		Models:        len(models),
+		InputTokens:   totals.TotalInput,
+		OutputTokens:  totals.TotalOutput,
+		TotalTokens:   totals.TotalTokens,
+		TotalRequests: totals.TotalRequests,
+		TotalCost:     totals.TotalCost,
+		CostStatus:    tokenwatch.DetermineCostStatus(period, totals.TotalCost, pricingAvailable),
+	})
+}
+
+// exportInflux fetches usage for the period and writes one line protocol point
+// per model per daily bucket to stdout
+func exportInflux(provider providers.Provider, period string, debug bool) error {
+	startTime, endTime := providers.GetPeriodTimeRange(period, false)
+
+	consumptions, err := provider.GetConsumption(startTime, endTime, false, debug)
+	if err != nil {
+		return fmt.Errorf("failed to get consumption data: %w", err)
+	}
+
+	pricings, err := provider.GetPricing(startTime, endTime, false, debug)
+	if err != nil {
+		// Keep stdout clean for consumers - report the problem on stderr
+		warnf(os.Stderr, "Could not fetch pricing data: %v", err)
+	}
+
+	points, err := bucketPoints(provider.GetPlatform(), consumptions, pricings)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(os.Stdout, export.RenderInflux(points))
+	return err
+}
+
+// bucketPoints merges usage and costs into one point per model per bucket,
+// ordered by bucket start and then model name
+func bucketPoints(platform string, consumptions []*models.Consumption, pricings []*models.Pricing) ([]export.InfluxPoint, error) {
+	buckets, err := tokenwatch.AggregateBuckets(consumptions, pricings)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]export.InfluxPoint, 0, len(buckets))
+	for _, b := range buckets {
+		points = append(points, export.InfluxPoint{
+			Platform:     platform,
+			Model:        b.Model,
+			Time:         b.StartTime,
+			InputTokens:  b.InputTokens,
+			OutputTokens: b.OutputTokens,
+			TotalTokens:  b.TotalTokens,
+			Requests:     b.Requests,
+			Cost:         b.Cost,
+		})
+	}
+	return points, nil
+}
+
+// writeUsageJSON fetches usage for the period and writes it to stdout as a single
+// JSON document, with each model's per-bucket usage when buckets is set
+func writeUsageJSON(provider providers.Provider, period string, filter tokenwatch.ModelFilter, completeDays, bypassCache, debug, pretty, buckets bool) error {
+	startTime, endTime := providers.GetPeriodTimeRange(period, completeDays)
+	opts := reportOptions(period, startTime, endTime, bypassCache, debug)
+	opts.Filter = filter
+	opts.Buckets = buckets
+	report, err := tokenwatch.Report(context.Background(), tokenwatch.Config{Provider: provider}, opts)
+	if err != nil {
+		return err
+	}
+	return export.WriteJSON(os.Stdout, report, pretty)
+}
+
+// writeUsageCSV fetches usage for the period and writes one CSV line per model
+// to output, or to stdout when output is empty
+func writeUsageCSV(provider providers.Provider, period, output string, filter tokenwatch.ModelFilter, completeDays, bypassCache, debug bool) error {
+	report, err := buildUsageReport(provider, period, filter, completeDays, bypassCache, debug)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		return export.WriteCSV(os.Stdout, &report)
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteCSV(&buf, &report); err != nil {
+		return err
+	}
+	if err := export.WriteFileAtomic(output, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, icon("ok")+"Wrote %d models to %s\n", len(report.Models), output)
+	return nil
+}
+
+// pushUsageJSON fetches usage for the period and PUTs the JSON report to url
+func pushUsageJSON(provider providers.Provider, period, url string, filter tokenwatch.ModelFilter, completeDays, debug bool) error {
+	report, err := buildUsageReport(provider, period, filter, completeDays, false, debug)
+	if err != nil {
+		return err
+	}
+
+	if err := export.NewPusher(url, config.GetString("export.push_token")).Push(report); err != nil {
+		return err
+	}
+
+	fmt.Printf(icon("ok")+"Pushed usage report (%d models) to %s\n", len(report.Models), url)
+	return nil
+}
+
+// buildUsageReport fetches usage for the period and aggregates it into a JSON report
+// of the models filter keeps. With completeDays the period ends at the previous UTC midnight.
+func buildUsageReport(provider providers.Provider, period string, filter tokenwatch.ModelFilter, completeDays, bypassCache, debug bool) (export.UsageReport, error) {
+	startTime, endTime := providers.GetPeriodTimeRange(period, completeDays)
+	opts := reportOptions(period, startTime, endTime, bypassCache, debug)
+	opts.Filter = filter
+	report, err := tokenwatch.Report(context.Background(), tokenwatch.Config{Provider: provider}, opts)
+	if err != nil {
+		return export.UsageReport{}, err
+	}
+	return *report, nil
+}
+
+// buildUsageReportRange fetches usage between startTime and endTime and aggregates
+// it into a JSON report labelled with period
+func buildUsageReportRange(provider providers.Provider, period string, startTime, endTime time.Time, bypassCache, debug bool) (export.UsageReport, error) {
+	report, err := tokenwatch.Report(context.Background(), tokenwatch.Config{Provider: provider}, reportOptions(period, startTime, endTime, bypassCache, debug))
+	if err != nil {
+		return export.UsageReport{}, err
+	}
+	return *report, nil
+}
+
+// reportOptions returns the library options used by the CLI's JSON reports
+func reportOptions(period string, startTime, endTime time.Time, bypassCache, debug bool) tokenwatch.Options {
+	return tokenwatch.Options{
+		Period:       period,
+		StartTime:    startTime,
+		EndTime:      endTime,
+		BypassCache:  bypassCache,
+		Debug:        debug,
+		ModelBudgets: config.GetModelBudgets(),
+		// Keep stdout clean for consumers - report problems on stderr
+		OnWarning: func(msg string) { warnf(os.Stderr, "%s", msg) },
+	}
+}
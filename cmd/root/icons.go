@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Icon styles accepted by display.icons and --icons
+const (
+	iconsEmoji = "emoji"
+	iconsASCII = "ascii"
+	iconsNone  = "none"
+)
+
+// iconStyle is the active icon style
+var iconStyle = iconsEmoji
+
+// iconGlyphs maps semantic icon names to their emoji and ASCII forms
+var iconGlyphs = map[string]struct{ emoji, ascii string }{
+	"ok":       {"✅", "[OK]"},
+	"error":    {"❌", "[ERROR]"},
+	"warning":  {"⚠️ ", "[WARN]"},
+	"info":     {"ℹ️ ", "[INFO]"},
+	"tip":      {"💡", "[TIP]"},
+	"usage":    {"🤖", "[USAGE]"},
+	"summary":  {"📊", "[SUMMARY]"},
+	"table":    {"📋", "[TABLE]"},
+	"top":      {"🏆", "[TOP]"},
+	"cost":     {"💰", "[COST]"},
+	"billing":  {"🧾", "[BILLING]"},
+	"project":  {"📁", "[PROJECT]"},
+	"folder":   {"📁", "[DIR]"},
+	"rates":    {"💲", "[RATES]"},
+	"estimate": {"🧮", "[ESTIMATE]"},
+	"audit":    {"🔎", "[AUDIT]"},
+	"input":    {"📥", "[IN]"},
+	"output":   {"📤", "[OUT]"},
+	"repeat":   {"🔁", "[CALLS]"},
+	"period":   {"📅", "[PERIOD]"},
+	"average":  {"📈", "[AVG]"},
+	"time":     {"⏰", "[TIME]"},
+	"wait":     {"⏳", "[WAIT]"},
+	"refresh":  {"🔄", "[REFRESH]"},
+	"check":    {"🔍", "[CHECK]"},
+	"keys":     {"🔑", "[KEYS]"},
+	"start":    {"🚀", "[START]"},
+	"done":     {"🎉", "[DONE]"},
+	"prune":    {"🧹", "[PRUNE]"},
+	"delete":   {"🗑️ ", "[DELETE]"},
+	"disk":     {"💾", "[DISK]"},
+}
+
+// setIconStyle selects the icon style used by icon
+func setIconStyle(style string) error {
+	style = strings.ToLower(strings.TrimSpace(style))
+	switch style {
+	case iconsEmoji, iconsASCII, iconsNone:
+		iconStyle = style
+		return nil
+	default:
+		return fmt.Errorf("invalid icon style: %s. Valid styles are: %s, %s, %s", style, iconsEmoji, iconsASCII, iconsNone)
+	}
+}
+
+// icon returns the named icon in the active style followed by a space,
+// or an empty string when icons are disabled
+func icon(name string) string {
+	glyph, ok := iconGlyphs[name]
+	if !ok || iconStyle == iconsNone {
+		return ""
+	}
+	if iconStyle == iconsASCII {
+		return glyph.ascii + " "
+	}
+	return glyph.emoji + " "
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+
+	"tokenwatch/pkg/models"
+)
+
+// isEmoji reports whether r is an emoji or an emoji presentation selector.
+// Box-drawing characters used by the tables are not emoji.
+func isEmoji(r rune) bool {
+	return r >= 0x1F000 || (r >= 0x2600 && r <= 0x27BF) || r == 0x2139 || r == 0xFE0F
+}
+
+// useIconStyle switches the icon style for the rest of the test
+func useIconStyle(t *testing.T, style string) {
+	t.Helper()
+	saved := iconStyle
+	if err := setIconStyle(style); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { iconStyle = saved })
+}
+
+func TestASCIIIconsLeaveNoEmojiInReport(t *testing.T) {
+	saved := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = saved })
+	useIconStyle(t, iconsASCII)
+
+	now := time.Now().UTC()
+	provider := &stubProvider{
+		consumptions: []*models.Consumption{{Platform: "openai", Model: "gpt-4o", InputTokens: 100, OutputTokens: 50, TotalTokens: 150, RequestCount: 2, StartTime: now.Add(-time.Hour), EndTime: now}},
+		pricings:     []*models.Pricing{{Platform: "openai", Model: "gpt-4o", Amount: 0.5, Currency: "usd", StartTime: now.Add(-time.Hour), EndTime: now}},
+	}
+
+	for _, compact := range []bool{false, true} {
+		var buf bytes.Buffer
+		opts := displayOptions{
+			out:           &buf,
+			sections:      []string{sectionSummary, sectionTable, sectionTypes, sectionRecommendations},
+			dateFormat:    "2006-01-02",
+			compact:       compact,
+			count:         countTotal,
+			costUnit:      costUnit1K,
+			costBreakdown: costBreakdownTotal,
+			filter:        newModelFilter("gpt-3.5-turbo", 0, 0, false),
+		}
+		if err := displayOpenAIData(provider, "7d", opts, false, false); err != nil {
+			t.Fatalf("displayOpenAIData: %v", err)
+		}
+		out := buf.String()
+		if !strings.Contains(out, "[USAGE]") {
+			t.Errorf("compact=%v: ascii output has no [USAGE] tag:\n%s", compact, out)
+		}
+		for _, r := range out {
+			if isEmoji(r) {
+				t.Errorf("compact=%v: ascii output contains emoji %q:\n%s", compact, r, out)
+				break
+			}
+		}
+	}
+}
+
+func TestIconStyles(t *testing.T) {
+	tests := map[string]string{
+		iconsEmoji: "📊 ",
+		iconsASCII: "[SUMMARY] ",
+		iconsNone:  "",
+	}
+	for style, want := range tests {
+		useIconStyle(t, style)
+		if got := icon("summary"); got != want {
+			t.Errorf("%s: icon(summary) = %q, want %q", style, got, want)
+		}
+	}
+	if err := setIconStyle("fancy"); err == nil {
+		t.Error("setIconStyle(fancy) succeeded, want an error")
+	}
+}
@@ -2,10 +2,13 @@ package main
 
 import (
 	"os"
+	"time"
 
 	"tokenwatch/internal/config"
+	"tokenwatch/pkg/telemetry"
 	"tokenwatch/pkg/utils"
 
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
@@ -14,6 +17,9 @@ var (
 	BuildTime = "unknown"
 )
 
+// telemetryWaitTimeout bounds how long exit is delayed waiting for telemetry
+const telemetryWaitTimeout = 2 * time.Second
+
 var RootCmd = &cobra.Command{
 	Use:     "tokenwatch",
 	Version: Version,
@@ -23,6 +29,20 @@ Currently supports OpenAI organization-level usage and costs monitoring.`,
 	CompletionOptions: cobra.CompletionOptions{
 		DisableDefaultCmd: true,
 	},
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		noColor, _ := cmd.Flags().GetBool("no-color")
+		setupColor(noColor)
+
+		// --icons overrides display.icons for this run
+		style, _ := cmd.Flags().GetString("icons")
+		if style == "" && config.Config != nil {
+			style = config.DisplaySettings().Icons
+		}
+		if style == "" {
+			return nil
+		}
+		return setIconStyle(style)
+	},
 }
 
 func Execute() error {
@@ -30,11 +50,14 @@ func Execute() error {
 }
 
 func init() {
+	RootCmd.PersistentFlags().String("icons", "", "Icon style: emoji, ascii, or none (overrides display.icons)")
+	RootCmd.PersistentFlags().Bool("no-color", false, "Disable colored output (also set by display.colors: false or the NO_COLOR environment variable)")
+
 	// Initialize logger early
 	logLevel := utils.InfoLevel
 
 	// Try to load config to get debug setting
-	if err := config.Init(); err == nil {
+	if config.Init() == nil {
 		if config.GetBool("settings.debug") {
 			logLevel = utils.DebugLevel
 		}
@@ -44,15 +67,63 @@ func init() {
 		}
 	}
 
-	// Initialize logger with color support for terminal
+	// Initialize logger with color support for terminal. display.colors and
+	// NO_COLOR apply from the start; --no-color once flags are parsed.
 	utils.InitLogger(logLevel, true)
+	setupColor(false)
+}
+
+// colorEnabled reports whether output is colored. Any of --no-color, a
+// non-empty NO_COLOR (https://no-color.org) or display.colors: false turns
+// colors off, and none of them can turn colors back on once another has.
+func colorEnabled(noColorFlag bool) bool {
+	if noColorFlag || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return config.DisplaySettings().Colors
+}
+
+// setupColor disables colored output when colorEnabled says so. Colors are
+// never forced on, so output that isn't a terminal stays plain.
+func setupColor(noColorFlag bool) {
+	if !colorEnabled(noColorFlag) {
+		disableColor()
+	}
+}
+
+// disableColor turns off colored output, including the logger's level colors
+func disableColor() {
+	color.NoColor = true
+	if utils.DefaultLogger != nil {
+		utils.DefaultLogger.SetColorize(false)
+	}
+}
+
+// reportTelemetry sends an anonymized record of the command run, if the user opted in
+func reportTelemetry(cmd *cobra.Command, runErr error) {
+	if config.Config == nil || !config.GetBool("telemetry.enabled") || cmd == nil {
+		return
+	}
+
+	var period string
+	if flag := cmd.Flags().Lookup("period"); flag != nil {
+		period = flag.Value.String()
+	}
+
+	client := telemetry.NewClient(true, config.GetString("telemetry.endpoint"))
+	client.Send(telemetry.NewEvent(cmd.CommandPath(), period, runErr == nil, Version))
+	client.Wait(telemetryWaitTimeout)
 }
 
 func main() {
-	if err := Execute(); err != nil {
+	cmd, err := RootCmd.ExecuteC()
+	reportTelemetry(cmd, err)
+	if err != nil {
 		utils.Error("Command execution failed", map[string]interface{}{
 			"error": err.Error(),
 		})
+		utils.CloseLogger()
 		os.Exit(1)
 	}
+	utils.CloseLogger()
 }
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/viper"
+
+	"tokenwatch/internal/config"
+	"tokenwatch/pkg/models"
+	"tokenwatch/pkg/utils"
+)
+
+// useColorSources sets display.colors and NO_COLOR for the rest of the test,
+// starting with colors forced on and a colorized logger writing to log
+func useColorSources(t *testing.T, configColors bool, noColorEnv string, log *bytes.Buffer) {
+	t.Helper()
+	savedConfig, savedNoColor, savedLogger := config.Config, color.NoColor, utils.DefaultLogger
+	t.Cleanup(func() {
+		config.Config, color.NoColor, utils.DefaultLogger = savedConfig, savedNoColor, savedLogger
+	})
+
+	config.Config = viper.New()
+	config.Config.Set("display.colors", configColors)
+	t.Setenv("NO_COLOR", noColorEnv)
+	color.NoColor = false
+	utils.DefaultLogger = utils.NewLogger(utils.InfoLevel, log, "", true)
+}
+
+func TestColorPrecedence(t *testing.T) {
+	tests := []struct {
+		name         string
+		flag         bool
+		env          string
+		configColors bool
+		want         bool
+	}{
+		{name: "nothing set", configColors: true, want: true},
+		{name: "--no-color", flag: true, configColors: true, want: false},
+		{name: "NO_COLOR", env: "1", configColors: true, want: false},
+		{name: "display.colors false", configColors: false, want: false},
+		{name: "all set", flag: true, env: "1", configColors: false, want: false},
+	}
+	for _, tt := range tests {
+		useColorSources(t, tt.configColors, tt.env, &bytes.Buffer{})
+		if got := colorEnabled(tt.flag); got != tt.want {
+			t.Errorf("%s: colorEnabled = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNoColorGivesPlainOutput(t *testing.T) {
+	now := time.Now().UTC()
+	provider := &stubProvider{
+		consumptions: []*models.Consumption{{Platform: "openai", Model: "gpt-4o", InputTokens: 100, OutputTokens: 50, TotalTokens: 150, RequestCount: 2, StartTime: now.Add(-time.Hour), EndTime: now}},
+		pricings:     []*models.Pricing{{Platform: "openai", Model: "gpt-4o", Amount: 0.5, Currency: "usd", StartTime: now.Add(-time.Hour), EndTime: now}},
+	}
+
+	tests := []struct {
+		name         string
+		flag         bool
+		env          string
+		configColors bool
+		plain        bool
+	}{
+		{name: "colors on", configColors: true, plain: false},
+		{name: "--no-color", flag: true, configColors: true, plain: true},
+		{name: "NO_COLOR", env: "1", configColors: true, plain: true},
+		{name: "display.colors false", configColors: false, plain: true},
+	}
+	for _, tt := range tests {
+		var log bytes.Buffer
+		useColorSources(t, tt.configColors, tt.env, &log)
+		setupColor(tt.flag)
+
+		var out bytes.Buffer
+		opts := displayOptions{
+			out:           &out,
+			sections:      []string{sectionSummary, sectionTable},
+			dateFormat:    "2006-01-02",
+			count:         countTotal,
+			costUnit:      costUnit1K,
+			costBreakdown: costBreakdownTotal,
+		}
+		if err := displayOpenAIData(provider, "7d", opts, false, false); err != nil {
+			t.Fatalf("%s: displayOpenAIData: %v", tt.name, err)
+		}
+		utils.Warn("disk almost full", nil)
+
+		for source, text := range map[string]string{"report": out.String(), "log": log.String()} {
+			if plain := !strings.Contains(text, "\x1b["); plain != tt.plain {
+				t.Errorf("%s: %s plain = %v, want %v:\n%s", tt.name, source, plain, tt.plain, text)
+			}
+		}
+	}
+}
@@ -2,17 +2,23 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"path/filepath"
 	"sort"
+	"strings"
 	"time"
 
 	"tokenwatch/internal/config"
+	"tokenwatch/pkg/models"
 	"tokenwatch/pkg/providers"
+	"tokenwatch/pkg/tokenwatch"
 	"tokenwatch/pkg/utils"
 
 	"github.com/fatih/color"
-	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 // getProvider creates and returns a provider for the specified platform
@@ -24,44 +30,150 @@ func getProvider(platform string) providers.Provider {
 		if apiKey == "" {
 			return nil
 		}
-		return providers.NewOpenAIProvider(apiKey, orgID)
+		provider := providers.NewOpenAIProvider(apiKey, orgID)
+		provider.SetProject(config.GetString("openai.project_id"))
+		provider.SetTimeouts(utils.TimeoutConfig{
+			DialTimeout:           config.GetDialTimeout(),
+			ResponseHeaderTimeout: config.GetResponseHeaderTimeout(),
+			ReadTimeout:           config.GetReadTimeout(),
+		})
+		provider.SetRateLimit(config.GetRateLimit(platform))
+		provider.SetMaxConcurrency(config.GetMaxConcurrency())
+		provider.SetMaxPages(config.GetMaxPages())
+		provider.SetMaxResponseBytes(config.GetMaxResponseBytes())
+		provider.SetEmptyPageRetries(config.GetEmptyPageRetries())
+		provider.SetExtraHeaders(config.GetStringMapString("openai.extra_headers"))
+		provider.SetCostInCents(config.GetBool("openai.cost_in_cents"))
+		provider.SetIcons(icon)
+		provider.SetCacheTTL(time.Duration(config.GetCacheDuration()) * time.Second)
+		if dir := os.Getenv(providers.FixturesEnv); dir != "" {
+			// Recorded responses must not end up in the real disk cache
+			provider.UseFixtures(dir)
+		} else {
+			provider.EnableDiskCache(config.GetCacheDir())
+			pruneCacheOnce(config.GetCacheDir())
+		}
+		if config.GetBool("settings.persist_circuit_state") {
+			if dir, err := config.EnsureDataDir(); err != nil {
+				utils.Warn("Circuit breaker state won't be saved", map[string]interface{}{
+					"error": err.Error(),
+				})
+			} else if err := provider.EnableCircuitPersistence(filepath.Join(dir, "circuit_openai.json")); err != nil {
+				utils.Warn("Ignoring saved circuit breaker state", map[string]interface{}{
+					"error": err.Error(),
+				})
+			}
+		}
+		return provider
 	default:
 		return nil
 	}
 }
 
-// ModelStats holds aggregated stats for a model
-type ModelStats struct {
-	Model        string
-	InputTokens  int64
-	OutputTokens int64
-	TotalTokens  int64
-	Requests     int64
-	Cost         float64
+// validatePeriod checks that period is one of the supported period values
+func validatePeriod(period string) error {
+	if providers.IsQuarterPeriod(period) {
+		_, _, err := providers.QuarterRange(period)
+		return err
+	}
+	if period != "" && period != "1d" && period != "7d" && period != "30d" && period != "90d" && period != "1y" && period != "all" {
+		return fmt.Errorf("invalid period: %s. Valid periods are: 1d, 7d, 30d, 90d, 1y, all, q1-q4, last-quarter", period)
+	}
+	return nil
+}
+
+// Token metrics accepted by --count
+const (
+	countTotal  = "total"
+	countInput  = "input"
+	countOutput = "output"
+)
+
+// countTokens returns a copy of stats whose TotalTokens holds the metric
+// selected by count, re-sorted by it. The input and output columns are unchanged.
+func countTokens(stats []models.ModelStats, count string) []models.ModelStats {
+	counted := make([]models.ModelStats, len(stats))
+	copy(counted, stats)
+
+	for i := range counted {
+		switch count {
+		case countInput:
+			counted[i].TotalTokens = counted[i].InputTokens
+		case countOutput:
+			counted[i].TotalTokens = counted[i].OutputTokens
+		}
+	}
+	tokenwatch.SortModelStats(counted)
+	return counted
+}
+
+// newModelFilter builds the report filter from --exclude-models, a comma-separated
+// list, and the --min-tokens and --min-cost thresholds
+func newModelFilter(exclude string, minTokens int64, minCost float64, excludeFromTotal bool) tokenwatch.ModelFilter {
+	f := tokenwatch.ModelFilter{MinTokens: minTokens, MinCost: minCost, ExcludeFromTotal: excludeFromTotal}
+	for _, name := range strings.Split(exclude, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			f.Exclude = append(f.Exclude, name)
+		}
+	}
+	return f
 }
 
-// TotalStats holds the totals across all models
-type TotalStats struct {
-	TotalInput    int64
-	TotalOutput   int64
-	TotalTokens   int64
-	TotalRequests int64
-	TotalCost     float64
+// Cost-efficiency denominators accepted by --cost-unit
+const (
+	costUnit1K = "1k"
+	costUnit1M = "1m"
+)
+
+// costPerTokens returns cost per 1K or 1M tokens as selected by unit, or 0
+// when there are no tokens or no cost
+func costPerTokens(cost float64, tokens int64, unit string) float64 {
+	if tokens <= 0 || cost <= 0 {
+		return 0
+	}
+	per := 1000.0
+	if unit == costUnit1M {
+		per = 1000000
+	}
+	return cost / float64(tokens) * per
 }
 
-// calculateTotals computes the totals across all models
-func calculateTotals(models []ModelStats) TotalStats {
-	var totals TotalStats
+// Cost column layouts accepted by --cost-breakdown
+const (
+	costBreakdownTotal       = "total"
+	costBreakdownInputOutput = "input-output"
+)
 
-	for _, m := range models {
-		totals.TotalInput += m.InputTokens
-		totals.TotalOutput += m.OutputTokens
-		totals.TotalTokens += m.TotalTokens
-		totals.TotalRequests += m.Requests
-		totals.TotalCost += m.Cost
+// costUnitHeader returns the table header for the cost-efficiency column
+func costUnitHeader(unit string) string {
+	if unit == costUnit1M {
+		return "$/1M Tokens"
 	}
+	return "$/1K Tokens"
+}
 
-	return totals
+// countedTotal returns the total of the token metric selected by count
+func countedTotal(totals models.TotalStats, count string) int64 {
+	switch count {
+	case countInput:
+		return totals.TotalInput
+	case countOutput:
+		return totals.TotalOutput
+	default:
+		return totals.TotalTokens
+	}
+}
+
+// countHeader returns the table header for the token column selected by count
+func countHeader(count string) string {
+	switch count {
+	case countInput:
+		return "Counted (Input)"
+	case countOutput:
+		return "Counted (Output)"
+	default:
+		return "Total Tokens"
+	}
 }
 
 var (
@@ -88,9 +200,28 @@ Examples:
         tokenwatch usage --period 90d   # Last 90 days
         tokenwatch usage --period 1y    # Last 1 year
         tokenwatch usage --period all   # Last 5 years (maximum)
+        tokenwatch usage --since 36h    # Last 36 hours (any Go duration)
+        tokenwatch usage --from 2024-01-01 --to 2024-01-31  # A billing cycle (dates inclusive, UTC)
         tokenwatch usage -w -p 1d       # Watch mode - refresh every 30s
         tokenwatch usage -w -p 7d       # Watch mode with 7-day period
-        tokenwatch usage -w -p 90d      # Watch mode with 90-day period`,
+        tokenwatch usage -w -p 90d      # Watch mode with 90-day period
+        tokenwatch usage --no-summary   # Hide the summary section
+        tokenwatch usage --section-order table,summary  # Table first, no recommendations
+        tokenwatch usage --date-format "02 Jan 2006"    # Custom date layout
+        tokenwatch usage --by-project   # Add a cost breakdown by project
+        tokenwatch usage --group-by project,api-key  # Tokens per project and API key
+        tokenwatch usage --compact      # Narrow layout for small terminals
+        tokenwatch usage --watch-diff   # Watch mode highlighting changes between refreshes
+        tokenwatch usage --watch-rate   # Watch mode with tokens/min and $/hr between refreshes
+        tokenwatch usage --format json  # JSON output (pretty on a terminal, compact when piped)
+        tokenwatch usage --format json --compact-json | jq .totals
+        tokenwatch usage --push https://dashboard.example.com/ingest  # Send the JSON report
+
+Time range precedence: --from/--to win over --since, which wins over --period.
+--from and --to take a UTC date (YYYY-MM-DD, --to includes the whole day) or an
+RFC3339 time. Without --to the range ends now. Without --from it starts 5 years
+ago, as far back as OpenAI keeps data, which stands in for the account's start;
+an earlier --from is rejected.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Load config
 		if err := config.Init(); err != nil {
@@ -103,29 +234,218 @@ Examples:
 			return utils.NewAuthError("OpenAI not configured", "openai")
 		}
 
+		// Reject flag combinations that don't make sense together
+		if err := validateFlags(cmd); err != nil {
+			return err
+		}
+
 		// Get flags
 		period, _ = cmd.Flags().GetString("period")
 		watch, _ = cmd.Flags().GetBool("watch")
 		debug, _ = cmd.Flags().GetBool("debug")
 
 		// Validate period
-		if period != "" && period != "1d" && period != "7d" && period != "30d" && period != "90d" && period != "1y" && period != "all" {
-			return fmt.Errorf("invalid period: %s. Valid periods are: 1d, 7d, 30d, 90d, 1y, all", period)
+		if err := validatePeriod(period); err != nil {
+			return err
 		}
 		if period == "" {
 			period = "7d"
 		}
 
+		// --since overrides --period with an arbitrary duration
+		if since, _ := cmd.Flags().GetString("since"); since != "" {
+			if _, err := providers.ParseSince(since); err != nil {
+				return err
+			}
+			period = since
+		}
+
+		// --from/--to override both with an explicit range, labelled FROM..TO
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
+		if from != "" || to != "" {
+			spec := from + providers.DateRangeSeparator + to
+			if _, _, err := providers.ParseDateRange(spec); err != nil {
+				return utils.NewValidationError("range", err.Error())
+			}
+			period = spec
+		}
+
+		// The partial current day is included unless complete days are requested
+		includeToday, _ := cmd.Flags().GetBool("include-today")
+		completeDaysOnly, _ := cmd.Flags().GetBool("complete-days-only")
+		completeDays := completeDaysOnly || !includeToday
+
+		// Resolve the output format - the flag overrides output.default_format
+		format := config.GetString("output.default_format")
+		if cmd.Flags().Changed("format") {
+			format, _ = cmd.Flags().GetString("format")
+		}
+		format = strings.ToLower(format)
+		watchDiff, _ := cmd.Flags().GetBool("watch-diff")
+		watchRate, _ := cmd.Flags().GetBool("watch-rate")
+		if (watch || watchDiff || watchRate) && !cmd.Flags().Changed("format") {
+			// Watch mode always renders tables, even when JSON is the configured default
+			format = formatTable
+		}
+		if format != formatTable && format != formatJSON && format != formatCSV {
+			return utils.NewValidationError("format", fmt.Sprintf("invalid format: %s. Valid formats are: %s, %s, %s", format, formatTable, formatJSON, formatCSV))
+		}
+		output, _ := cmd.Flags().GetString("output")
+		if output != "" && format != formatCSV {
+			return utils.NewValidationError("output", "--output only applies to --format csv")
+		}
+		excludeModels, _ := cmd.Flags().GetString("exclude-models")
+		minTokens, _ := cmd.Flags().GetInt64("min-tokens")
+		minCost, _ := cmd.Flags().GetFloat64("min-cost")
+		excludeFromTotal, _ := cmd.Flags().GetBool("exclude-from-total")
+		if minTokens < 0 {
+			return utils.NewValidationError("min-tokens", "must not be negative")
+		}
+		if minCost < 0 {
+			return utils.NewValidationError("min-cost", "must not be negative")
+		}
+		filter := newModelFilter(excludeModels, minTokens, minCost, excludeFromTotal)
+		if excludeFromTotal && !filter.Active() {
+			return utils.NewValidationError("exclude-from-total", "--exclude-from-total only applies with --exclude-models, --min-tokens or --min-cost")
+		}
+
+		buckets, _ := cmd.Flags().GetBool("buckets")
+		if buckets && format != formatJSON {
+			return utils.NewValidationError("buckets", "--buckets only applies to --format json")
+		}
+
+		// Resolve which report sections to show and in what order
+		noSummary, _ := cmd.Flags().GetBool("no-summary")
+		sectionOrder, _ := cmd.Flags().GetString("section-order")
+		sections, err := parseSectionOrder(sectionOrder, noSummary)
+		if err != nil {
+			return err
+		}
+		display := config.DisplaySettings()
+		if !display.ShowRecommendations && strings.TrimSpace(sectionOrder) == "" {
+			// An explicit --section-order still shows recommendations if listed
+			sections = removeString(sections, sectionRecommendations)
+		}
+		if byType, _ := cmd.Flags().GetBool("by-type"); byType && !containsString(sections, sectionTypes) {
+			sections = append(sections, sectionTypes)
+		}
+		if byProject, _ := cmd.Flags().GetBool("by-project"); byProject && !containsString(sections, sectionProjects) {
+			sections = append(sections, sectionProjects)
+		}
+
+		// Resolve the date layout - the flag overrides display.date_format
+		dateFormat := display.DateFormat
+		if cmd.Flags().Changed("date-format") {
+			dateFormat, _ = cmd.Flags().GetString("date-format")
+		}
+		if err := utils.ValidateDateFormat(dateFormat); err != nil {
+			return err
+		}
+
+		count, _ := cmd.Flags().GetString("count")
+		count = strings.ToLower(count)
+		if count != countTotal && count != countInput && count != countOutput {
+			return utils.NewValidationError("count", fmt.Sprintf("invalid count: %s. Valid values are: %s, %s, %s", count, countTotal, countInput, countOutput))
+		}
+
+		costUnit, _ := cmd.Flags().GetString("cost-unit")
+		costUnit = strings.ToLower(costUnit)
+		if costUnit != costUnit1K && costUnit != costUnit1M {
+			return utils.NewValidationError("cost-unit", fmt.Sprintf("invalid cost unit: %s. Valid values are: %s, %s", costUnit, costUnit1K, costUnit1M))
+		}
+
+		costBreakdown, _ := cmd.Flags().GetString("cost-breakdown")
+		costBreakdown = strings.ToLower(costBreakdown)
+		if costBreakdown != costBreakdownTotal && costBreakdown != costBreakdownInputOutput {
+			return utils.NewValidationError("cost-breakdown", fmt.Sprintf("invalid cost breakdown: %s. Valid values are: %s, %s", costBreakdown, costBreakdownTotal, costBreakdownInputOutput))
+		}
+
+		maxAge, _ := cmd.Flags().GetDuration("max-age")
+		if maxAge < 0 {
+			return utils.NewValidationError("max-age", "must not be negative")
+		}
+		maxPages, _ := cmd.Flags().GetInt("max-pages")
+		if maxPages < 0 {
+			return utils.NewValidationError("max-pages", "must not be negative (use 0 for unlimited)")
+		}
+
+		compact, _ := cmd.Flags().GetBool("compact")
+
+		// Grouping by anything other than the model alone switches to the usage-only table
+		groupByFlag, _ := cmd.Flags().GetString("group-by")
+		groupBy, groupHeaders, err := parseGroupBy(groupByFlag)
+		if err != nil {
+			return err
+		}
+		grouped := len(groupBy) != 1 || groupBy[0] != providers.GroupByModel
+		if grouped {
+			if format != formatTable {
+				return utils.NewValidationError("group-by", "--group-by only applies to table output")
+			}
+			if watch || watchDiff || watchRate {
+				return utils.NewValidationError("group-by", "--group-by can't be combined with watch mode")
+			}
+			if compact {
+				return utils.NewValidationError("group-by", "--group-by can't be combined with --compact")
+			}
+			if filter.Active() {
+				return utils.NewValidationError("group-by", "--group-by can't be combined with --exclude-models, --min-tokens or --min-cost")
+			}
+			if pushURL, _ := cmd.Flags().GetString("push"); pushURL != "" {
+				return utils.NewValidationError("group-by", "--group-by can't be combined with --push")
+			}
+		}
+
+		// --project overrides openai.project_id for this run
+		if cmd.Flags().Changed("project") {
+			project, _ := cmd.Flags().GetString("project")
+			config.Set("openai.project_id", strings.TrimSpace(project))
+		}
+
+		// Fit the table to the terminal unless a width is given explicitly
+		width := terminalWidth()
+		if cmd.Flags().Changed("width") {
+			width, _ = cmd.Flags().GetInt("width")
+		}
+
+		opts := displayOptions{
+			out:           cmd.OutOrStdout(),
+			sections:      sections,
+			dateFormat:    dateFormat,
+			width:         width,
+			compact:       compact,
+			showProgress:  display.ShowProgress,
+			numbers:       newNumberFormat(display),
+			orgLabel:      orgDisplayName(config.GetString("openai.organization_label"), config.GetString("openai.organization_id")),
+			count:         count,
+			costUnit:      costUnit,
+			costBreakdown: costBreakdown,
+			filter:        filter,
+			completeDays:  completeDays,
+		}
+
+		// Diff mode implies watch mode
+		if watchDiff {
+			watch = true
+			opts.diff = &watchState{}
+		}
+		// Rate mode also implies watch mode
+		if watchRate {
+			watch = true
+			opts.rate = &rateState{}
+		}
+
 		// Validate watch mode - only allow for 1d period
 		//if watch && period != "1d" {
 		// return fmt.Errorf("watch mode (-w) is only available for 1-day period (--period 1d). For longer periods, use regular mode")
 		// }
 
-		// Warn about longer period limitations
-		if period == "30d" || period == "90d" || period == "1y" || period == "all" {
-			fmt.Println("⚠️  Note: Longer periods may take longer to load and may have limited data availability due to OpenAI API limitations.")
-			fmt.Println("   Consider using --period 7d for more reliable results.")
-			fmt.Println()
+		// Warn about longer period limitations (JSON output must stay parseable)
+		if format == formatTable && (period == "30d" || period == "90d" || period == "1y" || period == "all" || providers.IsQuarterPeriod(period)) {
+			fmt.Fprintln(opts.out, icon("warning")+"Note: Longer periods may take longer to load and may have limited data availability due to OpenAI API limitations.")
+			fmt.Fprintln(opts.out, "   Consider using --period 7d for more reliable results.")
+			fmt.Fprintln(opts.out)
 		}
 
 		// Get provider
@@ -134,274 +454,1316 @@ Examples:
 			return fmt.Errorf("OpenAI provider not available")
 		}
 
-		// Cast provider to OpenAIProvider for displayOpenAIData
-		openaiProvider, ok := provider.(*providers.OpenAIProvider)
-		if !ok {
-			return fmt.Errorf("failed to get OpenAI provider")
+		if cmd.Flags().Changed("max-age") {
+			if p, ok := provider.(cacheAgeLimiter); ok {
+				p.SetMaxCacheAge(maxAge)
+			}
+		}
+		if cmd.Flags().Changed("max-pages") {
+			if p, ok := provider.(pageLimiter); ok {
+				p.SetMaxPages(maxPages)
+			}
+		}
+		if failFast, _ := cmd.Flags().GetBool("fail-fast"); failFast {
+			if p, ok := provider.(failFaster); ok {
+				p.SetFailFast(true)
+			}
+		}
+		// Watch refreshes bypass the cache, but completed days can't change, so
+		// only the current day needs refetching each time
+		if watch && config.GetBool("settings.watch_cache_completed_days") {
+			if p, ok := provider.(completedDayCacher); ok {
+				p.SetCacheCompletedDays(true)
+			}
+		}
+
+		strict, _ := cmd.Flags().GetBool("strict")
+
+		if pushURL, _ := cmd.Flags().GetString("push"); pushURL != "" {
+			return strictResult(strict, pushUsageJSON(provider, period, pushURL, filter, completeDays, debug))
+		}
+
+		if grouped {
+			err := displayGroupedUsage(provider, period, groupBy, groupHeaders, opts, debug)
+			if showRequestStats, _ := cmd.Flags().GetBool("show-request-stats"); showRequestStats {
+				displayRequestStats(opts.out, provider)
+			}
+			return strictResult(strict, err)
+		}
+
+		// Machine-readable output keeps stdout clean, so the footer goes to stderr
+		showRequestStats, _ := cmd.Flags().GetBool("show-request-stats")
+		if format == formatJSON || format == formatCSV {
+			if showRequestStats {
+				defer displayRequestStats(os.Stderr, provider)
+			}
+			if format == formatCSV {
+				if watch {
+					// validateFlags only lets watch mode get here with --watch-overwrite
+					return watchUsageCSV(provider, period, output, filter, debug)
+				}
+				return strictResult(strict, writeUsageCSV(provider, period, output, filter, completeDays, false, debug))
+			}
+			return strictResult(strict, writeUsageJSON(provider, period, filter, completeDays, false, debug, jsonPretty(cmd), buckets))
 		}
 
 		// If watch mode, run in a loop
 		if watch {
 			for {
 				// Clear screen
-				fmt.Print("\033[H\033[2J")
+				fmt.Fprint(opts.out, "\033[H\033[2J")
 
 				// Display data with cache bypassed for fresh data
-				if err := displayOpenAIData(openaiProvider, period, true, debug); err != nil {
-					fmt.Printf("❌ Error: %v\n", err)
+				if err := displayOpenAIData(provider, period, opts, true, debug); err != nil {
+					fmt.Fprintf(opts.out, icon("error")+"Error: %v\n", err)
+				}
+				if showRequestStats {
+					displayRequestStats(opts.out, provider)
 				}
 
-				// Show refresh info
-				fmt.Printf("\n🔄 Refreshing every 30 seconds... (Press Ctrl+C to stop)\n")
-
-				// Wait 30 seconds
-				time.Sleep(30 * time.Second)
+				// Count down to the next refresh on a single status line
+				fmt.Fprintln(opts.out)
+				updated := time.Now()
+				for now := updated; now.Sub(updated) < watchInterval; now = time.Now() {
+					fmt.Fprintf(opts.out, "\r\033[K"+icon("refresh")+"%s (Press Ctrl+C to stop)", watchStatus(now.Sub(updated), watchInterval-now.Sub(updated)))
+					time.Sleep(time.Second)
+				}
 			}
 		} else {
 			// Single run
-			return displayOpenAIData(openaiProvider, period, false, debug)
+			err := displayOpenAIData(provider, period, opts, false, debug)
+			if showRequestStats {
+				displayRequestStats(opts.out, provider)
+			}
+			return strictResult(strict, err)
 		}
 	},
 }
 
+// watchInterval is how often watch mode refreshes
+const watchInterval = 30 * time.Second
+
+// watchUsageCSV rewrites the CSV report in output every watchInterval until
+// interrupted. A failed refresh is reported and leaves the previous file in place.
+// Warnings go to stderr on every refresh; validateFlags rejects --strict here,
+// as there is no single run for it to fail.
+func watchUsageCSV(provider providers.Provider, period, output string, filter tokenwatch.ModelFilter, debug bool) error {
+	for {
+		// validateFlags rejects --complete-days-only with watch mode
+		if err := writeUsageCSV(provider, period, output, filter, false, true, debug); err != nil {
+			fmt.Fprintf(os.Stderr, icon("error")+"Error: %v\n", err)
+		}
+		time.Sleep(watchInterval)
+	}
+}
+
+// watchStatus describes how stale the watch display is and when it next refreshes,
+// e.g. "Last updated 5s ago, next refresh in 25s"
+func watchStatus(elapsed, remaining time.Duration) string {
+	updated := "just now"
+	if secs := int(elapsed / time.Second); secs > 0 {
+		updated = fmt.Sprintf("%ds ago", secs)
+	}
+
+	// Round up so the countdown never shows 0s while still waiting
+	remainingSecs := int((remaining + time.Second - 1) / time.Second)
+	if remainingSecs < 0 {
+		remainingSecs = 0
+	}
+
+	return fmt.Sprintf("Last updated %s, next refresh in %ds", updated, remainingSecs)
+}
+
 func init() {
-	usageCmd.Flags().StringP("period", "p", "7d", "Time period: 1d (recent activity), 7d (historical data), 30d, 90d, 1y, all")
+	usageCmd.Flags().StringP("period", "p", "7d", "Time period: 1d (recent activity), 7d (historical data), 30d, 90d, 1y, all, q1-q4, last-quarter")
+	usageCmd.Flags().String("since", "", "Show usage for a Go duration up to now, e.g. 36h (overrides --period)")
+	usageCmd.Flags().String("from", "", "Start of a custom range: YYYY-MM-DD or RFC3339, at most 5 years ago (default 5 years ago; overrides --period and --since)")
+	usageCmd.Flags().String("to", "", "End of a custom range: YYYY-MM-DD (inclusive) or RFC3339 (default now)")
+	usageCmd.Flags().Bool("include-today", true, "Include the partial current day (default)")
+	usageCmd.Flags().Bool("complete-days-only", false, "End the period at the previous UTC midnight (same as --include-today=false)")
 	usageCmd.Flags().BoolP("watch", "w", false, "Watch mode - refresh every 30 seconds")
 	usageCmd.Flags().BoolP("debug", "d", false, "Enable debug logging for API calls")
+	usageCmd.Flags().Bool("no-summary", false, "Hide the summary section")
+	usageCmd.Flags().String("section-order", "", "Comma-separated section order: summary,recommendations,table,types,projects")
+	usageCmd.Flags().Bool("by-type", false, "Show a cost breakdown by usage type (input, output, batch, ...)")
+	usageCmd.Flags().Bool("by-project", false, "Show a cost breakdown by project")
+	usageCmd.Flags().String("date-format", "", "Go time layout for dates (overrides display.date_format)")
+	usageCmd.Flags().Int("width", 0, "Terminal width to fit the table in (default: detected, 0 for unlimited)")
+	usageCmd.Flags().String("count", countTotal, "Token metric for the total column and sorting: total, input, output")
+	usageCmd.Flags().String("project", "", "Only report usage for this project ID (overrides openai.project_id)")
+	usageCmd.Flags().String("cost-unit", costUnit1K, "Denominator of the cost-efficiency column: 1k, 1m")
+	usageCmd.Flags().String("exclude-models", "", "Comma-separated models to hide from the report, e.g. gpt-3.5-turbo,text-embedding-3-small")
+	usageCmd.Flags().Int64("min-tokens", 0, "Hide models with fewer input + output tokens than this")
+	usageCmd.Flags().Float64("min-cost", 0, "Hide models that cost less than this")
+	usageCmd.Flags().Bool("exclude-from-total", false, "Leave models hidden by --exclude-models, --min-tokens or --min-cost out of TOTAL too")
+	usageCmd.Flags().String("cost-breakdown", costBreakdownTotal, "Cost columns in the model table: total, input-output")
+	usageCmd.Flags().String("group-by", "model", "Comma-separated usage grouping: model, project, api-key (anything but model shows tokens only)")
+	usageCmd.Flags().Bool("compact", false, "Narrow key/value layout for 80-column terminals (top 3 models)")
+	usageCmd.Flags().Bool("watch-diff", false, "Watch mode that highlights values changed since the last refresh")
+	usageCmd.Flags().Bool("watch-rate", false, "Watch mode that shows tokens/min and $/hr observed between refreshes")
+	usageCmd.Flags().Int("max-pages", providers.DefaultMaxPages, "Cap on API pages per fetch, 0 for unlimited (overrides settings.max_pages)")
+	usageCmd.Flags().Bool("fail-fast", false, "Cancel the remaining chunks of a long period as soon as one fails")
+	usageCmd.Flags().Duration("max-age", 0, "Ignore cached data older than this, e.g. 2m (0 uses the cache TTL only)")
+	usageCmd.Flags().Bool("show-request-stats", false, "Show the HTTP requests, pages, cache hits and bytes downloaded by this run")
+	usageCmd.Flags().Bool("strict", false, "Exit non-zero after rendering if any warning was raised (e.g. pricing unavailable, partial data)")
+	usageCmd.Flags().String("format", "", "Output format: table, json, csv (overrides output.default_format)")
+	usageCmd.Flags().StringP("output", "o", "", "Write CSV output to this file instead of stdout")
+	usageCmd.Flags().Bool("watch-overwrite", false, "With --watch, rewrite the --output CSV file on every refresh")
+	usageCmd.Flags().Bool("json-pretty", false, "Indent JSON output (default when stdout is a terminal)")
+	usageCmd.Flags().Bool("compact-json", false, "Write JSON output on a single line (default when piped)")
+	usageCmd.Flags().Bool("buckets", false, "Include each model's per-day buckets with start/end times in JSON output")
+	usageCmd.MarkFlagsMutuallyExclusive("json-pretty", "compact-json")
+	usageCmd.MarkFlagsMutuallyExclusive("include-today", "complete-days-only")
+	usageCmd.Flags().String("push", "", "PUT the JSON report to this URL instead of displaying it")
 	RootCmd.AddCommand(usageCmd)
 }
 
+// validateFlags rejects usage flag combinations that don't make sense together.
+// Watch mode redraws the screen every refresh, so it can't be combined with
+// machine-readable output or pushes, which would repeat the whole report each time.
+func validateFlags(cmd *cobra.Command) error {
+	flags := cmd.Flags()
+
+	watch, _ := flags.GetBool("watch")
+	watchDiff, _ := flags.GetBool("watch-diff")
+	watchRate, _ := flags.GetBool("watch-rate")
+	overwrite, _ := flags.GetBool("watch-overwrite")
+	if overwrite && !watch {
+		return utils.NewValidationError("watch-overwrite", "--watch-overwrite only applies with --watch")
+	}
+	if !watch && !watchDiff && !watchRate {
+		return nil
+	}
+
+	includeToday, _ := flags.GetBool("include-today")
+	if completeDaysOnly, _ := flags.GetBool("complete-days-only"); completeDaysOnly || !includeToday {
+		return utils.NewValidationError("watch", "--watch shows live activity, so it always includes the current day")
+	}
+
+	if strict, _ := flags.GetBool("strict"); strict {
+		return utils.NewValidationError("watch", "--strict fails a single run on warnings, so it can't be combined with --watch")
+	}
+	// --watch-overwrite is the one way to watch into a file: the CSV file is
+	// replaced on every refresh instead of the report being repeated
+	if overwrite {
+		format, _ := flags.GetString("format")
+		output, _ := flags.GetString("output")
+		if !strings.EqualFold(format, formatCSV) || output == "" {
+			return utils.NewValidationError("watch-overwrite", "--watch-overwrite needs --format csv and --output <file>")
+		}
+		if watchDiff || watchRate {
+			return utils.NewValidationError("watch-overwrite", "--watch-diff and --watch-rate only apply to the table, not to --watch-overwrite")
+		}
+	} else if format, _ := flags.GetString("format"); strings.EqualFold(format, formatJSON) || strings.EqualFold(format, formatCSV) {
+		return utils.NewValidationError("watch", fmt.Sprintf("--watch cannot be combined with --format %[1]s; run 'tokenwatch usage --format %[1]s' on a schedule instead, or use --format csv --output <file> --watch-overwrite", strings.ToLower(format)))
+	}
+	if push, _ := flags.GetString("push"); push != "" {
+		return utils.NewValidationError("watch", "--watch cannot be combined with --push; run 'tokenwatch usage --push <url>' on a schedule instead")
+	}
+	if flags.Changed("json-pretty") || flags.Changed("compact-json") {
+		return utils.NewValidationError("watch", "--json-pretty and --compact-json only apply to --format json, which can't be used with --watch")
+	}
+
+	return nil
+}
+
+// warnf prints a warning to w and records it so --strict can fail the run
+func warnf(w io.Writer, format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	utils.RecordWarning(msg)
+	fmt.Fprintln(w, icon("warning")+"Warning: "+msg)
+}
+
+// strictResult returns err, or in strict mode an error listing the warnings
+// recorded during the run once everything has been rendered
+func strictResult(strict bool, err error) error {
+	if err != nil || !strict {
+		return err
+	}
+	if warnings := utils.Warnings(); len(warnings) > 0 {
+		return fmt.Errorf("--strict: %d warning(s) raised: %s", len(warnings), strings.Join(warnings, "; "))
+	}
+	return nil
+}
+
+// Output formats accepted by --format
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+	formatCSV   = "csv"
+)
+
+// jsonPretty reports whether JSON output should be indented. An explicit
+// --json-pretty or --compact-json wins; otherwise output is pretty on a terminal.
+func jsonPretty(cmd *cobra.Command) bool {
+	if cmd.Flags().Changed("json-pretty") {
+		pretty, _ := cmd.Flags().GetBool("json-pretty")
+		return pretty
+	}
+	if compactJSON, _ := cmd.Flags().GetBool("compact-json"); compactJSON {
+		return false
+	}
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// displayOptions controls how a usage report is rendered
+type displayOptions struct {
+	out           io.Writer // defaults to os.Stdout
+	sections      []string
+	dateFormat    string
+	width         int
+	compact       bool
+	showProgress  bool
+	numbers       numberFormat           // resolved once from the display settings
+	diff          *watchState            // nil unless --watch-diff is set
+	rate          *rateState             // nil unless --watch-rate is set
+	orgLabel      string                 // shown in the header; empty when no organization is configured
+	count         string                 // token metric in the total column: total, input, or output
+	costUnit      string                 // cost-efficiency denominator: 1k or 1m tokens
+	costBreakdown string                 // cost columns in the model table: total or input-output
+	filter        tokenwatch.ModelFilter // models hidden from the report, and from the totals with ExcludeFromTotal
+	// completeDays ends the period at the previous UTC midnight
+	completeDays bool
+}
+
+// orgIDDisplayLength is how much of an organization ID is shown when it has no label
+const orgIDDisplayLength = 12
+
+// orgDisplayName returns label if set, otherwise orgID truncated to orgIDDisplayLength
+func orgDisplayName(label, orgID string) string {
+	if label = strings.TrimSpace(label); label != "" {
+		return label
+	}
+	if len(orgID) > orgIDDisplayLength {
+		return orgID[:orgIDDisplayLength] + "…"
+	}
+	return orgID
+}
+
+// watchState retains the previous reading between watch refreshes
+type watchState struct {
+	previous []models.ModelStats
+	seeded   bool
+}
+
+// apply compares the report against the previous reading, then remembers it for the next refresh
+func (w *watchState) apply(r *reportData) {
+	if w.seeded {
+		r.diffAgainst(w.previous)
+	}
+	w.previous = r.models
+	w.seeded = true
+}
+
+// watchRate is the approximate rate of usage observed between two readings
+type watchRate struct {
+	TokensPerMinute float64
+	CostPerHour     float64
+	Interval        time.Duration
+}
+
+// computeRate derives the usage rate from two totals read interval apart.
+// The period window slides between readings, so usage leaving the window can
+// outweigh new usage; such negative deltas are reported as no activity.
+func computeRate(previous, current models.TotalStats, interval time.Duration) watchRate {
+	rate := watchRate{Interval: interval}
+	if interval <= 0 {
+		return rate
+	}
+	if tokens := current.TotalTokens - previous.TotalTokens; tokens > 0 {
+		rate.TokensPerMinute = float64(tokens) / interval.Minutes()
+	}
+	if cost := current.TotalCost - previous.TotalCost; cost > 0 {
+		rate.CostPerHour = cost / interval.Hours()
+	}
+	return rate
+}
+
+// rateState retains the previous totals and when they were read between watch refreshes
+type rateState struct {
+	previous models.TotalStats
+	readAt   time.Time
+}
+
+// apply computes the rate since the previous reading, then remembers this one.
+// It returns nil on the first reading.
+func (s *rateState) apply(totals models.TotalStats, now time.Time) *watchRate {
+	var rate *watchRate
+	if !s.readAt.IsZero() {
+		r := computeRate(s.previous, totals, now.Sub(s.readAt))
+		rate = &r
+	}
+	s.previous = totals
+	s.readAt = now
+	return rate
+}
+
+// displayWatchRate prints the observed rate, or that it is still being measured
+func displayWatchRate(w io.Writer, rate *watchRate) {
+	if rate == nil {
+		fmt.Fprintln(w, color.HiBlackString(icon("average")+"Rate: measuring, shown after the next refresh"))
+		return
+	}
+	fmt.Fprintf(w, icon("average")+"Rate: ~%s tokens/min, ~%s/hr %s\n",
+		color.CyanString("%.0f", rate.TokensPerMinute),
+		color.YellowString("$%.4f", rate.CostPerHour),
+		color.HiBlackString("(over the last %s)", rate.Interval.Round(time.Second)))
+}
+
+// periodLabel describes period for report headers, e.g. "Last 7d" or
+// "2024-01-01 to 2024-01-31"
+func periodLabel(period string) string {
+	switch {
+	case providers.IsDateRange(period):
+		from, to, _ := strings.Cut(period, providers.DateRangeSeparator)
+		if from == "" {
+			from = "earliest"
+		}
+		if to == "" {
+			to = "now"
+		}
+		return from + " to " + to
+	case period == providers.PeriodLastQuarter:
+		return "Last quarter"
+	case providers.IsQuarterPeriod(period):
+		return strings.ToUpper(period) + " (this year)"
+	default:
+		return "Last " + period
+	}
+}
+
 // displayOpenAIData fetches and displays OpenAI usage data
-func displayOpenAIData(provider *providers.OpenAIProvider, period string, bypassCache bool, debug bool) error {
+func displayOpenAIData(provider providers.Provider, period string, opts displayOptions, bypassCache bool, debug bool) error {
+	w := opts.out
+	if w == nil {
+		w = os.Stdout
+	}
+
 	// Display header
-	fmt.Printf("🤖 OPENAI USAGE - Last %s\n", period)
-	fmt.Printf("⏰ Generated: %s\n\n", time.Now().Format("2006-01-02 15:04:05"))
+	if opts.orgLabel != "" {
+		fmt.Fprintf(w, icon("usage")+"OPENAI USAGE (%s) - %s\n", opts.orgLabel, periodLabel(period))
+	} else {
+		fmt.Fprintf(w, icon("usage")+"OPENAI USAGE - %s\n", periodLabel(period))
+	}
+	fmt.Fprintf(w, icon("time")+"Generated: %s\n\n", formatTimestamp(time.Now(), opts.dateFormat))
 
 	// Get time range
-	startTime, endTime := providers.GetPeriodTimeRange(period)
+	startTime, endTime := providers.GetPeriodTimeRange(period, opts.completeDays)
+
+	// Debug output is interleaved with fetching, so only show progress without it
+	fetching := startProgress(opts.showProgress && !debug, "Fetching usage and cost data...")
 
 	// Fetch consumption data
 	consumptions, err := provider.GetConsumption(startTime, endTime, bypassCache, debug)
 	if err != nil {
+		fetching.Done()
 		return fmt.Errorf("failed to get consumption data: %w", err)
 	}
 
 	// Fetch pricing data
 	pricings, err := provider.GetPricing(startTime, endTime, bypassCache, debug)
+	pricingAvailable := err == nil
+	fetching.Done()
+	if stats, ok := provider.(clientStatsProvider); ok && debug {
+		displayClientStats(w, stats.ClientStats())
+	}
 	if err != nil {
 		// Don't fail if pricing data is unavailable - just log a warning
-		fmt.Printf("⚠️  Warning: Could not fetch pricing data: %v\n", err)
-		fmt.Println("   This is normal for longer time periods or when costs are not yet available.")
-		fmt.Println()
+		warnf(w, "Could not fetch pricing data: %v", err)
+		fmt.Fprintln(w, "   This is normal for longer time periods or when costs are not yet available.")
+		fmt.Fprintln(w)
 	}
 
-	// Create pricing map for easy lookup
-	pricingMap := make(map[string]float64)
+	// Aggregate data by model
+	aggregated, err := tokenwatch.AggregateModelStats(consumptions, pricings)
+	if err != nil {
+		return err
+	}
+	modelStats := countTokens(aggregated, opts.count)
+
+	// Flag models over their own budget, even when the total looks fine
+	breaches := tokenwatch.CheckModelBudgets(aggregated, config.GetModelBudgets(), startTime, endTime)
+	for _, b := range breaches {
+		warnf(w, "%s", tokenwatch.BudgetBreachMessage(b))
+	}
+	if len(breaches) > 0 {
+		fmt.Fprintln(w)
+	}
+
+	// Check if we have any data to display
+	if len(modelStats) == 0 {
+		fmt.Fprintln(w, icon("info")+"No consumption or cost data found for the specified period.")
+		fmt.Fprintln(w, "   This could mean:")
+		fmt.Fprintln(w, "   • No API calls were made during this period")
+		fmt.Fprintln(w, "   • The data is not yet available from OpenAI")
+		fmt.Fprintln(w, "   • You're checking a period before your account was created")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, icon("tip")+"Try using a shorter period like '--period 7d' to see recent data.")
+		fmt.Fprintln(w, "   OpenAI only returns data for periods with actual activity.")
+		return nil
+	}
+
+	// Calculate totals and display
+	pricingSummary := models.NewPricingSummary(provider.GetPlatform(), "", period, startTime, endTime)
 	for _, p := range pricings {
-		pricingMap[p.Model] = pricingMap[p.Model] + p.Amount
+		pricingSummary.AddPricing(p)
 	}
 
-	// Aggregate data by model
-	modelMap := make(map[string]*ModelStats)
+	// In debug mode, cross-check the aggregated totals to catch double counting
+	if debug {
+		validateSummaries(provider.GetPlatform(), period, startTime, endTime, consumptions, pricingSummary)
+	}
 
-	for _, c := range consumptions {
-		if _, exists := modelMap[c.Model]; !exists {
-			modelMap[c.Model] = &ModelStats{Model: c.Model}
+	// Totals come from the uncounted stats, so TotalTokens stays input plus output
+	// whatever --count shows. Hidden models still count towards the totals
+	// unless the filter excludes them from them too.
+	kept, filtered := opts.filter.Apply(aggregated)
+	totals := opts.filter.Totals(aggregated, kept)
+	modelStats = countTokens(kept, opts.count)
+
+	report := reportData{
+		period:          period,
+		startTime:       startTime,
+		endTime:         endTime,
+		dateFormat:      opts.dateFormat,
+		width:           opts.width,
+		count:           opts.count,
+		costUnit:        opts.costUnit,
+		costBreakdown:   opts.costBreakdown,
+		numbers:         opts.numbers,
+		filtered:        filtered,
+		filteredInTotal: !opts.filter.ExcludeFromTotal,
+		models:          modelStats,
+		totals:          totals,
+		pricing:         pricingSummary,
+		costStatus:      tokenwatch.DetermineCostStatus(period, totals.TotalCost, pricingAvailable),
+		costLag:         models.CostLagDays(consumptions, pricings),
+		usageBucket:     providers.UsageBucketWidth,
+		costBucket:      providers.CostBucketWidth,
+	}
+	if opts.diff != nil {
+		opts.diff.apply(&report)
+	}
+	if opts.rate != nil {
+		report.rate = opts.rate.apply(totals, time.Now())
+		report.showRate = true
+	}
+
+	// Project costs need a separate request, so only fetch them when shown
+	if containsString(opts.sections, sectionProjects) && !opts.compact {
+		if p, ok := provider.(projectCostProvider); ok {
+			projectCosts, err := p.GetProjectCosts(startTime, endTime, bypassCache, debug)
+			if err != nil {
+				warnf(w, "Could not fetch project costs: %v", err)
+				fmt.Fprintln(w)
+			} else {
+				report.projectCosts = projectCosts
+			}
 		}
+	}
 
-		stats := modelMap[c.Model]
-		stats.InputTokens += c.InputTokens
-		stats.OutputTokens += c.OutputTokens
-		stats.TotalTokens += c.TotalTokens
-		stats.Requests += c.RequestCount
+	// Compact mode replaces all sections with a narrow key/value layout
+	if opts.compact {
+		displayCompactReport(w, report)
+		if report.showRate {
+			displayWatchRate(w, report.rate)
+		}
+		return nil
 	}
 
-	// Add pricing data
-	for model, cost := range pricingMap {
-		if stats, exists := modelMap[model]; exists {
-			stats.Cost = cost
-		} else {
-			// Create entry for models with costs but no usage (shouldn't happen normally)
-			modelMap[model] = &ModelStats{
-				Model: model,
-				Cost:  cost,
+	// Display each requested section in order
+	for i, name := range opts.sections {
+		// The table has no trailing blank line, so separate it from what follows
+		if i > 0 && opts.sections[i-1] == sectionTable {
+			fmt.Fprintln(w)
+		}
+		sectionRenderers[name](w, report)
+	}
+
+	if report.showRate {
+		fmt.Fprintln(w)
+		displayWatchRate(w, report.rate)
+	}
+
+	return nil
+}
+
+// reportData holds the aggregated data shared by all report sections
+type reportData struct {
+	period          string
+	startTime       time.Time
+	endTime         time.Time
+	dateFormat      string
+	width           int    // terminal width the table must fit in, 0 for unlimited
+	count           string // token metric in the total column: total, input, or output
+	costUnit        string // cost-efficiency denominator: 1k or 1m tokens
+	costBreakdown   string // cost columns in the model table: total or input-output
+	numbers         numberFormat
+	filtered        int  // models hidden by the model filter
+	filteredInTotal bool // true when hidden models still count in the totals
+	models          []models.ModelStats
+	totals          models.TotalStats
+	pricing         *models.PricingSummary
+	costStatus      string
+	costLag         int                     // days the latest cost data trails the latest usage data
+	usageBucket     string                  // bucket width usage was fetched at
+	costBucket      string                  // bucket width costs were fetched at, never finer than 1d
+	showRate        bool                    // true in --watch-rate mode
+	rate            *watchRate              // rate since the previous reading, nil on the first
+	projectCosts    map[string]float64      // cost per project, nil unless the projects section is shown
+	diffing         bool                    // true once there is a previous reading to compare against
+	changes         map[string]modelChanges // per-model changes since the previous reading
+}
+
+// modelChanges flags which cells of a model row changed since the previous reading
+type modelChanges struct {
+	InputTokens  bool
+	OutputTokens bool
+	TotalTokens  bool
+	Requests     bool
+	Cost         bool
+}
+
+// any reports whether at least one cell changed
+func (c modelChanges) any() bool {
+	return c.InputTokens || c.OutputTokens || c.TotalTokens || c.Requests || c.Cost
+}
+
+// diffAgainst records which models changed compared to the previous reading.
+// Models that did not exist previously are marked as changed in every cell.
+func (r *reportData) diffAgainst(previous []models.ModelStats) {
+	byModel := make(map[string]models.ModelStats, len(previous))
+	for _, m := range previous {
+		byModel[m.Model] = m
+	}
+
+	r.diffing = true
+	r.changes = make(map[string]modelChanges)
+	for _, m := range r.models {
+		prev, existed := byModel[m.Model]
+		c := modelChanges{
+			InputTokens:  !existed || m.InputTokens != prev.InputTokens,
+			OutputTokens: !existed || m.OutputTokens != prev.OutputTokens,
+			TotalTokens:  !existed || m.TotalTokens != prev.TotalTokens,
+			Requests:     !existed || m.Requests != prev.Requests,
+			Cost:         !existed || m.Cost != prev.Cost,
+		}
+		if c.any() {
+			r.changes[m.Model] = c
+		}
+	}
+}
+
+// hasChanges reports whether anything changed since the previous reading
+func (r reportData) hasChanges() bool {
+	return len(r.changes) > 0
+}
+
+// Section names accepted by --section-order
+const (
+	sectionSummary         = "summary"
+	sectionRecommendations = "recommendations"
+	sectionTable           = "table"
+	sectionTypes           = "types"
+	sectionProjects        = "projects"
+)
+
+// defaultSectionOrder is the order sections are displayed in when none is given
+var defaultSectionOrder = []string{sectionSummary, sectionRecommendations, sectionTable}
+
+// validSections lists every section accepted by --section-order
+var validSections = []string{sectionSummary, sectionRecommendations, sectionTable, sectionTypes, sectionProjects}
+
+// sectionRenderers maps section names to the functions that display them
+var sectionRenderers = map[string]func(io.Writer, reportData){
+	sectionSummary: func(w io.Writer, r reportData) {
+		displayOpenAISummary(w, r)
+	},
+	sectionRecommendations: func(w io.Writer, r reportData) {
+		displaySmartRecommendations(w, r.period)
+	},
+	sectionTable: func(w io.Writer, r reportData) {
+		displayOpenAITable(w, r.models, r.totals, r.changes, r.width, r.count, r.costUnit, r.costBreakdown, r.numbers)
+		if r.filtered > 0 {
+			note := "not included in TOTAL"
+			if r.filteredInTotal {
+				note = "still included in TOTAL"
+			}
+			fmt.Fprintln(w, color.HiBlackString(icon("info")+"%d model(s) hidden by the model filter (%s)", r.filtered, note))
+		}
+		if r.diffing && !r.hasChanges() {
+			fmt.Fprintln(w, color.HiBlackString("· no change since last refresh"))
+		}
+	},
+	sectionTypes: func(w io.Writer, r reportData) {
+		displayUsageTypeBreakdown(w, r.pricing)
+	},
+	sectionProjects: func(w io.Writer, r reportData) {
+		displayProjectBreakdown(w, r.projectCosts, r.totals.TotalCost)
+	},
+}
+
+// parseSectionOrder turns a comma-separated section list into the sections to display,
+// dropping the summary when noSummary is set
+func parseSectionOrder(order string, noSummary bool) ([]string, error) {
+	sections := defaultSectionOrder
+	if strings.TrimSpace(order) != "" {
+		sections = nil
+		seen := make(map[string]bool)
+		for _, name := range strings.Split(order, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if _, ok := sectionRenderers[name]; !ok {
+				return nil, fmt.Errorf("invalid section: %s. Valid sections are: %s", name, strings.Join(validSections, ", "))
+			}
+			if seen[name] {
+				return nil, fmt.Errorf("duplicate section: %s", name)
+			}
+			seen[name] = true
+			sections = append(sections, name)
+		}
+	}
+
+	if !noSummary {
+		return sections, nil
+	}
+	return removeString(sections, sectionSummary), nil
+}
+
+// Grouping dimensions accepted by --group-by, mapped to the provider's
+// group_by values and the table header for each
+var groupByDimensions = map[string]struct {
+	dimension string
+	header    string
+}{
+	"model":   {providers.GroupByModel, "Model"},
+	"project": {providers.GroupByProject, "Project"},
+	"api-key": {providers.GroupByAPIKey, "API Key"},
+}
+
+// validGroupBy lists the --group-by dimensions in the order shown in errors
+var validGroupBy = []string{"model", "project", "api-key"}
+
+// parseGroupBy turns a comma-separated --group-by value into provider
+// grouping dimensions and their table headers
+func parseGroupBy(value string) (dimensions, headers []string, err error) {
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		group, ok := groupByDimensions[name]
+		if !ok {
+			return nil, nil, utils.NewValidationError("group-by", fmt.Sprintf("invalid grouping: %s. Valid values are: %s", name, strings.Join(validGroupBy, ", ")))
+		}
+		if seen[name] {
+			return nil, nil, utils.NewValidationError("group-by", fmt.Sprintf("duplicate grouping: %s", name))
+		}
+		seen[name] = true
+		dimensions = append(dimensions, group.dimension)
+		headers = append(headers, group.header)
+	}
+	return dimensions, headers, nil
+}
+
+// compactTopModels is the number of models shown in the compact layout
+const compactTopModels = 3
+
+// compactModelWidth is the widest model name shown in the compact layout
+const compactModelWidth = 32
+
+// displayCompactReport shows a narrow two-column summary that fits in 80 columns
+func displayCompactReport(w io.Writer, r reportData) {
+	days := int(r.endTime.Sub(r.startTime).Hours() / 24)
+	if days < 1 {
+		days = 1
+	}
+
+	fmt.Fprintln(w, icon("summary")+"SUMMARY")
+	fmt.Fprintln(w, color.HiBlackString(strings.Repeat("─", 40)))
+	fmt.Fprintf(w, "%-12s %s → %s\n", "Period", formatTimestamp(r.startTime, r.dateFormat), formatTimestamp(r.endTime, r.dateFormat))
+	fmt.Fprintf(w, "%-12s %s\n", "Tokens", color.CyanString("%d", r.totals.TotalTokens))
+	fmt.Fprintf(w, "%-12s %s\n", "Requests", color.MagentaString("%d", r.totals.TotalRequests))
+	fmt.Fprintf(w, "%-12s %s\n", "Cost", color.YellowString("$%.4f", r.totals.TotalCost))
+	fmt.Fprintf(w, "%-12s %s\n", "Daily cost", color.YellowString("$%.4f", r.totals.TotalCost/float64(days)))
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, icon("top")+"TOP MODELS")
+	fmt.Fprintln(w, color.HiBlackString(strings.Repeat("─", 40)))
+	for i, m := range r.models {
+		if i >= compactTopModels {
+			break
+		}
+		name := m.Model
+		if len(name) > compactModelWidth {
+			name = name[:compactModelWidth-1] + "…"
+		}
+		fmt.Fprintf(w, "%-*s %s %s\n", compactModelWidth, name,
+			color.CyanString("%12s", fmt.Sprintf("%d tok", m.TotalTokens)),
+			color.YellowString("$%.4f", m.Cost))
+	}
+	if len(r.models) > compactTopModels {
+		fmt.Fprintln(w, color.HiBlackString("+%d more", len(r.models)-compactTopModels))
+	}
+}
+
+// totalsProvider is implemented by providers that can fetch grand totals
+// without the per-model breakdown
+type totalsProvider interface {
+	GetTotals(startTime, endTime time.Time, bypassCache bool, debug bool) (models.TotalStats, error)
+}
+
+// cacheAgeLimiter is implemented by providers whose cache can be limited to recent entries
+type cacheAgeLimiter interface {
+	SetMaxCacheAge(maxAge time.Duration)
+}
+
+// pageLimiter is implemented by providers whose paginated fetches can be capped
+type pageLimiter interface {
+	SetMaxPages(n int)
+}
+
+// failFaster is implemented by providers that can abandon a chunked fetch
+// as soon as one chunk fails
+type failFaster interface {
+	SetFailFast(enabled bool)
+}
+
+// completedDayCacher is implemented by providers that can cache daily usage
+// and costs one completed day at a time
+type completedDayCacher interface {
+	SetCacheCompletedDays(enabled bool)
+}
+
+// projectCostProvider is implemented by providers that can break costs down by project
+type projectCostProvider interface {
+	GetProjectCosts(startTime, endTime time.Time, bypassCache bool, debug bool) (map[string]float64, error)
+}
+
+// groupedConsumptionProvider is implemented by providers that can break usage
+// down by dimensions other than the model
+type groupedConsumptionProvider interface {
+	GetConsumptionGrouped(startTime, endTime time.Time, groupBy []string, bypassCache bool, debug bool) ([]*models.Consumption, error)
+}
+
+// clientStatsProvider is implemented by providers that track HTTP client statistics
+type clientStatsProvider interface {
+	ClientStats() utils.ClientStats
+}
+
+// displayClientStats shows how much time the HTTP client spent retrying and throttling
+func displayClientStats(w io.Writer, stats utils.ClientStats) {
+	fmt.Fprintln(w, icon("check")+"REQUEST TIMING SUMMARY:")
+	fmt.Fprintf(w, "   Retries: %d (%d requests retried, %d failed after all attempts)\n", stats.Retries, stats.RetriedRequests, stats.FailedRequests)
+	if len(stats.AttemptsToSuccess) > 1 {
+		var parts []string
+		for i, n := range stats.AttemptsToSuccess {
+			label := fmt.Sprintf("%d", i+1)
+			if i == len(stats.AttemptsToSuccess)-1 && len(stats.AttemptsToSuccess) == utils.MaxTrackedAttempts {
+				label += "+"
 			}
+			parts = append(parts, fmt.Sprintf("%s: %d", label, n))
 		}
+		fmt.Fprintf(w, "   Attempts Before Success: %s\n", strings.Join(parts, ", "))
+	}
+	fmt.Fprintf(w, "   Time in Backoff: %s (capped at max backoff %d times)\n", stats.BackoffTime.Round(time.Millisecond), stats.MaxBackoffHits)
+	fmt.Fprintf(w, "   Rate Limiter Wait: %s\n", stats.RateLimitWait.Round(time.Millisecond))
+	fmt.Fprintln(w)
+}
+
+// fetchStatsProvider is implemented by providers that count pages and cache lookups
+type fetchStatsProvider interface {
+	FetchStats() providers.FetchStats
+}
+
+// displayRequestStats shows the API traffic TokenWatch itself caused so far
+// in this run, for --show-request-stats
+func displayRequestStats(w io.Writer, provider providers.Provider) {
+	fmt.Fprintln(w, icon("info")+"REQUEST STATS (this run):")
+	if p, ok := provider.(clientStatsProvider); ok {
+		stats := p.ClientStats()
+		fmt.Fprintf(w, "   HTTP Requests: %d (%d retries)\n", stats.Requests, stats.Retries)
 	}
+	if p, ok := provider.(fetchStatsProvider); ok {
+		stats := p.FetchStats()
+		fmt.Fprintf(w, "   Pages Fetched: %d\n", stats.Pages)
+		fmt.Fprintf(w, "   Cache: %d hits, %d misses\n", stats.CacheHits, stats.CacheMisses)
+		fmt.Fprintf(w, "   Downloaded: ~%s\n", formatBytes(stats.BytesRead))
+	}
+	fmt.Fprintln(w)
+}
 
-	// Convert to slice and sort by total tokens (descending)
-	var models []ModelStats
-	for _, stats := range modelMap {
-		// Include models that have either tokens or costs
-		if stats.TotalTokens > 0 || stats.Cost > 0 {
-			models = append(models, *stats)
+// removeString returns a copy of list without any occurrences of value
+func removeString(list []string, value string) []string {
+	var filtered []string
+	for _, item := range list {
+		if item != value {
+			filtered = append(filtered, item)
 		}
 	}
-	sort.Slice(models, func(i, j int) bool {
-		// Sort by total tokens first, then by cost
-		if models[i].TotalTokens != models[j].TotalTokens {
-			return models[i].TotalTokens > models[j].TotalTokens
+	return filtered
+}
+
+// containsString reports whether list contains value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
 		}
-		return models[i].Cost > models[j].Cost
+	}
+	return false
+}
+
+// displayUsageTypeBreakdown shows costs grouped by usage type
+func displayUsageTypeBreakdown(w io.Writer, summary *models.PricingSummary) {
+	fmt.Fprintln(w, icon("billing")+"COST BY USAGE TYPE")
+	fmt.Fprintln(w, "─"+color.HiBlackString("─────────────────────────────────────────────────"))
+
+	costs := summary.CostByType()
+	if len(costs) == 0 {
+		fmt.Fprintln(w, icon("info")+"No cost data available for this period")
+		fmt.Fprintln(w)
+		return
+	}
+
+	types := make([]string, 0, len(costs))
+	for usageType := range costs {
+		types = append(types, usageType)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		return costs[types[i]] > costs[types[j]]
 	})
 
-	// Check if we have any data to display
-	if len(models) == 0 {
-		fmt.Println("ℹ️  No consumption or cost data found for the specified period.")
-		fmt.Println("   This could mean:")
-		fmt.Println("   • No API calls were made during this period")
-		fmt.Println("   • The data is not yet available from OpenAI")
-		fmt.Println("   • You're checking a period before your account was created")
-		fmt.Println()
-		fmt.Println("💡 Try using a shorter period like '--period 7d' to see recent data.")
-		fmt.Println("   OpenAI only returns data for periods with actual activity.")
+	for _, usageType := range types {
+		share := float64(0)
+		if summary.TotalCost > 0 {
+			share = costs[usageType] / summary.TotalCost * 100
+		}
+		fmt.Fprintf(w, "   • %-20s %s (%.1f%%)\n", usageType, color.YellowString("$%.4f", costs[usageType]), share)
+	}
+
+	fmt.Fprintln(w)
+}
+
+// displayProjectBreakdown shows costs grouped by project
+func displayProjectBreakdown(w io.Writer, costs map[string]float64, totalCost float64) {
+	fmt.Fprintln(w, icon("project")+"COST BY PROJECT")
+	fmt.Fprintln(w, "─"+color.HiBlackString("─────────────────────────────────────────────────"))
+
+	if len(costs) == 0 {
+		fmt.Fprintln(w, icon("info")+"No project cost data available for this period")
+		fmt.Fprintln(w)
+		return
+	}
+
+	projects := make([]string, 0, len(costs))
+	for project := range costs {
+		projects = append(projects, project)
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		return costs[projects[i]] > costs[projects[j]]
+	})
+
+	for _, project := range projects {
+		share := float64(0)
+		if totalCost > 0 {
+			share = costs[project] / totalCost * 100
+		}
+		fmt.Fprintf(w, "   • %-30s %s (%.1f%%)\n", project, color.YellowString("$%.4f", costs[project]), share)
+	}
+
+	fmt.Fprintln(w)
+}
+
+// displayGroupedUsage fetches usage grouped by the --group-by dimensions and
+// shows it as a table with one column per dimension. Costs aren't shown since
+// the costs API can't attribute them to models or API keys.
+func displayGroupedUsage(provider providers.Provider, period string, groupBy, headers []string, opts displayOptions, debug bool) error {
+	w := opts.out
+	if w == nil {
+		w = os.Stdout
+	}
+
+	grouped, ok := provider.(groupedConsumptionProvider)
+	if !ok {
+		return fmt.Errorf("%s provider does not support --group-by", provider.GetPlatform())
+	}
+
+	if opts.orgLabel != "" {
+		fmt.Fprintf(w, icon("usage")+"OPENAI USAGE BY %s (%s) - %s\n", strings.ToUpper(strings.Join(headers, ", ")), opts.orgLabel, periodLabel(period))
+	} else {
+		fmt.Fprintf(w, icon("usage")+"OPENAI USAGE BY %s - %s\n", strings.ToUpper(strings.Join(headers, ", ")), periodLabel(period))
+	}
+	fmt.Fprintf(w, icon("time")+"Generated: %s\n\n", formatTimestamp(time.Now(), opts.dateFormat))
+
+	startTime, endTime := providers.GetPeriodTimeRange(period, opts.completeDays)
+
+	fetching := startProgress(opts.showProgress && !debug, "Fetching usage data...")
+	consumptions, err := grouped.GetConsumptionGrouped(startTime, endTime, groupBy, false, debug)
+	fetching.Done()
+	if stats, ok := provider.(clientStatsProvider); ok && debug {
+		displayClientStats(w, stats.ClientStats())
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get consumption data: %w", err)
+	}
+
+	groups := tokenwatch.AggregateUsageGroups(consumptions, groupBy)
+	if len(groups) == 0 {
+		fmt.Fprintln(w, icon("info")+"No consumption data found for the specified period.")
 		return nil
 	}
 
-	// Calculate totals and display
-	totals := calculateTotals(models)
+	columns := make([]tableColumn, 0, len(headers)+4)
+	for _, h := range headers {
+		columns = append(columns, tableColumn{header: h})
+	}
+	columns = append(columns,
+		tableColumn{header: "Input Tokens", numeric: true},
+		tableColumn{header: "Output Tokens", numeric: true},
+		tableColumn{header: "Total Tokens", numeric: true},
+		tableColumn{header: "Requests", numeric: true},
+	)
 
-	// Display summary
-	displayOpenAISummary(period, totals.TotalTokens, totals.TotalRequests, totals.TotalCost, startTime, endTime)
+	var rows [][]string
+	var total models.UsageGroup
+	for _, g := range groups {
+		row := make([]string, 0, len(columns))
+		for _, v := range g.Values {
+			row = append(row, color.YellowString("%s", v))
+		}
+		row = append(row,
+			color.GreenString("%s", opts.numbers.count(g.InputTokens)),
+			color.BlueString("%s", opts.numbers.count(g.OutputTokens)),
+			color.WhiteString("%s", opts.numbers.count(g.TotalTokens)),
+			color.MagentaString("%s", opts.numbers.count(g.Requests)),
+		)
+		rows = append(rows, row)
+
+		total.InputTokens += g.InputTokens
+		total.OutputTokens += g.OutputTokens
+		total.TotalTokens += g.TotalTokens
+		total.Requests += g.Requests
+	}
+
+	separatorRow := make([]string, len(columns))
+	for i := range separatorRow {
+		separatorRow[i] = "─"
+	}
+	rows = append(rows, separatorRow)
 
-	// Display smart recommendations
-	displaySmartRecommendations(period)
+	summaryRow := []string{color.HiWhiteString("TOTAL")}
+	for range headers[1:] {
+		summaryRow = append(summaryRow, "")
+	}
+	summaryRow = append(summaryRow,
+		color.HiGreenString("%s", opts.numbers.count(total.InputTokens)),
+		color.HiBlueString("%s", opts.numbers.count(total.OutputTokens)),
+		color.HiWhiteString("%s", opts.numbers.count(total.TotalTokens)),
+		color.HiMagentaString("%s", opts.numbers.count(total.Requests)),
+	)
+	rows = append(rows, summaryRow)
 
-	// Display table
-	displayOpenAITable(models, totals)
+	fmt.Fprintln(w, icon("table")+"USAGE BREAKDOWN")
+	table := newTable(w, columns)
+	for _, row := range rows {
+		table.AddRow(row)
+	}
+	if err := table.Render(); err != nil {
+		fmt.Fprintf(w, icon("error")+"Error: %v\n", err)
+	}
 
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, color.HiBlackString(icon("info")+"Costs can't be attributed per model or API key; use --by-project for costs by project."))
 	return nil
 }
 
+// validateSummaries warns when the consumption or pricing totals don't reconcile
+// with their components
+func validateSummaries(platform, period string, startTime, endTime time.Time, consumptions []*models.Consumption, pricingSummary *models.PricingSummary) {
+	consumptionSummary := models.NewConsumptionSummary(platform, "", period, startTime, endTime)
+	for _, c := range consumptions {
+		consumptionSummary.AddConsumption(c)
+	}
+
+	for _, err := range []error{consumptionSummary.Validate(), pricingSummary.Validate()} {
+		if err != nil {
+			utils.Warn("Summary totals don't reconcile", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+}
+
 // displayOpenAISummary shows overall statistics
-func displayOpenAISummary(period string, totalTokens, totalRequests int64, totalCost float64, startTime, endTime time.Time) {
-	days := int(endTime.Sub(startTime).Hours() / 24)
+func displayOpenAISummary(w io.Writer, r reportData) {
+	days := int(r.endTime.Sub(r.startTime).Hours() / 24)
+	if days < 1 {
+		// --since can request less than a day
+		days = 1
+	}
 
-	fmt.Println("📊 SUMMARY")
-	fmt.Println("─" + color.HiBlackString("─────────────────────────────────────────────────"))
+	fmt.Fprintln(w, icon("summary")+"SUMMARY")
+	fmt.Fprintln(w, "─"+color.HiBlackString("─────────────────────────────────────────────────"))
 
-	fmt.Printf("📅 Period: %s to %s (%d days)\n",
-		startTime.Format("2006-01-02"),
-		endTime.Format("2006-01-02"),
+	fmt.Fprintf(w, icon("period")+"Period: %s to %s (%d days)\n",
+		formatTimestamp(r.startTime, r.dateFormat),
+		formatTimestamp(r.endTime, r.dateFormat),
 		days)
 
-	fmt.Printf("📈 Daily Averages: %s tokens, %s requests\n",
-		color.CyanString("%.1f", float64(totalTokens)/float64(days)),
-		color.CyanString("%.1f", float64(totalRequests)/float64(days)))
+	fmt.Fprintf(w, icon("average")+"Daily Averages: %s tokens, %s requests\n",
+		color.CyanString("%.1f", float64(r.totals.TotalTokens)/float64(days)),
+		color.CyanString("%.1f", float64(r.totals.TotalRequests)/float64(days)))
 
-	if totalCost > 0 {
-		fmt.Printf("💰 Daily Cost Average: %s\n",
-			color.YellowString("$%.4f", totalCost/float64(days)))
-	} else {
-		if period == "30d" {
-			fmt.Printf("💰 Cost Data: %s\n", color.YellowString("Not available for this period"))
-		} else {
-			fmt.Printf("💰 Cost Data: %s\n", color.GreenString("Free Tier"))
-		}
+	switch r.costStatus {
+	case models.CostStatusBilled:
+		fmt.Fprintf(w, icon("cost")+"Daily Cost Average: %s\n",
+			color.YellowString("$%.4f", r.totals.TotalCost/float64(days)))
+	case models.CostStatusUnavailable:
+		fmt.Fprintf(w, icon("cost")+"Cost Data: %s\n", color.YellowString("Not available for this period"))
+	default:
+		fmt.Fprintf(w, icon("cost")+"Cost Data: %s\n", color.GreenString("Free Tier"))
 	}
 
-	fmt.Println()
+	// Costs are published later than usage, so recent days may show tokens without cost
+	if r.costLag > 1 {
+		fmt.Fprintf(w, icon("wait")+"Note: %s\n", color.HiBlackString("cost data is %d days behind usage - recent days may show tokens but no cost yet", r.costLag))
+	}
+
+	// Costs are only available per day, so say so rather than silently pairing
+	// finer-grained usage with daily costs
+	if r.usageBucket != r.costBucket {
+		fmt.Fprintf(w, icon("info")+"Note: %s\n", color.HiBlackString("usage is bucketed by %s but costs are only available per %s", r.usageBucket, r.costBucket))
+	}
+
+	// Make refunds and adjustments visible instead of silently lowering spend
+	if credits := r.pricing.Credits(); credits < 0 {
+		fmt.Fprintf(w, icon("billing")+"Gross Cost: %s | Credits: %s | Net Cost: %s\n",
+			color.YellowString("$%.4f", r.pricing.GrossCost()),
+			color.GreenString("-$%.4f", -credits),
+			color.HiYellowString("$%.4f", r.pricing.TotalCost))
+	}
+
+	fmt.Fprintln(w)
 }
 
 // displaySmartRecommendations provides smart recommendations based on the selected time period
-func displaySmartRecommendations(period string) {
-	fmt.Println("💡 SMART RECOMMENDATIONS")
-	fmt.Println("─" + color.HiBlackString("─────────────────────────────────────────────────"))
+func displaySmartRecommendations(w io.Writer, period string) {
+	fmt.Fprintln(w, icon("tip")+"SMART RECOMMENDATIONS")
+	fmt.Fprintln(w, "─"+color.HiBlackString("─────────────────────────────────────────────────"))
 
 	switch period {
 	case "1d":
-		fmt.Println("📊 1-day period is perfect for:")
-		fmt.Println("   • Recent activity monitoring")
-		fmt.Println("   • Real-time usage tracking")
-		fmt.Println("   • Immediate cost calculations")
-		fmt.Println("   • Debugging current API calls")
-		fmt.Println()
-		fmt.Println("🔄 For historical analysis, try: --period 7d")
+		fmt.Fprintln(w, icon("summary")+"1-day period is perfect for:")
+		fmt.Fprintln(w, "   • Recent activity monitoring")
+		fmt.Fprintln(w, "   • Real-time usage tracking")
+		fmt.Fprintln(w, "   • Immediate cost calculations")
+		fmt.Fprintln(w, "   • Debugging current API calls")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, icon("refresh")+"For historical analysis, try: --period 7d")
 
 	case "7d":
-		fmt.Println("📊 7-day period is ideal for:")
-		fmt.Println("   • Weekly usage patterns")
-		fmt.Println("   • Historical cost analysis")
-		fmt.Println("   • Model performance comparison")
-		fmt.Println("   • Budget planning")
-		fmt.Println()
-		fmt.Println("🔄 For recent activity, try: --period 1d")
+		fmt.Fprintln(w, icon("summary")+"7-day period is ideal for:")
+		fmt.Fprintln(w, "   • Weekly usage patterns")
+		fmt.Fprintln(w, "   • Historical cost analysis")
+		fmt.Fprintln(w, "   • Model performance comparison")
+		fmt.Fprintln(w, "   • Budget planning")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, icon("refresh")+"For recent activity, try: --period 1d")
 
 	case "30d":
-		fmt.Println("📊 30-day period may have limited data:")
-		fmt.Println("   • OpenAI API data availability varies")
-		fmt.Println("   • Some periods may return empty results")
-		fmt.Println("   • Consider using 7d for reliable data")
-		fmt.Println()
-		fmt.Println("🔄 For best results, try: --period 7d")
+		fmt.Fprintln(w, icon("summary")+"30-day period may have limited data:")
+		fmt.Fprintln(w, "   • OpenAI API data availability varies")
+		fmt.Fprintln(w, "   • Some periods may return empty results")
+		fmt.Fprintln(w, "   • Consider using 7d for reliable data")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, icon("refresh")+"For best results, try: --period 7d")
+
+	case "90d":
+		fmt.Fprintln(w, icon("summary")+"90-day period is useful for quarterly reviews:")
+		fmt.Fprintln(w, "   • More pages are fetched, so loading takes longer")
+		fmt.Fprintln(w, "   • Cost data for the most recent days may still be missing")
+		fmt.Fprintln(w, "   • Compare against the 30d view to spot trend changes")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, icon("refresh")+"For a faster, month-level view, try: --period 30d")
+
+	case "1y":
+		fmt.Fprintln(w, icon("summary")+"1-year period covers long-term spend:")
+		fmt.Fprintln(w, "   • Many pages are fetched, so expect a slow first load")
+		fmt.Fprintln(w, "   • If the fetch limit is reached, totals are partial and a warning is shown")
+		fmt.Fprintln(w, "   • Use 'tokenwatch export' to keep the data for offline analysis")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, icon("refresh")+"For quarterly detail, try: --period 90d")
+
+	case "all":
+		fmt.Fprintln(w, icon("summary")+"All-time period goes back as far as OpenAI keeps data:")
+		fmt.Fprintln(w, "   • This is the slowest report and the most likely to hit the fetch limit")
+		fmt.Fprintln(w, "   • Check for a partial-data warning before relying on the totals")
+		fmt.Fprintln(w, "   • Run with --strict in scripts to fail on partial data")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, icon("refresh")+"For reliable recent totals, try: --period 30d")
+
+	case "q1", "q2", "q3", "q4", providers.PeriodLastQuarter:
+		fmt.Fprintln(w, icon("summary")+"Calendar quarters line up with invoices and reporting:")
+		fmt.Fprintln(w, "   • The range runs between UTC quarter boundaries")
+		fmt.Fprintln(w, "   • The current quarter ends now, so it is still growing")
+		fmt.Fprintln(w, "   • Cost data for the last days of a quarter may arrive late")
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, icon("refresh")+"For a rolling window, try: --period 90d")
 
 	default:
-		fmt.Println("📊 For optimal results:")
-		fmt.Println("   • Use --period 1d for recent activity")
-		fmt.Println("   • Use --period 7d for historical data")
-		fmt.Println("   • 30d period may have data limitations")
+		fmt.Fprintln(w, icon("summary")+"For optimal results:")
+		fmt.Fprintln(w, "   • Use --period 1d for recent activity")
+		fmt.Fprintln(w, "   • Use --period 7d for historical data")
+		fmt.Fprintln(w, "   • Longer periods (30d and up) load slower and may have data limitations")
+	}
+
+	fmt.Fprintln(w)
+}
+
+// changedColor highlights cells that changed since the previous watch refresh
+var changedColor = color.New(color.FgHiGreen, color.Bold)
+
+// cell formats a table cell with its usual color, or the highlight color when it changed
+func cell(changed bool, sprintf func(string, ...interface{}) string, format string, a ...interface{}) string {
+	if changed {
+		return changedColor.Sprintf(format, a...)
 	}
+	return sprintf(format, a...)
+}
+
+// tableDropOrder lists the model table columns hidden on narrow terminals after
+// the last column, $/1K (or $/1M) Tokens: Cached, Requests, Input Tokens,
+// Output Tokens. Model, Total Tokens and Cost always stay.
+var tableDropOrder = []int{2, 5, 1, 3}
 
-	fmt.Println()
+// hasOtherCost reports whether any model has cost that is neither input nor
+// output, ignoring rounding residue below the displayed precision
+func hasOtherCost(stats []models.ModelStats) bool {
+	for _, m := range stats {
+		if math.Abs(m.OtherCost()) >= 0.00005 {
+			return true
+		}
+	}
+	return false
 }
 
-// displayOpenAITable shows detailed model breakdown
-func displayOpenAITable(models []ModelStats, totals TotalStats) {
-	fmt.Println("📋 MODEL BREAKDOWN")
+// displayOpenAITable shows detailed model breakdown, highlighting any cells listed in changes.
+// When width is set, lower-priority columns are hidden until the table fits.
+// With the input-output cost breakdown, input and output cost columns (and an
+// other column when needed) are shown before the total cost, which they sum to.
+// totals are uncounted; the TOTAL row's token column shows the metric selected by count.
+// Counts and costs are formatted with numbers.
+func displayOpenAITable(w io.Writer, stats []models.ModelStats, totals models.TotalStats, changes map[string]modelChanges, width int, count, costUnit, costBreakdown string, numbers numberFormat) {
+	fmt.Fprintln(w, icon("table")+"MODEL BREAKDOWN")
 
-	table := tablewriter.NewWriter(os.Stdout)
-	table.Header("Model", "Input Tokens", "Output Tokens", "Total Tokens", "Requests", "Cost", "$/1K Tokens")
+	split := costBreakdown == costBreakdownInputOutput
+	showOther := split && hasOtherCost(stats)
+
+	columns := []tableColumn{
+		{header: "Model"},
+		{header: "Input Tokens", numeric: true},
+		{header: "Cached", numeric: true},
+		{header: "Output Tokens", numeric: true},
+		{header: countHeader(count), numeric: true},
+		{header: "Requests", numeric: true},
+	}
+	if split {
+		columns = append(columns, tableColumn{header: "Input Cost", numeric: true}, tableColumn{header: "Output Cost", numeric: true})
+		if showOther {
+			columns = append(columns, tableColumn{header: "Other Cost", numeric: true})
+		}
+	}
+	columns = append(columns,
+		tableColumn{header: "Cost", numeric: true},
+		tableColumn{header: costUnitHeader(costUnit), numeric: true},
+	)
+	header := columnHeaders(columns)
+
+	// splitCells returns the split cost cells, or none in the total view
+	splitCells := func(input, output, other float64, sprintf func(string, ...interface{}) string) []string {
+		if !split {
+			return nil
+		}
+		cells := []string{sprintf("%s", numbers.cost(input, 4)), sprintf("%s", numbers.cost(output, 4))}
+		if showOther {
+			cells = append(cells, sprintf("%s", numbers.cost(other, 4)))
+		}
+		return cells
+	}
 
 	// Add rows
 	var rows [][]string
 
-	for _, m := range models {
-		costPer1K := float64(0)
-		if m.TotalTokens > 0 && m.Cost > 0 {
-			costPer1K = (m.Cost / float64(m.TotalTokens)) * 1000
+	// --count replaces TotalTokens with the counted metric, so the
+	// cost-efficiency column is computed from the real total instead
+	for _, m := range stats {
+		changed := changes[m.Model]
+		costColor := color.CyanString
+		if changed.Cost {
+			costColor = changedColor.Sprintf
 		}
-
 		row := []string{
-			color.YellowString(m.Model),
-			color.GreenString("%d", m.InputTokens),
-			color.BlueString("%d", m.OutputTokens),
-			color.WhiteString("%d", m.TotalTokens),
-			color.MagentaString("%d", m.Requests),
-			color.CyanString("$%.4f", m.Cost),
-			color.HiBlackString("$%.4f", costPer1K),
+			cell(changed.any(), color.YellowString, "%s", m.Model),
+			cell(changed.InputTokens, color.GreenString, "%s", numbers.count(m.InputTokens)),
+			color.HiBlackString("%s", numbers.count(m.CachedTokens)),
+			cell(changed.OutputTokens, color.BlueString, "%s", numbers.count(m.OutputTokens)),
+			cell(changed.TotalTokens, color.WhiteString, "%s", numbers.count(m.TotalTokens)),
+			cell(changed.Requests, color.MagentaString, "%s", numbers.count(m.Requests)),
 		}
+		row = append(row, splitCells(m.InputCost, m.OutputCost, m.OtherCost(), costColor)...)
+		row = append(row,
+			cell(changed.Cost, color.CyanString, "%s", numbers.cost(m.Cost, 4)),
+			color.HiBlackString("%s", numbers.cost(costPerTokens(m.Cost, m.InputTokens+m.OutputTokens, costUnit), 4)),
+		)
 		rows = append(rows, row)
 	}
 
 	// Add separator line above total
-	separatorRow := []string{
-		"─",
-		"─",
-		"─",
-		"─",
-		"─",
-		"─",
-		"─",
+	separatorRow := make([]string, len(columns))
+	for i := range separatorRow {
+		separatorRow[i] = "─"
 	}
 	rows = append(rows, separatorRow)
 
 	// Add summary row using pre-calculated totals
-	costPer1K := float64(0)
-	if totals.TotalTokens > 0 && totals.TotalCost > 0 {
-		costPer1K = (totals.TotalCost / float64(totals.TotalTokens)) * 1000
-	}
-
 	summaryRow := []string{
 		color.HiWhiteString("TOTAL"),
-		color.HiGreenString("%d", totals.TotalInput),
-		color.HiBlueString("%d", totals.TotalOutput),
-		color.HiWhiteString("%d", totals.TotalTokens),
-		color.HiMagentaString("%d", totals.TotalRequests),
-		color.HiYellowString("$%.4f", totals.TotalCost),
-		color.HiCyanString("$%.4f", costPer1K),
+		color.HiGreenString("%s", numbers.count(totals.TotalInput)),
+		color.HiBlackString("%s", numbers.count(totals.TotalCached)),
+		color.HiBlueString("%s", numbers.count(totals.TotalOutput)),
+		color.HiWhiteString("%s", numbers.count(countedTotal(totals, count))),
+		color.HiMagentaString("%s", numbers.count(totals.TotalRequests)),
 	}
+	summaryRow = append(summaryRow, splitCells(totals.TotalInputCost, totals.TotalOutputCost, totals.OtherCost(), color.HiYellowString)...)
+	summaryRow = append(summaryRow,
+		color.HiYellowString("%s", numbers.cost(totals.TotalCost, 4)),
+		color.HiCyanString("%s", numbers.cost(costPerTokens(totals.TotalCost, totals.TotalInput+totals.TotalOutput, costUnit), 4)),
+	)
 	rows = append(rows, summaryRow)
 
-	table.Bulk(rows)
-	table.Render()
+	// The cost-efficiency column is always last; split cost columns go only
+	// after the default drop order, since they were asked for explicitly
+	dropOrder := append([]int{len(columns) - 1}, tableDropOrder...)
+	for i := 6; i < len(columns)-2; i++ {
+		dropOrder = append(dropOrder, i)
+	}
+
+	keep := fitColumns(header, rows, width, dropOrder)
+	for i, row := range rows {
+		rows[i] = selectColumns(row, keep)
+	}
+	var kept []tableColumn
+	for _, i := range keep {
+		kept = append(kept, columns[i])
+	}
+
+	table := newTable(w, kept)
+	for _, row := range rows {
+		table.AddRow(row)
+	}
+	if err := table.Render(); err != nil {
+		fmt.Fprintf(w, icon("error")+"Error: %v\n", err)
+	}
+
+	if len(keep) < len(header) {
+		fmt.Fprintln(w, color.HiBlackString(icon("info")+"%d columns hidden to fit a %d-column terminal (use --width or --compact)", len(header)-len(keep), width))
+	}
 }
@@ -0,0 +1,1060 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fatih/color"
+
+	"tokenwatch/internal/config"
+	"tokenwatch/pkg/models"
+	"tokenwatch/pkg/providers"
+	"tokenwatch/pkg/tokenwatch"
+	"tokenwatch/pkg/utils"
+)
+
+func TestCostPerTokensUsesRealTotalWithCount(t *testing.T) {
+	saved := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = saved })
+
+	stats := []models.ModelStats{{Model: "gpt-4o", InputTokens: 1000, OutputTokens: 3000, TotalTokens: 4000, Cost: 8}}
+	for _, count := range []string{countTotal, countInput, countOutput} {
+		counted := countTokens(stats, count)
+		totals := tokenwatch.CalculateTotals(stats)
+
+		var buf bytes.Buffer
+		displayOpenAITable(&buf, counted, totals, nil, 0, count, costUnit1K, costBreakdownTotal, numberFormat{})
+
+		// $8 over 4,000 input + output tokens is $2 per 1K, whatever is counted
+		for _, line := range strings.Split(buf.String(), "\n") {
+			fields := strings.Fields(strings.NewReplacer("│", " ", "|", " ").Replace(line))
+			if len(fields) == 0 || (fields[0] != "gpt-4o" && fields[0] != "TOTAL") {
+				continue
+			}
+			if got := fields[len(fields)-1]; !strings.Contains(got, "2.0000") {
+				t.Errorf("--count %s: $/1K Tokens = %q in %q, want $2.0000", count, got, line)
+			}
+		}
+	}
+}
+
+func TestValidateFlagsWatchCombinations(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    map[string]string
+		wantErr string // empty when the combination is allowed
+	}{
+		{name: "watch table", args: map[string]string{"watch": "true"}},
+		{name: "watch json", args: map[string]string{"watch": "true", "format": "json"}, wantErr: "--format json"},
+		{name: "watch csv", args: map[string]string{"watch": "true", "format": "csv"}, wantErr: "--watch-overwrite"},
+		{name: "watch csv file", args: map[string]string{"watch": "true", "format": "csv", "output": "usage.csv"}, wantErr: "--format csv"},
+		{name: "watch push", args: map[string]string{"watch": "true", "push": "https://example.com"}, wantErr: "--push"},
+		{name: "watch strict", args: map[string]string{"watch": "true", "strict": "true"}, wantErr: "--strict"},
+		{name: "overwrite csv file", args: map[string]string{"watch": "true", "format": "csv", "output": "usage.csv", "watch-overwrite": "true"}},
+		{name: "overwrite without watch", args: map[string]string{"format": "csv", "output": "usage.csv", "watch-overwrite": "true"}, wantErr: "only applies with --watch"},
+		{name: "overwrite without output", args: map[string]string{"watch": "true", "format": "csv", "watch-overwrite": "true"}, wantErr: "--output"},
+		{name: "overwrite json", args: map[string]string{"watch": "true", "format": "json", "output": "usage.json", "watch-overwrite": "true"}, wantErr: "--format csv"},
+		// --strict fails a single run, so no watch mode accepts it, not even the CSV file loop
+		{name: "overwrite strict", args: map[string]string{"watch": "true", "format": "csv", "output": "usage.csv", "watch-overwrite": "true", "strict": "true"}, wantErr: "--strict"},
+		{name: "watch-diff strict", args: map[string]string{"watch-diff": "true", "strict": "true"}, wantErr: "--strict"},
+		{name: "overwrite with diff", args: map[string]string{"watch": "true", "watch-diff": "true", "format": "csv", "output": "usage.csv", "watch-overwrite": "true"}, wantErr: "--watch-diff"},
+		{name: "single run json", args: map[string]string{"format": "json"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := usageCmd.Flags()
+			for name, value := range tt.args {
+				if err := flags.Set(name, value); err != nil {
+					t.Fatalf("set --%s: %v", name, err)
+				}
+			}
+			t.Cleanup(func() {
+				for name := range tt.args {
+					f := flags.Lookup(name)
+					_ = f.Value.Set(f.DefValue)
+					f.Changed = false
+				}
+			})
+
+			err := validateFlags(usageCmd)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("validateFlags: unexpected error %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validateFlags error = %v, want one mentioning %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseSectionOrder(t *testing.T) {
+	tests := []struct {
+		order     string
+		noSummary bool
+		want      []string
+		wantErr   string
+	}{
+		{order: "", want: []string{sectionSummary, sectionRecommendations, sectionTable}},
+		{order: "", noSummary: true, want: []string{sectionRecommendations, sectionTable}},
+		{order: "table, Summary,types", want: []string{sectionTable, sectionSummary, sectionTypes}},
+		{order: "projects,summary", noSummary: true, want: []string{sectionProjects}},
+		{order: "table,charts", wantErr: "invalid section: charts"},
+		{order: "table,summary,table", wantErr: "duplicate section: table"},
+	}
+	for _, tt := range tests {
+		got, err := parseSectionOrder(tt.order, tt.noSummary)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("parseSectionOrder(%q) error = %v, want %q", tt.order, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("parseSectionOrder(%q, %v) = %v, %v; want %v", tt.order, tt.noSummary, got, err, tt.want)
+		}
+	}
+}
+
+func TestSectionsRenderInOrder(t *testing.T) {
+	saved := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = saved })
+
+	headers := map[string]string{
+		sectionSummary:         "SUMMARY",
+		sectionRecommendations: "SMART RECOMMENDATIONS",
+		sectionTable:           "MODEL BREAKDOWN",
+		sectionTypes:           "COST BY USAGE TYPE",
+		sectionProjects:        "COST BY PROJECT",
+	}
+	report := reportData{
+		period:    "7d",
+		startTime: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		endTime:   time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC),
+		models:    []models.ModelStats{{Model: "gpt-4o", TotalTokens: 10, Cost: 1}},
+		pricing:   &models.PricingSummary{},
+	}
+	report.totals = tokenwatch.CalculateTotals(report.models)
+
+	sections, err := parseSectionOrder("table,types,summary", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	for _, name := range sections {
+		sectionRenderers[name](&buf, report)
+	}
+	out := buf.String()
+
+	// Shown sections appear in the requested order
+	last := -1
+	for _, name := range sections {
+		i := strings.Index(out, headers[name])
+		if i < 0 {
+			t.Fatalf("section %s missing from output:\n%s", name, out)
+		}
+		if i < last {
+			t.Errorf("section %s rendered out of order:\n%s", name, out)
+		}
+		last = i
+	}
+	// Sections that weren't asked for are left out
+	for _, name := range []string{sectionRecommendations, sectionProjects} {
+		if strings.Contains(out, headers[name]) {
+			t.Errorf("section %s rendered but not requested:\n%s", name, out)
+		}
+	}
+}
+
+// stubProvider serves fixed usage and costs
+type stubProvider struct {
+	consumptions []*models.Consumption
+	pricings     []*models.Pricing
+}
+
+func (s *stubProvider) GetPlatform() string { return "openai" }
+func (s *stubProvider) IsAvailable() bool   { return true }
+func (s *stubProvider) GetConsumption(startTime, endTime time.Time, bypassCache, debug bool) ([]*models.Consumption, error) {
+	return s.consumptions, nil
+}
+func (s *stubProvider) GetPricing(startTime, endTime time.Time, bypassCache, debug bool) ([]*models.Pricing, error) {
+	return s.pricings, nil
+}
+func (s *stubProvider) GetConsumptionContext(ctx context.Context, startTime, endTime time.Time, bypassCache, debug bool) ([]*models.Consumption, error) {
+	return s.GetConsumption(startTime, endTime, bypassCache, debug)
+}
+func (s *stubProvider) GetPricingContext(ctx context.Context, startTime, endTime time.Time, bypassCache, debug bool) ([]*models.Pricing, error) {
+	return s.GetPricing(startTime, endTime, bypassCache, debug)
+}
+func (s *stubProvider) GetConsumptionSummary(period string) (*models.ConsumptionSummary, error) {
+	return nil, nil
+}
+func (s *stubProvider) GetPricingSummary(period string) (*models.PricingSummary, error) {
+	return nil, nil
+}
+
+func TestDisplayOpenAIDataWritesToOut(t *testing.T) {
+	saved := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = saved })
+
+	now := time.Now().UTC()
+	provider := &stubProvider{
+		consumptions: []*models.Consumption{{Platform: "openai", Model: "gpt-4o", InputTokens: 100, OutputTokens: 50, TotalTokens: 150, RequestCount: 2, StartTime: now.Add(-time.Hour), EndTime: now}},
+		pricings:     []*models.Pricing{{Platform: "openai", Model: "gpt-4o", Amount: 0.5, Currency: "usd", StartTime: now.Add(-time.Hour), EndTime: now}},
+	}
+
+	// Anything written to stdout instead of out would leak past the buffer
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	t.Cleanup(func() { os.Stdout = stdout })
+
+	var buf bytes.Buffer
+	opts := displayOptions{
+		out:           &buf,
+		sections:      []string{sectionSummary, sectionTable},
+		dateFormat:    "2006-01-02",
+		count:         countTotal,
+		costUnit:      costUnit1K,
+		costBreakdown: costBreakdownTotal,
+	}
+	displayErr := displayOpenAIData(provider, "7d", opts, false, false)
+	w.Close()
+	os.Stdout = stdout
+	leaked, _ := io.ReadAll(r)
+
+	if displayErr != nil {
+		t.Fatalf("displayOpenAIData: %v", displayErr)
+	}
+	if len(leaked) > 0 {
+		t.Errorf("report wrote to stdout instead of out:\n%s", leaked)
+	}
+	out := buf.String()
+	for _, want := range []string{"OPENAI USAGE", "SUMMARY", "MODEL BREAKDOWN", "gpt-4o"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestDisplayOpenAIDataCountKeepsRealTotals(t *testing.T) {
+	saved := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = saved })
+
+	now := time.Now().UTC()
+	provider := &stubProvider{
+		consumptions: []*models.Consumption{{Platform: "openai", Model: "gpt-4o", InputTokens: 140, OutputTokens: 70, TotalTokens: 210, RequestCount: 7, StartTime: now.Add(-time.Hour), EndTime: now}},
+	}
+
+	var buf bytes.Buffer
+	opts := displayOptions{
+		out:           &buf,
+		sections:      []string{sectionTable, sectionSummary},
+		dateFormat:    "2006-01-02",
+		count:         countInput,
+		costUnit:      costUnit1K,
+		costBreakdown: costBreakdownTotal,
+	}
+	if err := displayOpenAIData(provider, "7d", opts, false, false); err != nil {
+		t.Fatalf("displayOpenAIData: %v", err)
+	}
+	out := buf.String()
+
+	// 210 input + output tokens over 7 days, not the 140 counted input tokens
+	if !strings.Contains(out, "Daily Averages: 30.0 tokens") {
+		t.Errorf("daily averages should use all tokens with --count input:\n%s", out)
+	}
+	// The counted column of the TOTAL row still sums the counted metric
+	found := false
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(strings.NewReplacer("│", " ", "|", " ").Replace(line))
+		if len(fields) > 5 && fields[0] == "TOTAL" {
+			found = true
+			if fields[4] != "140" {
+				t.Errorf("TOTAL counted column = %q in %q, want 140", fields[4], line)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("no TOTAL row in:\n%s", out)
+	}
+}
+
+func TestDisplayOpenAIDataExcludeModels(t *testing.T) {
+	saved := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = saved })
+
+	now := time.Now().UTC()
+	provider := &stubProvider{
+		consumptions: []*models.Consumption{
+			{Platform: "openai", Model: "gpt-4o", InputTokens: 800, OutputTokens: 200, TotalTokens: 1000, RequestCount: 5, StartTime: now.Add(-time.Hour), EndTime: now},
+			{Platform: "openai", Model: "gpt-3.5-turbo", InputTokens: 40, OutputTokens: 10, TotalTokens: 50, RequestCount: 2, StartTime: now.Add(-time.Hour), EndTime: now},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		excludeFromTotal bool
+		wantTotal        string
+		wantNote         string
+	}{
+		{name: "still in total", wantTotal: "1050", wantNote: "still included in TOTAL"},
+		{name: "excluded from total", excludeFromTotal: true, wantTotal: "1000", wantNote: "not included in TOTAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := displayOptions{
+				out:           &buf,
+				sections:      []string{sectionTable},
+				dateFormat:    "2006-01-02",
+				count:         countTotal,
+				costUnit:      costUnit1K,
+				costBreakdown: costBreakdownTotal,
+				filter:        newModelFilter("gpt-3.5-turbo", 0, 0, tt.excludeFromTotal),
+			}
+			if err := displayOpenAIData(provider, "7d", opts, false, false); err != nil {
+				t.Fatalf("displayOpenAIData: %v", err)
+			}
+			out := buf.String()
+
+			if strings.Contains(out, "gpt-3.5-turbo") {
+				t.Errorf("excluded model is still listed:\n%s", out)
+			}
+			if !strings.Contains(out, "1 model(s) hidden") || !strings.Contains(out, tt.wantNote) {
+				t.Errorf("missing the hidden models note %q:\n%s", tt.wantNote, out)
+			}
+			found := false
+			for _, line := range strings.Split(out, "\n") {
+				fields := strings.Fields(strings.NewReplacer("│", " ", "|", " ").Replace(line))
+				if len(fields) > 5 && fields[0] == "TOTAL" {
+					found = true
+					if fields[4] != tt.wantTotal {
+						t.Errorf("TOTAL tokens = %q, want %s", fields[4], tt.wantTotal)
+					}
+				}
+			}
+			if !found {
+				t.Errorf("no TOTAL row in:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestStrictResultFailsOnWarnings(t *testing.T) {
+	utils.ResetWarnings()
+	t.Cleanup(utils.ResetWarnings)
+
+	// No warnings leaves a successful run successful, strict or not
+	if err := strictResult(true, nil); err != nil {
+		t.Errorf("strict run without warnings = %v, want nil", err)
+	}
+
+	var buf bytes.Buffer
+	warnf(&buf, "Could not fetch pricing data: %s", "timeout")
+	if !strings.Contains(buf.String(), "Warning: Could not fetch pricing data: timeout") {
+		t.Errorf("warnf printed %q", buf.String())
+	}
+
+	// One warning fails a strict run, naming the warning
+	err := strictResult(true, nil)
+	if err == nil || !strings.Contains(err.Error(), "1 warning(s)") || !strings.Contains(err.Error(), "pricing data") {
+		t.Errorf("strict run with a warning = %v, want an error listing it", err)
+	}
+	// Without --strict the warning doesn't change the outcome
+	if err := strictResult(false, nil); err != nil {
+		t.Errorf("non-strict run with a warning = %v, want nil", err)
+	}
+	// A real error is returned as is
+	failure := errors.New("fetch failed")
+	if err := strictResult(true, failure); err != failure {
+		t.Errorf("strict run with an error = %v, want %v", err, failure)
+	}
+}
+
+func TestValidateSummariesWarnsOnMismatch(t *testing.T) {
+	utils.ResetWarnings()
+	t.Cleanup(utils.ResetWarnings)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+	consumptions := []*models.Consumption{{Model: "gpt-4o", InputTokens: 100, OutputTokens: 50, TotalTokens: 150, RequestCount: 2}}
+	pricing := models.NewPricingSummary("openai", "", "7d", start, end)
+	pricing.AddPricing(models.NewPricing("openai", "gpt-4o", "gpt-4o, input", 1.5, "usd", start, end))
+
+	validateSummaries("openai", "7d", start, end, consumptions, pricing)
+	if got := utils.Warnings(); len(got) != 0 {
+		t.Errorf("reconciling totals warned: %v", got)
+	}
+
+	// Double counting shows up as totals that no longer match their components
+	consumptions[0].TotalTokens = 300
+	pricing.TotalCost = 3
+	validateSummaries("openai", "7d", start, end, consumptions, pricing)
+	if got := utils.Warnings(); len(got) != 2 || got[0] != "Summary totals don't reconcile" {
+		t.Errorf("mismatched totals warned %v, want one warning per summary", got)
+	}
+}
+
+func TestSummaryShowsCreditsSeparately(t *testing.T) {
+	saved := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = saved })
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+	pricing := models.NewPricingSummary("openai", "", "7d", start, end)
+	for _, amount := range []float64{12.5, -2.25, 0.75} {
+		pricing.AddPricing(models.NewPricing("openai", "gpt-4o", "gpt-4o, input", amount, "usd", start, end))
+	}
+	report := reportData{period: "7d", startTime: start, endTime: end, pricing: pricing, costStatus: models.CostStatusBilled}
+
+	var buf bytes.Buffer
+	displayOpenAISummary(&buf, report)
+	if want := "Gross Cost: $13.2500 | Credits: -$2.2500 | Net Cost: $11.0000"; !strings.Contains(buf.String(), want) {
+		t.Errorf("summary is missing %q:\n%s", want, buf.String())
+	}
+
+	// Without credits the split is left out
+	pricing = models.NewPricingSummary("openai", "", "7d", start, end)
+	pricing.AddPricing(models.NewPricing("openai", "gpt-4o", "gpt-4o, input", 1, "usd", start, end))
+	report.pricing = pricing
+	buf.Reset()
+	displayOpenAISummary(&buf, report)
+	if strings.Contains(buf.String(), "Credits") {
+		t.Errorf("summary without credits shows the split:\n%s", buf.String())
+	}
+}
+
+func TestSummaryUsesDateFormat(t *testing.T) {
+	saved := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = saved })
+
+	r := summaryReport()
+	r.dateFormat = "02 Jan 2006"
+	var buf bytes.Buffer
+	displayOpenAISummary(&buf, r)
+
+	if want := "Period: 01 Mar 2024 UTC to 08 Mar 2024 UTC (7 days)"; !strings.Contains(buf.String(), want) {
+		t.Errorf("summary is missing %q:\n%s", want, buf.String())
+	}
+}
+
+// summaryReport returns report data for the 7 days from 2024-03-01 with
+// matching usage and cost buckets, for testing the summary section
+func summaryReport() reportData {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+	return reportData{
+		period:      "7d",
+		startTime:   start,
+		endTime:     end,
+		dateFormat:  "2006-01-02",
+		costStatus:  models.CostStatusBilled,
+		pricing:     models.NewPricingSummary("openai", "", "7d", start, end),
+		usageBucket: "1d",
+		costBucket:  "1d",
+	}
+}
+
+func TestCompactReportFitsIn80Columns(t *testing.T) {
+	r := summaryReport()
+	r.dateFormat = "2006-01-02 15:04:05"
+	r.totals = models.TotalStats{TotalTokens: 987654321098, TotalRequests: 123456789, TotalCost: 98765.4321}
+	for i := 0; i < 5; i++ {
+		r.models = append(r.models, models.ModelStats{
+			Model:       fmt.Sprintf("ft:gpt-4o-mini-2024-07-18:a-very-long-organization-name:custom-suffix:%d", i),
+			TotalTokens: 98765432109,
+			Cost:        12345.6789,
+		})
+	}
+
+	for _, style := range []string{iconsEmoji, iconsASCII} {
+		useIconStyle(t, style)
+		var buf bytes.Buffer
+		displayCompactReport(&buf, r)
+		for _, line := range strings.Split(strings.TrimRight(buf.String(), "\n"), "\n") {
+			// Emoji take two columns but count as one rune
+			width := visibleWidth(line)
+			for _, r := range line {
+				if isEmoji(r) && r != 0xFE0F {
+					width++
+				}
+			}
+			if width > 80 {
+				t.Errorf("%s icons: line is %d columns wide: %q", style, width, line)
+			}
+		}
+	}
+}
+
+func TestDiffAgainstMarksChangedCells(t *testing.T) {
+	previous := []models.ModelStats{
+		{Model: "gpt-4o", InputTokens: 100, OutputTokens: 50, TotalTokens: 150, Requests: 2, Cost: 0.5},
+		{Model: "gpt-4o-mini", InputTokens: 10, OutputTokens: 5, TotalTokens: 15, Requests: 1, Cost: 0.01},
+		{Model: "o1", InputTokens: 7, TotalTokens: 7, Requests: 1, Cost: 0.2},
+	}
+	r := reportData{models: []models.ModelStats{
+		{Model: "gpt-4o", InputTokens: 100, OutputTokens: 80, TotalTokens: 180, Requests: 2, Cost: 0.7},
+		{Model: "gpt-4o-mini", InputTokens: 10, OutputTokens: 5, TotalTokens: 15, Requests: 1, Cost: 0.01},
+		{Model: "o3-mini", InputTokens: 1, TotalTokens: 1, Requests: 1},
+	}}
+	r.diffAgainst(previous)
+
+	all := modelChanges{InputTokens: true, OutputTokens: true, TotalTokens: true, Requests: true, Cost: true}
+	want := map[string]modelChanges{
+		"gpt-4o":  {OutputTokens: true, TotalTokens: true, Cost: true},
+		"o3-mini": all,
+	}
+	if !r.diffing {
+		t.Error("diffing is not set")
+	}
+	if !reflect.DeepEqual(r.changes, want) {
+		t.Errorf("changes = %+v, want %+v", r.changes, want)
+	}
+
+	r.diffAgainst(r.models)
+	if r.hasChanges() {
+		t.Errorf("changes = %+v against an identical reading, want none", r.changes)
+	}
+}
+
+func TestSummaryNotesCostLag(t *testing.T) {
+	saved := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = saved })
+
+	// Daily buckets: usage through Mar 7, costs only through Mar 4
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	var consumptions []*models.Consumption
+	var pricings []*models.Pricing
+	for day := 0; day < 7; day++ {
+		bucket := start.AddDate(0, 0, day)
+		consumptions = append(consumptions, &models.Consumption{Model: "gpt-4o", TotalTokens: 100, StartTime: bucket, EndTime: bucket.AddDate(0, 0, 1)})
+		if day < 4 {
+			pricings = append(pricings, &models.Pricing{Model: "gpt-4o", Amount: 0.1, StartTime: bucket, EndTime: bucket.AddDate(0, 0, 1)})
+		}
+	}
+
+	tests := []struct {
+		name     string
+		pricings []*models.Pricing
+		lag      int
+	}{
+		{name: "lagging", pricings: pricings, lag: 3},
+		{name: "one day behind", pricings: append(pricings[:4:4], &models.Pricing{StartTime: start.AddDate(0, 0, 5)}), lag: 1},
+		{name: "current", pricings: append(pricings[:4:4], &models.Pricing{StartTime: start.AddDate(0, 0, 6)}), lag: 0},
+		{name: "no costs", lag: 0},
+	}
+	for _, tt := range tests {
+		r := summaryReport()
+		r.costLag = models.CostLagDays(consumptions, tt.pricings)
+		if r.costLag != tt.lag {
+			t.Errorf("%s: CostLagDays = %d, want %d", tt.name, r.costLag, tt.lag)
+		}
+
+		var buf bytes.Buffer
+		displayOpenAISummary(&buf, r)
+		note := fmt.Sprintf("cost data is %d days behind usage", tt.lag)
+		if got := strings.Contains(buf.String(), note); got != (tt.lag > 1) {
+			t.Errorf("%s: note %q shown = %v, want %v:\n%s", tt.name, note, got, tt.lag > 1, buf.String())
+		}
+	}
+}
+
+// tableHeader returns the column headers of the first table in out, as
+// rendered: upper-cased by the table writer
+func tableHeader(out string) []string {
+	for _, line := range strings.Split(out, "\n") {
+		cells := strings.FieldsFunc(line, func(r rune) bool { return r == '│' || r == '|' })
+		if len(cells) == 0 || strings.TrimSpace(cells[0]) != "MODEL" {
+			continue
+		}
+		var header []string
+		for _, c := range cells {
+			if c = strings.TrimSpace(c); c != "" {
+				header = append(header, c)
+			}
+		}
+		return header
+	}
+	return nil
+}
+
+func TestModelTableDropsColumnsToFitWidth(t *testing.T) {
+	saved := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = saved })
+	useIconStyle(t, iconsASCII)
+
+	stats := []models.ModelStats{{Model: "gpt-4o-2024-08-06", InputTokens: 1234567, CachedTokens: 1000, OutputTokens: 765432, TotalTokens: 1999999, Requests: 4321, Cost: 12.3456}}
+	totals := tokenwatch.CalculateTotals(stats)
+
+	tests := []struct {
+		width  int
+		header []string
+		hidden int
+	}{
+		{width: 0, header: []string{"MODEL", "INPUT TOKENS", "CACHED", "OUTPUT TOKENS", "TOTAL TOKENS", "REQUESTS", "COST", "$/ 1 K TOKENS"}},
+		{width: 100, header: []string{"MODEL", "INPUT TOKENS", "CACHED", "OUTPUT TOKENS", "TOTAL TOKENS", "REQUESTS", "COST"}, hidden: 1},
+		{width: 80, header: []string{"MODEL", "INPUT TOKENS", "OUTPUT TOKENS", "TOTAL TOKENS", "COST"}, hidden: 3},
+		{width: 40, header: []string{"MODEL", "TOTAL TOKENS", "COST"}, hidden: 5},
+		{width: 10, header: []string{"MODEL", "TOTAL TOKENS", "COST"}, hidden: 5},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		displayOpenAITable(&buf, stats, totals, nil, tt.width, countTotal, costUnit1K, costBreakdownTotal, numberFormat{})
+		out := buf.String()
+
+		if got := tableHeader(out); !reflect.DeepEqual(got, tt.header) {
+			t.Errorf("width %d: columns = %q, want %q\n%s", tt.width, got, tt.header, out)
+		}
+		hint := fmt.Sprintf("%d columns hidden to fit a %d-column terminal", tt.hidden, tt.width)
+		if got := strings.Contains(out, hint); got != (tt.hidden > 0) {
+			t.Errorf("width %d: hint %q shown = %v, want %v\n%s", tt.width, hint, got, tt.hidden > 0, out)
+		}
+	}
+}
+
+func TestWatchStatus(t *testing.T) {
+	tests := []struct {
+		elapsed, remaining time.Duration
+		want               string
+	}{
+		{elapsed: 0, remaining: 30 * time.Second, want: "Last updated just now, next refresh in 30s"},
+		{elapsed: 900 * time.Millisecond, remaining: 29100 * time.Millisecond, want: "Last updated just now, next refresh in 30s"},
+		{elapsed: 5 * time.Second, remaining: 25 * time.Second, want: "Last updated 5s ago, next refresh in 25s"},
+		{elapsed: 29500 * time.Millisecond, remaining: 500 * time.Millisecond, want: "Last updated 29s ago, next refresh in 1s"},
+		{elapsed: 5 * time.Minute, remaining: 0, want: "Last updated 300s ago, next refresh in 0s"},
+		{elapsed: 31 * time.Second, remaining: -time.Second, want: "Last updated 31s ago, next refresh in 0s"},
+	}
+	for _, tt := range tests {
+		if got := watchStatus(tt.elapsed, tt.remaining); got != tt.want {
+			t.Errorf("watchStatus(%v, %v) = %q, want %q", tt.elapsed, tt.remaining, got, tt.want)
+		}
+	}
+}
+
+func TestReportHeaderShowsOrgLabel(t *testing.T) {
+	saved := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = saved })
+	useIconStyle(t, iconsNone)
+
+	tests := []struct {
+		label, orgID string
+		want         string
+	}{
+		{label: "Production", orgID: "org-abcdefghijklmnop", want: "OPENAI USAGE (Production) - Last 7d"},
+		{label: "  ", orgID: "org-abcdefghijklmnop", want: "OPENAI USAGE (org-abcdefgh…) - Last 7d"},
+		{orgID: "org-short", want: "OPENAI USAGE (org-short) - Last 7d"},
+		{want: "OPENAI USAGE - Last 7d"},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		opts := displayOptions{
+			out:           &buf,
+			dateFormat:    "2006-01-02",
+			count:         countTotal,
+			costUnit:      costUnit1K,
+			costBreakdown: costBreakdownTotal,
+			orgLabel:      orgDisplayName(tt.label, tt.orgID),
+		}
+		if err := displayOpenAIData(&stubProvider{}, "7d", opts, false, false); err != nil {
+			t.Fatalf("displayOpenAIData: %v", err)
+		}
+		if header, _, _ := strings.Cut(buf.String(), "\n"); header != tt.want {
+			t.Errorf("label %q, org %q: header = %q, want %q", tt.label, tt.orgID, header, tt.want)
+		}
+	}
+}
+
+func TestModelTableRightAlignsGroupedNumbers(t *testing.T) {
+	// Keep the color codes: alignment must not be thrown off by them
+	saved := color.NoColor
+	color.NoColor = false
+	t.Cleanup(func() { color.NoColor = saved })
+
+	stats := []models.ModelStats{
+		{Model: "gpt-4o", InputTokens: 1234567, CachedTokens: 1000, OutputTokens: 98765, TotalTokens: 1333332, Requests: 4321, Cost: 12345.6789},
+		{Model: "o1", InputTokens: 12, OutputTokens: 3, TotalTokens: 15, Requests: 1, Cost: 0.5},
+	}
+	totals := tokenwatch.CalculateTotals(stats)
+
+	var buf bytes.Buffer
+	displayOpenAITable(&buf, stats, totals, nil, 0, countTotal, costUnit1K, costBreakdownTotal, numberFormat{grouping: true})
+
+	rows := make(map[string][]string)
+	for _, line := range strings.Split(buf.String(), "\n") {
+		line = ansiPattern.ReplaceAllString(line, "")
+		cells := strings.Split(line, "│")
+		if len(cells) < 3 {
+			continue
+		}
+		cells = cells[1 : len(cells)-1]
+		if name := strings.TrimSpace(cells[0]); name != "" && name != "─" {
+			rows[name] = cells
+		}
+	}
+
+	want := map[string][]string{
+		"gpt-4o": {"1,234,567", "1,000", "98,765", "1,333,332", "4,321", "$12,345.6789", "$9.2593"},
+		"o1":     {"12", "0", "3", "15", "1", "$0.5000", "$33.3333"},
+		"TOTAL":  {"1,234,579", "1,000", "98,768", "1,333,347", "4,322", "$12,346.1789", "$9.2595"},
+	}
+	for name, values := range want {
+		cells, ok := rows[name]
+		if !ok || len(cells) != len(values)+1 {
+			t.Errorf("%s row = %q, want %d columns\n%s", name, cells, len(values)+1, buf.String())
+			continue
+		}
+		if !strings.HasPrefix(cells[0], " "+name) {
+			t.Errorf("%s: model cell %q is not left-aligned", name, cells[0])
+		}
+		for i, value := range values {
+			cell := cells[i+1]
+			if strings.TrimSpace(cell) != value {
+				t.Errorf("%s: column %d = %q, want %q", name, i+1, strings.TrimSpace(cell), value)
+			} else if !strings.HasSuffix(cell, value+" ") {
+				t.Errorf("%s: column %d cell %q is not right-aligned", name, i+1, cell)
+			}
+		}
+	}
+}
+
+func TestSummaryNotesMixedBucketWidths(t *testing.T) {
+	saved := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = saved })
+
+	tests := []struct {
+		usageBucket string
+		want        bool
+	}{
+		{usageBucket: "1h", want: true},
+		{usageBucket: "1m", want: true},
+		{usageBucket: "1d"},
+	}
+	for _, tt := range tests {
+		r := summaryReport()
+		r.usageBucket = tt.usageBucket
+
+		var buf bytes.Buffer
+		displayOpenAISummary(&buf, r)
+		note := fmt.Sprintf("usage is bucketed by %s but costs are only available per 1d", tt.usageBucket)
+		if got := strings.Contains(buf.String(), note); got != tt.want {
+			t.Errorf("usage bucket %s: note %q shown = %v, want %v:\n%s", tt.usageBucket, note, got, tt.want, buf.String())
+		}
+	}
+}
+
+func TestCostUnit1MIs1000Times1K(t *testing.T) {
+	saved := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = saved })
+
+	stats := []models.ModelStats{
+		{Model: "gpt-4o-mini", InputTokens: 1234567, OutputTokens: 89012, TotalTokens: 1323579, Cost: 0.2386},
+		{Model: "o1", InputTokens: 3000, OutputTokens: 7000, TotalTokens: 10000, Cost: 0.465},
+	}
+	for _, m := range stats {
+		tokens := m.InputTokens + m.OutputTokens
+		perK := costPerTokens(m.Cost, tokens, costUnit1K)
+		perM := costPerTokens(m.Cost, tokens, costUnit1M)
+		if perK <= 0 || math.Abs(perM-1000*perK) > 1e-12*perM {
+			t.Errorf("%s: $/1M = %v, want 1000 x $/1K = %v", m.Model, perM, 1000*perK)
+		}
+	}
+
+	// The rendered column follows the unit, header included
+	totals := tokenwatch.CalculateTotals(stats)
+	last := map[string]map[string]string{}
+	for _, unit := range []string{costUnit1K, costUnit1M} {
+		var buf bytes.Buffer
+		displayOpenAITable(&buf, stats, totals, nil, 0, countTotal, unit, costBreakdownTotal, numberFormat{})
+		if header := strings.ToUpper(costUnitHeader(unit)); !strings.Contains(strings.ReplaceAll(buf.String(), " ", ""), strings.ReplaceAll(header, " ", "")) {
+			t.Errorf("%s: table has no %q column:\n%s", unit, header, buf.String())
+		}
+		last[unit] = map[string]string{}
+		for _, line := range strings.Split(buf.String(), "\n") {
+			fields := strings.Fields(strings.NewReplacer("│", " ", "|", " ").Replace(line))
+			if len(fields) > 1 {
+				last[unit][fields[0]] = fields[len(fields)-1]
+			}
+		}
+	}
+	want := map[string][2]string{
+		"o1":    {"$0.0465", "$46.5000"},
+		"TOTAL": {"$0.0005", "$0.5276"}, // too small to read per 1K
+	}
+	for model, w := range want {
+		if got := [2]string{last[costUnit1K][model], last[costUnit1M][model]}; got != w {
+			t.Errorf("%s: $/1K, $/1M = %v, want %v", model, got, w)
+		}
+	}
+}
+
+func TestGetProviderUsesPlatformRateLimit(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv(providers.FixturesEnv, "")
+	saved := config.SystemConfigPath
+	config.SystemConfigPath = filepath.Join(home, "system", "config.yaml")
+	t.Cleanup(func() { config.SystemConfigPath = saved })
+
+	path := config.UserConfigPath()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	content := "api_keys:\n  openai: sk-test\nproviders:\n  openai:\n    rate_limit_rps: 7\n    burst: 3\n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Init(); err != nil {
+		t.Fatalf("config.Init: %v", err)
+	}
+
+	provider, ok := getProvider("openai").(*providers.OpenAIProvider)
+	if !ok {
+		t.Fatal("getProvider(openai) did not return an OpenAI provider")
+	}
+	// The limiter is shared by every client for the host
+	t.Cleanup(func() { provider.SetRateLimit(providers.DefaultOpenAIRateLimit, providers.DefaultOpenAIBurst) })
+
+	if rps, burst := provider.RateLimit(); rps != 7 || burst != 3 {
+		t.Errorf("rate limit = %v rps, burst %d; want 7 rps, burst 3 from providers.openai", rps, burst)
+	}
+}
+
+func TestSummaryDatesShowZone(t *testing.T) {
+	saved := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = saved })
+
+	r := summaryReport()
+	var buf bytes.Buffer
+	displayOpenAISummary(&buf, r)
+	if want := "Period: 2024-03-01 UTC to 2024-03-08 UTC"; !strings.Contains(buf.String(), want) {
+		t.Errorf("summary has no %q:\n%s", want, buf.String())
+	}
+
+	buf.Reset()
+	displayCompactReport(&buf, r)
+	if want := "2024-03-01 UTC → 2024-03-08 UTC"; !strings.Contains(buf.String(), want) {
+		t.Errorf("compact report has no %q:\n%s", want, buf.String())
+	}
+
+	// A zone in the layout isn't repeated, and other zones keep their own label
+	est := time.FixedZone("EST", -5*3600)
+	tests := []struct {
+		t      time.Time
+		layout string
+		want   string
+	}{
+		{r.startTime, "2006-01-02 15:04", "2024-03-01 00:00 UTC"},
+		{r.startTime.In(est), "2006-01-02 15:04", "2024-02-29 19:00 EST"},
+		{r.startTime, time.RFC1123, "Fri, 01 Mar 2024 00:00:00 UTC"},
+		{r.startTime.In(est), time.RFC3339, "2024-02-29T19:00:00-05:00"},
+	}
+	for _, tt := range tests {
+		if got := formatTimestamp(tt.t, tt.layout); got != tt.want {
+			t.Errorf("formatTimestamp(%v, %q) = %q, want %q", tt.t, tt.layout, got, tt.want)
+		}
+	}
+}
+
+func TestComputeRate(t *testing.T) {
+	previous := models.TotalStats{TotalTokens: 10000, TotalCost: 1.50}
+	current := models.TotalStats{TotalTokens: 16000, TotalCost: 1.75}
+
+	rate := computeRate(previous, current, 5*time.Minute)
+	if rate.TokensPerMinute != 1200 {
+		t.Errorf("TokensPerMinute = %v, want 1200", rate.TokensPerMinute)
+	}
+	if math.Abs(rate.CostPerHour-3) > 1e-9 {
+		t.Errorf("CostPerHour = %v, want 3", rate.CostPerHour)
+	}
+
+	// Usage sliding out of the window reads as no activity, not a negative rate
+	if rate := computeRate(current, previous, 5*time.Minute); rate.TokensPerMinute != 0 || rate.CostPerHour != 0 {
+		t.Errorf("falling totals gave %+v, want a zero rate", rate)
+	}
+
+	var state rateState
+	read := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	if got := state.apply(previous, read); got != nil {
+		t.Errorf("first reading gave rate %+v, want nil", got)
+	}
+	got := state.apply(current, read.Add(5*time.Minute))
+	if got == nil || got.Interval != 5*time.Minute || got.TokensPerMinute != 1200 {
+		t.Errorf("second reading gave rate %+v, want 1200 tokens/min over 5m", got)
+	}
+}
+
+func TestRecommendationsForLongPeriods(t *testing.T) {
+	saved := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = saved })
+
+	const generic = "For optimal results:"
+	tests := map[string]string{
+		"90d": "90-day period is useful for quarterly reviews:",
+		"1y":  "1-year period covers long-term spend:",
+		"all": "All-time period goes back as far as OpenAI keeps data:",
+		"6w":  generic,
+	}
+	for period, want := range tests {
+		var buf bytes.Buffer
+		displaySmartRecommendations(&buf, period)
+		out := buf.String()
+		if !strings.Contains(out, want) {
+			t.Errorf("%s: recommendations have no %q:\n%s", period, want, out)
+		}
+		if want != generic && strings.Contains(out, generic) {
+			t.Errorf("%s: recommendations fell back to the generic advice:\n%s", period, out)
+		}
+	}
+}
+
+func TestClientStatsReportForcedRetries(t *testing.T) {
+	// /fail/N answers 503 to its first N requests, then 200
+	var mu sync.Mutex
+	served := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		failures, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/fail/"))
+		mu.Lock()
+		served[r.URL.Path]++
+		n := served[r.URL.Path]
+		mu.Unlock()
+		if n <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	// Backoffs are 1ms then 4ms, so every second retry is capped at 2ms
+	client := utils.NewRateLimitedClientWithConfig(1000, 100, 10*time.Second, utils.RetryConfig{
+		MaxRetries:     2,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     2 * time.Millisecond,
+		BackoffFactor:  4,
+	})
+	for failures := 0; failures <= 3; failures++ {
+		req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/fail/%d", server.URL, failures), nil)
+		resp, err := client.Do(req)
+		if failures == 3 {
+			if err == nil {
+				resp.Body.Close()
+				t.Fatal("request failing every attempt succeeded")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("request failing %d times: %v", failures, err)
+		}
+		resp.Body.Close()
+	}
+
+	stats := client.Stats()
+	if stats.Requests != 9 || stats.Retries != 5 {
+		t.Errorf("Requests, Retries = %d, %d, want 9, 5", stats.Requests, stats.Retries)
+	}
+
+	var buf bytes.Buffer
+	displayClientStats(&buf, stats)
+	out := buf.String()
+	for _, want := range []string{
+		"Retries: 5 (3 requests retried, 1 failed after all attempts)",
+		"Attempts Before Success: 1: 1, 2: 1, 3: 1\n",
+		"(capped at max backoff 2 times)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("client stats have no %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestRequestStatsCountPages(t *testing.T) {
+	// Three pages of costs: costs.json, then page tokens p2 and p3
+	dir := t.TempDir()
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	pages := []struct{ file, next string }{
+		{"costs.json", "p2"},
+		{"costs.page-p2.json", "p3"},
+		{"costs.page-p3.json", ""},
+	}
+	for i, page := range pages {
+		bucket := start.AddDate(0, 0, i).Unix()
+		next := page.next
+		resp := providers.OpenAICostResponse{
+			Data:     []providers.OpenAICostBucket{{StartTime: bucket, EndTime: bucket + 86400}},
+			HasMore:  next != "",
+			NextPage: next,
+		}
+		data, err := json.Marshal(resp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, page.file), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := providers.NewOpenAIProvider("sk-test", "")
+	p.UseFixtures(dir)
+	p.SetRateLimit(1000, 1000)
+	t.Cleanup(func() { p.SetRateLimit(providers.DefaultOpenAIRateLimit, providers.DefaultOpenAIBurst) })
+
+	// The second fetch of the same range is served from the cache
+	end := start.AddDate(0, 0, 7)
+	for fetch := 0; fetch < 2; fetch++ {
+		resp, err := p.GetCosts(start, end, nil, false, false)
+		if err != nil {
+			t.Fatalf("GetCosts: %v", err)
+		}
+		if len(resp.Data) != 3 {
+			t.Fatalf("GetCosts returned %d buckets, want one from each of 3 pages", len(resp.Data))
+		}
+	}
+
+	var buf bytes.Buffer
+	displayRequestStats(&buf, p)
+	out := buf.String()
+	for _, want := range []string{
+		"HTTP Requests: 3 (0 retries)",
+		"Pages Fetched: 3\n",
+		"Cache: 1 hits, 1 misses",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("request stats have no %q:\n%s", want, out)
+		}
+	}
+}
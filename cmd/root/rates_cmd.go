@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"tokenwatch/internal/config"
+	"tokenwatch/pkg/export"
+	"tokenwatch/pkg/pricing"
+	"tokenwatch/pkg/utils"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// rateRecord is a single model rate in `rates --format json` output
+type rateRecord struct {
+	Model       string  `json:"model"`
+	InputPer1K  float64 `json:"input_per_1k"`
+	OutputPer1K float64 `json:"output_per_1k"`
+	InputPer1M  float64 `json:"input_per_1m"`
+	OutputPer1M float64 `json:"output_per_1m"`
+}
+
+var ratesCmd = &cobra.Command{
+	Use:   "rates",
+	Short: "List the local model rates used for estimates",
+	Long: `List the local rate table used by 'tokenwatch estimate'.
+
+Rates are OpenAI list prices in USD for standard (non-batch) usage. When
+pricing.source_url is set, rates fetched from it (refreshed daily) override
+the built-in ones.
+
+Examples:
+  tokenwatch rates                  # All known models
+  tokenwatch rates --model mini     # Models whose name contains "mini"
+  tokenwatch rates --format json    # Machine-readable output`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filter, _ := cmd.Flags().GetString("model")
+		format, _ := cmd.Flags().GetString("format")
+
+		format = strings.ToLower(format)
+		if format != formatTable && format != formatJSON {
+			return utils.NewValidationError("format", fmt.Sprintf("invalid format: %s. Valid formats are: %s, %s", format, formatTable, formatJSON))
+		}
+
+		rates, err := filterRates(rateTable(), filter)
+		if err != nil {
+			return err
+		}
+
+		return writeRates(os.Stdout, rates, format, jsonPretty(cmd))
+	},
+}
+
+// writeRates writes rates to w as a table or, with formatJSON, as JSON
+func writeRates(w io.Writer, rates []pricing.Rate, format string, pretty bool) error {
+	if format == formatJSON {
+		records := make([]rateRecord, 0, len(rates))
+		for _, rate := range rates {
+			records = append(records, rateRecord{
+				Model:       rate.Model,
+				InputPer1K:  rate.InputPer1M / 1000,
+				OutputPer1K: rate.OutputPer1M / 1000,
+				InputPer1M:  rate.InputPer1M,
+				OutputPer1M: rate.OutputPer1M,
+			})
+		}
+		return export.WriteJSON(w, records, pretty)
+	}
+
+	fmt.Fprintln(w, icon("rates")+"LOCAL RATE TABLE (USD)")
+
+	columns := []tableColumn{
+		{header: "Model"},
+		{header: "Input $/1K", numeric: true},
+		{header: "Output $/1K", numeric: true},
+	}
+	table := newTable(w, columns)
+	for _, rate := range rates {
+		table.AddRow([]string{
+			color.YellowString(rate.Model),
+			color.CyanString("$%.5f", rate.InputPer1M/1000),
+			color.CyanString("$%.5f", rate.OutputPer1M/1000),
+		})
+	}
+	return table.Render()
+}
+
+// rateTable returns the rates used for estimates: the built-in table, overridden
+// by pricing.source_url when set. A failed fetch falls back to the built-in table.
+func rateTable() *pricing.RateTable {
+	table, err := pricing.LoadRateTable(config.GetString("pricing.source_url"), config.GetCacheDir())
+	if err != nil {
+		utils.Warn("Could not fetch rates from pricing.source_url, using built-in rates", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return table
+}
+
+// filterRates returns the rates whose model name contains filter. When nothing
+// matches, the filter is looked up as a model name so dated snapshots resolve.
+func filterRates(table *pricing.RateTable, filter string) ([]pricing.Rate, error) {
+	filter = strings.ToLower(strings.TrimSpace(filter))
+
+	var rates []pricing.Rate
+	for _, model := range table.Models() {
+		if strings.Contains(model, filter) {
+			rate, _ := table.Lookup(model)
+			rates = append(rates, rate)
+		}
+	}
+	if len(rates) > 0 {
+		return rates, nil
+	}
+
+	rate, err := table.Lookup(filter)
+	if err != nil {
+		return nil, err
+	}
+	return []pricing.Rate{rate}, nil
+}
+
+func init() {
+	ratesCmd.Flags().StringP("model", "m", "", "Only show models whose name contains this value")
+	ratesCmd.Flags().String("format", formatTable, "Output format: table, json")
+	RootCmd.AddCommand(ratesCmd)
+}
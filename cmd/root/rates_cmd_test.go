@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+
+	"tokenwatch/pkg/pricing"
+)
+
+func TestRatesListsConfiguredRates(t *testing.T) {
+	saved := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = saved })
+
+	table := pricing.NewRateTable([]pricing.Rate{
+		{Model: "gpt-4o", InputPer1M: 2.5, OutputPer1M: 10},
+		{Model: "gpt-4o-mini", InputPer1M: 0.15, OutputPer1M: 0.6},
+		{Model: "o1", InputPer1M: 15, OutputPer1M: 60},
+	})
+
+	rates, err := filterRates(table, "")
+	if err != nil {
+		t.Fatalf("filterRates: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := writeRates(&buf, rates, formatTable, false); err != nil {
+		t.Fatalf("writeRates: %v", err)
+	}
+	var found bool
+	for _, line := range strings.Split(buf.String(), "\n") {
+		fields := strings.Fields(strings.NewReplacer("│", " ", "|", " ").Replace(line))
+		if len(fields) == 3 && fields[0] == "gpt-4o" {
+			found = true
+			if fields[1] != "$0.00250" || fields[2] != "$0.01000" {
+				t.Errorf("gpt-4o rates = %s, %s; want $0.00250, $0.01000 per 1K", fields[1], fields[2])
+			}
+		}
+	}
+	if !found {
+		t.Errorf("gpt-4o is not listed:\n%s", buf.String())
+	}
+
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{filter: "mini", want: []string{"gpt-4o-mini"}},
+		{filter: " GPT-4O ", want: []string{"gpt-4o", "gpt-4o-mini"}},
+		{filter: "gpt-4o-2024-08-06", want: []string{"gpt-4o"}}, // dated snapshot
+	}
+	for _, tt := range tests {
+		rates, err := filterRates(table, tt.filter)
+		if err != nil {
+			t.Errorf("filterRates(%q): %v", tt.filter, err)
+			continue
+		}
+		buf.Reset()
+		if err := writeRates(&buf, rates, formatJSON, false); err != nil {
+			t.Fatalf("writeRates: %v", err)
+		}
+		var records []rateRecord
+		if err := json.Unmarshal(buf.Bytes(), &records); err != nil {
+			t.Fatalf("%q: JSON output does not decode: %v", tt.filter, err)
+		}
+		var got []string
+		for _, r := range records {
+			got = append(got, r.Model)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("filterRates(%q) = %v, want %v", tt.filter, got, tt.want)
+		}
+	}
+
+	if rates, err := filterRates(table, "claude"); err == nil {
+		t.Errorf("filterRates(claude) = %v, want an error", rates)
+	}
+}
@@ -1,135 +1,293 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
-	"path/filepath"
+	"strings"
+	"time"
 
 	"tokenwatch/internal/config"
+	"tokenwatch/pkg/cache"
 	"tokenwatch/pkg/utils"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
-func runSetup() error {
-	fmt.Println("🚀 Welcome to TokenWatch Setup!")
+// validationCacheTTL is how long a successful key validation is reused
+const validationCacheTTL = 5 * time.Minute
+
+// validateKey validates apiKey for platform, reusing a recent successful
+// validation from the disk cache unless refresh is set. Only a hash of the
+// key is used in the cache key.
+func validateKey(platform, apiKey string, refresh bool) error {
+	sum := sha256.Sum256([]byte(apiKey))
+	key := fmt.Sprintf("validation:%s:%s", platform, hex.EncodeToString(sum[:]))
+	dir := config.GetCacheDir()
+
+	var valid bool
+	if !refresh && cache.Load(dir, key, time.Now(), &valid) && valid {
+		utils.Debug("Using cached API key validation", map[string]interface{}{
+			"platform": platform,
+		})
+		return nil
+	}
+
+	if err := utils.ValidatePlatformKey(platform, apiKey); err != nil {
+		return err
+	}
+
+	if err := cache.Store(dir, key, true, validationCacheTTL); err != nil {
+		utils.Debug("Failed to cache API key validation", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+	return nil
+}
+
+// setupPlatforms lists the platforms setup can store API keys for. Usage
+// reports only cover OpenAI so far; keys for the others are kept for when
+// their providers land.
+var setupPlatforms = []string{"openai", "anthropic", "grok", "cursor"}
+
+// platformNames are the display names of setupPlatforms
+var platformNames = map[string]string{
+	"openai":    "OpenAI",
+	"anthropic": "Anthropic",
+	"grok":      "Grok",
+	"cursor":    "Cursor",
+}
+
+// checkSetupPlatform returns an error unless platform is one of setupPlatforms
+func checkSetupPlatform(platform string) error {
+	if _, ok := platformNames[platform]; !ok {
+		return utils.NewValidationError("platform", fmt.Sprintf("unsupported platform: %s. Supported platforms are: %s", platform, strings.Join(setupPlatforms, ", ")))
+	}
+	return nil
+}
+
+func runSetup(refresh bool) error {
+	fmt.Println(icon("start") + "Welcome to TokenWatch Setup!")
 	fmt.Println("This will guide you through setting up your OpenAI API key for token usage monitoring.")
 	fmt.Println()
 
+	// OpenAI first, then any other platforms the user asks for
+	for platform := "openai"; platform != ""; platform = nextSetupPlatform() {
+		if err := setupPlatform(platform, refresh); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println(icon("start") + "You can now use these commands:")
+	fmt.Println("   • tokenwatch usage              - View usage and costs")
+	fmt.Println("   • tokenwatch usage --period 1d  - View last 24 hours")
+	fmt.Println("   • tokenwatch usage --period 7d  - View last 7 days")
+	fmt.Println("   • tokenwatch usage --period 30d - View last 30 days")
+	fmt.Println("   • tokenwatch usage -w -p 1d     - Watch mode for real-time monitoring")
+	fmt.Println("   • tokenwatch config check         - Verify your setup")
+
+	fmt.Println("\n" + icon("done") + "Setup complete! You can run 'tokenwatch setup' again anytime to update your API key.")
+	return nil
+}
+
+// nextSetupPlatform asks whether to set up another platform and which one,
+// returning "" once the user is done
+func nextSetupPlatform() string {
+	for utils.ConfirmPrompt("Set up another platform?", false) {
+		platform := strings.ToLower(utils.Prompt(fmt.Sprintf("Platform (%s): ", strings.Join(setupPlatforms, ", "))))
+		if err := checkSetupPlatform(platform); err != nil {
+			fmt.Println(icon("error") + err.Error())
+			continue
+		}
+		return platform
+	}
+	return ""
+}
+
+// setupPlatform prompts for, validates, and saves the API key for a single
+// platform
+func setupPlatform(platform string, refresh bool) error {
+	if err := checkSetupPlatform(platform); err != nil {
+		return err
+	}
+	name := platformNames[platform]
+
 	// Check if key already exists (try to load existing config first)
 	var existingKey string
 	if err := config.Init(); err == nil {
-		existingKey = config.GetAPIKey("openai")
+		existingKey = config.GetAPIKey(platform)
 	}
 
 	if existingKey != "" {
-		fmt.Println("🔄 Updating existing OpenAI configuration...")
+		fmt.Println(icon("refresh") + "Updating existing " + name + " configuration...")
 	} else {
-		fmt.Println("🚀 Setting up OpenAI configuration...")
+		fmt.Println(icon("start") + "Setting up " + name + " configuration...")
 	}
 
-	fmt.Println("\n⚠️  Important: OpenAI requires an Admin API key for organization-level access.")
-	fmt.Println("   This key must have 'api.usage.read' scope to access usage and costs data.")
-	fmt.Println("   Personal API keys won't work for these endpoints.")
-	fmt.Println()
-
-	// Initialize Viper and read existing config (if any)
-	v := viper.New()
-
-	// Set config path
-	configDir := filepath.Join(os.Getenv("HOME"), ".tokenwatch")
-	configPath := filepath.Join(configDir, "config.yaml")
-
-	// Create config directory if it doesn't exist
-	if err := os.MkdirAll(configDir, 0700); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
+	keyPrompt := fmt.Sprintf("Enter %s API Key: ", name)
+	if platform == "openai" {
+		fmt.Println("\n" + icon("warning") + "Important: OpenAI requires an Admin API key for organization-level access.")
+		fmt.Println("   This key must have 'api.usage.read' scope to access usage and costs data.")
+		fmt.Println("   Personal API keys won't work for these endpoints.")
+		keyPrompt = "Enter OpenAI Admin API Key (must have api.usage.read scope): "
 	}
+	fmt.Println()
 
-	// Set config file and try to read existing config
-	v.SetConfigFile(configPath)
-	if err := v.ReadInConfig(); err != nil {
-		// If no existing config, set defaults
-		v.SetDefault("settings.cache_duration", 300)
-		v.SetDefault("settings.request_timeout", 10)
-		v.SetDefault("settings.retry_attempts", 3)
-		v.SetDefault("settings.debug", false)
-		v.SetDefault("data_dir", configDir)
-		v.SetDefault("display.date_format", "2006-01-02 15:04:05")
-		v.SetDefault("display.colors", true)
-		v.SetDefault("display.table_style", "fancy")
-		v.SetDefault("display.show_progress", true)
-		v.SetDefault("output.default_format", "table")
+	// Load the existing config (if any) so other settings are preserved
+	v, err := loadSetupConfig()
+	if err != nil {
+		return err
 	}
 
 	// Prompt for API key (masked input)
-	keyPrompt := "Enter OpenAI Admin API Key (must have api.usage.read scope): "
 	apiKey := utils.PromptMasked(keyPrompt)
 	if apiKey == "" {
-		return fmt.Errorf("API key is required for OpenAI setup")
+		return fmt.Errorf("API key is required for %s setup", name)
 	}
 
 	// Validate the API key
-	fmt.Printf("🔍 Validating OpenAI API key...\n")
-	if err := utils.ValidatePlatformKey("openai", apiKey); err != nil {
-		fmt.Printf("❌ API key validation failed: %v\n", err)
+	fmt.Printf(icon("check")+"Validating %s API key...\n", name)
+	if err := validateKey(platform, apiKey, refresh); err != nil {
+		fmt.Printf(icon("error")+"API key validation failed: %v\n", err)
 
 		// Ask if user wants to continue anyway
 		if !utils.ConfirmPrompt("Do you want to save this key anyway?", false) {
 			return fmt.Errorf("setup cancelled due to invalid API key")
 		}
-		fmt.Println("⚠️  Saving unvalidated API key. You may need to update it later.")
+		fmt.Println(icon("warning") + "Saving unvalidated API key. You may need to update it later.")
 	} else {
-		fmt.Println("✅ API key validated successfully!")
+		fmt.Println(icon("ok") + "API key validated successfully!")
 	}
 
-	// Set the API key
-	v.Set("api_keys.openai", apiKey)
-
 	// Save config
-	if err := v.WriteConfigAs(configPath); err != nil {
-		return fmt.Errorf("failed to save config: %w", err)
+	configPath, err := saveSetupConfig(v, platform, apiKey, "")
+	if err != nil {
+		return err
 	}
 
 	fmt.Printf("Configuration saved to %s\n", configPath)
 	fmt.Println()
 	if existingKey != "" {
-		fmt.Println("✅ OpenAI configuration updated successfully!")
+		fmt.Println(icon("ok") + name + " configuration updated successfully!")
 	} else {
-		fmt.Println("✅ OpenAI setup complete!")
+		fmt.Println(icon("ok") + name + " setup complete!")
 	}
 	fmt.Println()
 
-	fmt.Println("🚀 You can now use these commands:")
-	fmt.Println("   • tokenwatch usage              - View usage and costs")
-	fmt.Println("   • tokenwatch usage --period 1d  - View last 24 hours")
-	fmt.Println("   • tokenwatch usage --period 7d  - View last 7 days")
-	fmt.Println("   • tokenwatch usage --period 30d - View last 30 days")
-	fmt.Println("   • tokenwatch usage -w -p 1d     - Watch mode for real-time monitoring")
-	fmt.Println("   • tokenwatch config check         - Verify your setup")
+	return nil
+}
+
+// runNonInteractiveSetup writes the config from flags without prompting
+func runNonInteractiveSetup(platform, apiKey, orgID string, validate, refresh bool) error {
+	if platform == "" {
+		return utils.NewValidationError("platform", "--platform is required with --non-interactive")
+	}
+	if err := checkSetupPlatform(platform); err != nil {
+		return err
+	}
+	if apiKey == "" {
+		return utils.NewValidationError("api-key", "--api-key is required with --non-interactive")
+	}
+
+	if validate {
+		if err := validateKey(platform, apiKey, refresh); err != nil {
+			return fmt.Errorf("API key validation failed (use --no-validate to save it anyway): %w", err)
+		}
+	}
+
+	v, err := loadSetupConfig()
+	if err != nil {
+		return err
+	}
+
+	configPath, err := saveSetupConfig(v, platform, apiKey, orgID)
+	if err != nil {
+		return err
+	}
 
-	fmt.Println("\n🎉 Setup complete! You can run 'tokenwatch setup' again anytime to update your API key.")
+	fmt.Printf(icon("ok")+"Configuration saved to %s\n", configPath)
 	return nil
 }
 
+// loadSetupConfig reads the existing config, or sets the defaults written for a new one.
+// A config still at the legacy location is read here and migrated when saved.
+func loadSetupConfig() (*viper.Viper, error) {
+	v := viper.New()
+
+	// Set config path (XDG on Linux, falling back to ~/.tokenwatch)
+	configDir := config.ConfigDir()
+
+	// Create config directory if it doesn't exist
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	v.SetConfigFile(config.ResolveConfigPath())
+	if err := v.ReadInConfig(); err != nil {
+		// If no existing config, set defaults
+		config.SetDefaults(v, configDir)
+	}
+
+	return v, nil
+}
+
+// saveSetupConfig stores the platform credentials in v and writes it to the
+// user config file, returning the path written
+func saveSetupConfig(v *viper.Viper, platform, apiKey, orgID string) (string, error) {
+	v.Set("api_keys."+platform, apiKey)
+	if orgID != "" {
+		v.Set(platform+".organization_id", orgID)
+	}
+
+	configPath := config.UserConfigPath()
+	if err := v.WriteConfigAs(configPath); err != nil {
+		return "", fmt.Errorf("failed to save config: %w", err)
+	}
+	return configPath, nil
+}
+
 var setupCmd = &cobra.Command{
 	Use:   "setup",
 	Short: "Interactive setup for OpenAI API key",
 	Long: `Interactive setup for OpenAI API key.
 	
 This command will guide you through setting up your OpenAI Admin API key for token usage monitoring.
-Currently supports: OpenAI only.
+Usage reports currently cover OpenAI only; after OpenAI, setup can also store
+API keys for Anthropic, Grok and Cursor.
 
 Examples:
   tokenwatch setup    # Start interactive setup process
+  tokenwatch setup --non-interactive --platform openai --api-key "$OPENAI_ADMIN_KEY"
+  tokenwatch setup --non-interactive --platform openai --api-key sk-... --org-id org-... --no-validate
 
 Note: OpenAI requires an Admin API key for organization-level access to usage and costs data.`,
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runSetup()
+		// Validation requests go through the same gateway headers as usage requests
+		if err := config.Init(); err == nil {
+			utils.SetValidationHeaders(config.GetStringMapString("openai.extra_headers"))
+		}
+
+		refresh, _ := cmd.Flags().GetBool("refresh")
+		if nonInteractive, _ := cmd.Flags().GetBool("non-interactive"); nonInteractive {
+			platform, _ := cmd.Flags().GetString("platform")
+			apiKey, _ := cmd.Flags().GetString("api-key")
+			orgID, _ := cmd.Flags().GetString("org-id")
+			noValidate, _ := cmd.Flags().GetBool("no-validate")
+			return runNonInteractiveSetup(platform, apiKey, orgID, !noValidate, refresh)
+		}
+		return runSetup(refresh)
 	},
 }
 
 func init() {
+	setupCmd.Flags().Bool("non-interactive", false, "Write the config from flags without prompting")
+	setupCmd.Flags().String("platform", "", "Platform to configure (openai, anthropic, grok, cursor)")
+	setupCmd.Flags().String("api-key", "", "Admin API key for the platform")
+	setupCmd.Flags().String("org-id", "", "Organization ID to send with API requests (optional)")
+	setupCmd.Flags().Bool("no-validate", false, "Save the API key without validating it")
+	setupCmd.Flags().Bool("refresh", false, "Re-validate the API key even if it was validated in the last few minutes")
 	RootCmd.AddCommand(setupCmd)
 }
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/spf13/viper"
+
+	"tokenwatch/internal/config"
+	"tokenwatch/pkg/utils"
+)
+
+func TestLoadSetupConfigUsesConfigDefaults(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	v, err := loadSetupConfig()
+	if err != nil {
+		t.Fatalf("loadSetupConfig: %v", err)
+	}
+
+	want := viper.New()
+	config.SetDefaults(want, config.ConfigDir())
+	for _, key := range want.AllKeys() {
+		if !v.IsSet(key) {
+			t.Errorf("setup config is missing the default for %s", key)
+			continue
+		}
+		if got, def := v.Get(key), want.Get(key); got != def {
+			t.Errorf("setup default %s = %v, want %v", key, got, def)
+		}
+	}
+}
+
+func TestNonInteractiveSetupWritesConfig(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	saved := config.SystemConfigPath
+	config.SystemConfigPath = filepath.Join(home, "system", "config.yaml")
+	t.Cleanup(func() { config.SystemConfigPath = saved })
+
+	flags := map[string]string{
+		"non-interactive": "true",
+		"platform":        "openai",
+		"api-key":         "sk-admin-test",
+		"org-id":          "org-test",
+		"no-validate":     "true",
+	}
+	for name, value := range flags {
+		if err := setupCmd.Flags().Set(name, value); err != nil {
+			t.Fatalf("set --%s: %v", name, err)
+		}
+	}
+	t.Cleanup(func() {
+		for name := range flags {
+			f := setupCmd.Flags().Lookup(name)
+			f.Value.Set(f.DefValue)
+			f.Changed = false
+		}
+	})
+
+	if err := setupCmd.RunE(setupCmd, nil); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+
+	path := config.UserConfigPath()
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("setup wrote no config file: %v", err)
+	}
+	written := viper.New()
+	written.SetConfigFile(path)
+	if err := written.ReadInConfig(); err != nil {
+		t.Fatalf("config file %s doesn't parse: %v", path, err)
+	}
+	if got := written.GetString("api_keys.openai"); got != "sk-admin-test" {
+		t.Errorf("api_keys.openai = %q, want sk-admin-test", got)
+	}
+	if got := written.GetString("openai.organization_id"); got != "org-test" {
+		t.Errorf("openai.organization_id = %q, want org-test", got)
+	}
+	// A new config is written with the defaults filled in
+	if !written.IsSet("settings.cache_duration") {
+		t.Error("config file is missing the defaults")
+	}
+}
+
+func TestValidateKeyReusesCachedResultWithinTTL(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	saved := config.SystemConfigPath
+	config.SystemConfigPath = filepath.Join(home, "system", "config.yaml")
+	t.Cleanup(func() { config.SystemConfigPath = saved })
+	if err := config.Init(); err != nil {
+		t.Fatalf("config.Init: %v", err)
+	}
+
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/models" {
+			t.Errorf("validation requested %s, want /models", r.URL.Path)
+		}
+	}))
+	t.Cleanup(server.Close)
+	utils.SetValidationBaseURL(server.URL)
+	t.Cleanup(func() { utils.SetValidationBaseURL("https://api.openai.com/v1") })
+
+	steps := []struct {
+		name     string
+		key      string
+		refresh  bool
+		requests int64
+	}{
+		{name: "first validation", key: "sk-admin-test", requests: 1},
+		{name: "within the TTL", key: "sk-admin-test", requests: 1},
+		{name: "--refresh", key: "sk-admin-test", refresh: true, requests: 2},
+		{name: "another key", key: "sk-admin-other", requests: 3},
+	}
+	for _, step := range steps {
+		if err := validateKey("openai", step.key, step.refresh); err != nil {
+			t.Fatalf("%s: validateKey: %v", step.name, err)
+		}
+		if got := requests.Load(); got != step.requests {
+			t.Errorf("%s: %d validation requests so far, want %d", step.name, got, step.requests)
+		}
+	}
+}
+
+func TestInteractiveSetupWritesTwoPlatforms(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("OPENAI_API_KEY_FILE", filepath.Join(home, "missing"))
+	saved := config.SystemConfigPath
+	config.SystemConfigPath = filepath.Join(home, "system", "config.yaml")
+	t.Cleanup(func() { config.SystemConfigPath = saved })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(server.Close)
+	utils.SetValidationBaseURL(server.URL)
+	t.Cleanup(func() { utils.SetValidationBaseURL("https://api.openai.com/v1") })
+
+	// OpenAI key, another platform: an unknown one, then Anthropic and its key, then done
+	utils.SetPromptInput(strings.NewReader("sk-admin-one\ny\nbard\ny\nanthropic\nsk-ant-0123456789\nn\n"))
+	t.Cleanup(func() { utils.SetPromptInput(os.Stdin) })
+
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	t.Cleanup(func() { os.Stdout = stdout })
+	setupErr := runSetup(false)
+	w.Close()
+	os.Stdout = stdout
+	out, _ := io.ReadAll(r)
+
+	if setupErr != nil {
+		t.Fatalf("runSetup: %v\n%s", setupErr, out)
+	}
+	if n := strings.Count(string(out), "Welcome to TokenWatch Setup!"); n != 1 {
+		t.Errorf("welcome banner shown %d times, want once", n)
+	}
+	if !strings.Contains(string(out), "unsupported platform: bard") {
+		t.Errorf("unknown platform was not rejected:\n%s", out)
+	}
+
+	written := viper.New()
+	written.SetConfigFile(config.UserConfigPath())
+	if err := written.ReadInConfig(); err != nil {
+		t.Fatalf("config file doesn't parse: %v", err)
+	}
+	want := map[string]string{"api_keys.openai": "sk-admin-one", "api_keys.anthropic": "sk-ant-0123456789"}
+	for key, value := range want {
+		if got := written.GetString(key); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+}
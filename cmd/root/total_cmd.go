@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"tokenwatch/internal/config"
+	"tokenwatch/pkg/export"
+	"tokenwatch/pkg/providers"
+	"tokenwatch/pkg/tokenwatch"
+	"tokenwatch/pkg/utils"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var totalCmd = &cobra.Command{
+	Use:   "total",
+	Short: "Print total tokens, requests, and cost for a period",
+	Long: `Print only the totals for a period, for scripts and quick checks.
+
+With --format json the output is a single line with a fixed schema:
+  {"platform":"openai","period":"7d","total_tokens":N,"total_requests":N,"total_cost":F,"currency":"USD"}
+
+Examples:
+  tokenwatch total                          # Last 7 days
+  tokenwatch total --period 30d --format json
+  tokenwatch total -p 1d --format json | jq .total_cost
+  tokenwatch total --from-daemon           # Read the report written by 'tokenwatch daemon'`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := config.Init(); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		period, _ := cmd.Flags().GetString("period")
+		format, _ := cmd.Flags().GetString("format")
+		debug, _ := cmd.Flags().GetBool("debug")
+		fromDaemon, _ := cmd.Flags().GetBool("from-daemon")
+
+		if err := validatePeriod(period); err != nil {
+			return err
+		}
+		if period == "" {
+			period = "7d"
+		}
+
+		format = strings.ToLower(format)
+		if format != formatTable && format != formatJSON {
+			return utils.NewValidationError("format", fmt.Sprintf("invalid format: %s. Valid formats are: %s, %s", format, formatTable, formatJSON))
+		}
+
+		var total export.TotalReport
+		if fromDaemon {
+			// Read the daemon's latest report instead of calling the API
+			var err error
+			total, err = totalFromDaemon(latestReportPath(), period, cmd.Flags().Changed("period"))
+			if err != nil {
+				return err
+			}
+		} else {
+			if config.GetAPIKey("openai") == "" {
+				return utils.NewAuthError("OpenAI not configured", "openai")
+			}
+
+			provider := getProvider("openai")
+			if provider == nil {
+				return fmt.Errorf("OpenAI provider not available")
+			}
+
+			var err error
+			total, err = fetchTotal(provider, period, debug)
+			if err != nil {
+				return err
+			}
+		}
+
+		if format == formatJSON {
+			// Always a single line so scripts can rely on the exact output
+			return export.WriteJSON(os.Stdout, total, false)
+		}
+
+		fmt.Printf(icon("cost")+"%s total for %s: %s tokens, %s requests, %s\n",
+			strings.ToUpper(total.Platform),
+			totalPeriodPhrase(total.Period),
+			color.CyanString("%d", total.TotalTokens),
+			color.CyanString("%d", total.TotalRequests),
+			color.GreenString(formatTotalCost(newNumberFormat(config.DisplaySettings()), total.TotalCost, total.Currency)))
+		return nil
+	},
+}
+
+// fetchTotal fetches the totals for period, using the provider's totals-only
+// fast path when it has one and falling back to the full per-model report
+func fetchTotal(provider providers.Provider, period string, debug bool) (export.TotalReport, error) {
+	if p, ok := provider.(totalsProvider); ok {
+		startTime, endTime := providers.GetPeriodTimeRange(period, false)
+		totals, err := p.GetTotals(startTime, endTime, false, debug)
+		if err == nil {
+			return export.TotalReport{
+				Platform:      provider.GetPlatform(),
+				Period:        period,
+				TotalTokens:   totals.TotalTokens,
+				TotalRequests: totals.TotalRequests,
+				TotalCost:     totals.TotalCost,
+				Currency:      reportCurrency(totals.Currency),
+			}, nil
+		}
+		utils.Debug("Totals fast path failed, falling back to the per-model report", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+
+	report, err := buildUsageReport(provider, period, tokenwatch.ModelFilter{}, false, false, debug)
+	if err != nil {
+		return export.TotalReport{}, err
+	}
+	return totalFromReport(report), nil
+}
+
+// totalFromDaemon reads the totals from the daemon's report at path. When
+// checkPeriod is set, a report for a period other than period is an error.
+func totalFromDaemon(path, period string, checkPeriod bool) (export.TotalReport, error) {
+	report, err := readLatestReport(path)
+	if err != nil {
+		return export.TotalReport{}, err
+	}
+	if checkPeriod && report.Period != period {
+		return export.TotalReport{}, utils.NewValidationError("period", fmt.Sprintf("the daemon reports the last %s, not %s; restart it with --period %s", report.Period, period, period))
+	}
+	return totalFromReport(report), nil
+}
+
+// totalFromReport reduces a full usage report to its totals
+func totalFromReport(report export.UsageReport) export.TotalReport {
+	return export.TotalReport{
+		Platform:      report.Platform,
+		Period:        report.Period,
+		TotalTokens:   report.Totals.TotalTokens,
+		TotalRequests: report.Totals.Requests,
+		TotalCost:     report.Totals.Cost,
+		Currency:      reportCurrency(report.Totals.Currency),
+	}
+}
+
+// defaultCurrency is reported when no cost data names a currency
+const defaultCurrency = "USD"
+
+// reportCurrency normalizes a currency code from the API, e.g. "usd" to "USD".
+// An empty code, as when there were no costs, is reported as defaultCurrency.
+func reportCurrency(currency string) string {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return defaultCurrency
+	}
+	return currency
+}
+
+// formatTotalCost formats amount like numberFormat.cost, but with the currency
+// code instead of a $ sign when the costs are not in USD
+func formatTotalCost(numbers numberFormat, amount float64, currency string) string {
+	if currency == defaultCurrency {
+		return numbers.cost(amount, 2)
+	}
+	return strings.TrimPrefix(numbers.cost(amount, 2), "$") + " " + currency
+}
+
+// totalPeriodPhrase describes period after "total for", e.g. "last 7d",
+// "last quarter" or "Q1 (this year)"
+func totalPeriodPhrase(period string) string {
+	label := periodLabel(period)
+	if strings.HasPrefix(label, "Last ") {
+		return "l" + label[1:]
+	}
+	return label
+}
+
+func init() {
+	totalCmd.Flags().StringP("period", "p", "7d", "Time period: 1d, 7d, 30d, 90d, 1y, all, q1-q4, last-quarter")
+	totalCmd.Flags().String("format", formatTable, "Output format: table, json")
+	totalCmd.Flags().BoolP("debug", "d", false, "Enable debug logging for API calls")
+	totalCmd.Flags().Bool("from-daemon", false, "Read the latest report written by 'tokenwatch daemon' instead of calling the API")
+	RootCmd.AddCommand(totalCmd)
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+	"testing"
+
+	"tokenwatch/pkg/export"
+)
+
+func TestTotalJSONSchema(t *testing.T) {
+	reports := map[string]export.UsageReport{
+		"with usage": {Platform: "openai", Period: "7d", Totals: export.UsageTotals{TotalTokens: 1500, Requests: 12, Cost: 4.25}},
+		"empty":      {Platform: "openai", Period: "1d"},
+	}
+	for name, report := range reports {
+		var buf bytes.Buffer
+		if err := export.WriteJSON(&buf, totalFromReport(report), false); err != nil {
+			t.Fatalf("%s: WriteJSON: %v", name, err)
+		}
+		if lines := strings.Count(buf.String(), "\n"); lines != 1 {
+			t.Errorf("%s: output has %d lines, want exactly 1: %q", name, lines, buf.String())
+		}
+
+		var doc map[string]interface{}
+		if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("%s: invalid JSON: %v", name, err)
+		}
+
+		// The schema is frozen: exactly these keys, even when values are zero
+		keys := make([]string, 0, len(doc))
+		for k := range doc {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		if got, want := strings.Join(keys, ","), "currency,period,platform,total_cost,total_requests,total_tokens"; got != want {
+			t.Errorf("%s: keys = %s, want %s", name, got, want)
+		}
+
+		for _, k := range []string{"platform", "period", "currency"} {
+			if _, ok := doc[k].(string); !ok {
+				t.Errorf("%s: %s = %#v, want a string", name, k, doc[k])
+			}
+		}
+		for _, k := range []string{"total_tokens", "total_requests"} {
+			if n, ok := doc[k].(float64); !ok || n != float64(int64(n)) {
+				t.Errorf("%s: %s = %#v, want an integer", name, k, doc[k])
+			}
+		}
+		if _, ok := doc["total_cost"].(float64); !ok {
+			t.Errorf("%s: total_cost = %#v, want a number", name, doc["total_cost"])
+		}
+		if doc["currency"] != "USD" {
+			t.Errorf("%s: currency = %v, want USD", name, doc["currency"])
+		}
+	}
+
+	var buf bytes.Buffer
+	export.WriteJSON(&buf, totalFromReport(reports["with usage"]), false)
+	if want := `{"platform":"openai","period":"7d","total_tokens":1500,"total_requests":12,"total_cost":4.25,"currency":"USD"}` + "\n"; buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestTotalUsesReportCurrency(t *testing.T) {
+	tests := []struct {
+		currency string
+		want     string
+	}{
+		{currency: "usd", want: "USD"},
+		{currency: "eur", want: "EUR"},
+		{currency: "", want: "USD"},
+	}
+	for _, tt := range tests {
+		report := export.UsageReport{Platform: "openai", Period: "7d", Totals: export.UsageTotals{Cost: 1, Currency: tt.currency}}
+		if got := totalFromReport(report).Currency; got != tt.want {
+			t.Errorf("currency %q: total currency = %q, want %q", tt.currency, got, tt.want)
+		}
+	}
+
+	if got := formatTotalCost(numberFormat{}, 4.5, "USD"); got != "$4.50" {
+		t.Errorf("formatTotalCost USD = %q, want $4.50", got)
+	}
+	if got := formatTotalCost(numberFormat{}, 4.5, "EUR"); got != "4.50 EUR" {
+		t.Errorf("formatTotalCost EUR = %q, want 4.50 EUR", got)
+	}
+}
+
+func TestTotalPeriodPhrase(t *testing.T) {
+	tests := map[string]string{
+		"7d":           "last 7d",
+		"last-quarter": "last quarter",
+		"q1":           "Q1 (this year)",
+	}
+	for period, want := range tests {
+		if got := totalPeriodPhrase(period); got != want {
+			t.Errorf("totalPeriodPhrase(%q) = %q, want %q", period, got, want)
+		}
+	}
+}
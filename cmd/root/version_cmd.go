@@ -2,23 +2,66 @@ package main
 
 import (
 	"fmt"
+	"io"
 
+	"tokenwatch/internal/config"
+	"tokenwatch/pkg/update"
+
+	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
 
 var versionCmd = &cobra.Command{
 	Use:   "version",
 	Short: "Print the version number of TokenWatch",
-	Long:  `Print the version number and build information of TokenWatch CLI.`,
+	Long: `Print the version number and build information of TokenWatch CLI.
+
+With --check, also ask the project's releases (update.release_url, GitHub by
+default) whether a newer version is available. The check never fails the
+command: when offline it just says the check couldn't be done.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Printf("TokenWatch %s\n", Version)
 		fmt.Printf("Built: %s\n", BuildTime)
 		fmt.Printf("Go Version: %s\n", "go1.21+")
 		fmt.Printf("Platform: OpenAI only\n")
 		fmt.Printf("Status: First functional release\n")
+
+		if check, _ := cmd.Flags().GetBool("check"); check {
+			fmt.Println()
+			checkForUpdate(cmd.OutOrStdout(), releaseURL())
+		}
 	},
 }
 
+// releaseURL returns update.release_url, or the GitHub releases API when unset
+func releaseURL() string {
+	if config.Config != nil {
+		if url := config.GetString("update.release_url"); url != "" {
+			return url
+		}
+	}
+	return update.DefaultReleaseURL
+}
+
+// checkForUpdate reports to w whether a release newer than Version is available at url
+func checkForUpdate(w io.Writer, url string) {
+	result, err := update.Check(Version, url)
+	if err != nil {
+		fmt.Fprintln(w, icon("warning")+color.YellowString("Could not check for updates: %v", err))
+		return
+	}
+
+	if result.UpdateAvailable {
+		fmt.Fprintf(w, icon("start")+"A newer version is available: %s (you have %s)\n", color.GreenString(result.Latest), result.Current)
+		if result.URL != "" {
+			fmt.Fprintf(w, "   %s\n", color.CyanString(result.URL))
+		}
+		return
+	}
+	fmt.Fprintf(w, icon("ok")+"TokenWatch is up to date (latest release: %s)\n", result.Latest)
+}
+
 func init() {
+	versionCmd.Flags().Bool("check", false, "Check whether a newer release is available")
 	RootCmd.AddCommand(versionCmd)
 }
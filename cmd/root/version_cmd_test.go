@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+// releaseServer serves a latest release document tagged tag
+func releaseServer(t *testing.T, tag string) string {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"tag_name":"` + tag + `","html_url":"https://example.com/releases/` + tag + `"}`))
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestCheckForUpdateMessages(t *testing.T) {
+	saved := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = saved })
+
+	savedVersion := Version
+	Version = "v0.2.0"
+	t.Cleanup(func() { Version = savedVersion })
+
+	tests := []struct {
+		name    string
+		tag     string
+		want    []string
+		notWant string
+	}{
+		{
+			name: "newer",
+			tag:  "v0.3.0",
+			want: []string{"A newer version is available: v0.3.0 (you have v0.2.0)", "https://example.com/releases/v0.3.0"},
+		},
+		{
+			name:    "equal",
+			tag:     "v0.2.0",
+			want:    []string{"TokenWatch is up to date (latest release: v0.2.0)"},
+			notWant: "newer version",
+		},
+		{
+			name:    "older",
+			tag:     "v0.1.9",
+			want:    []string{"TokenWatch is up to date (latest release: v0.1.9)"},
+			notWant: "newer version",
+		},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		checkForUpdate(&buf, releaseServer(t, tt.tag))
+		out := buf.String()
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s: output %q does not contain %q", tt.name, out, want)
+			}
+		}
+		if tt.notWant != "" && strings.Contains(out, tt.notWant) {
+			t.Errorf("%s: output %q should not contain %q", tt.name, out, tt.notWant)
+		}
+	}
+}
+
+func TestCheckForUpdateFailureIsAWarning(t *testing.T) {
+	saved := color.NoColor
+	color.NoColor = true
+	t.Cleanup(func() { color.NoColor = saved })
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	checkForUpdate(&buf, server.URL)
+	if !strings.Contains(buf.String(), "Could not check for updates") {
+		t.Errorf("output = %q, want the could-not-check warning", buf.String())
+	}
+}
@@ -4,59 +4,153 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 var Config *viper.Viper
 
+// configFileName is the name of the config file inside the config directory
+const configFileName = "config.yaml"
+
+// SystemConfigPath is the system-wide config file layered underneath the user config.
+// Admins can use it to set organization defaults that users inherit but can override.
+// It is a variable so tests can point it at a temporary file.
+var SystemConfigPath = "/etc/tokenwatch/config.yaml"
+
+// legacyConfigDir returns the original config directory (~/.tokenwatch)
+func legacyConfigDir() string {
+	return filepath.Join(os.Getenv("HOME"), ".tokenwatch")
+}
+
+// ConfigDir returns the directory new configuration is written to.
+// On Linux this honors $XDG_CONFIG_HOME/tokenwatch; elsewhere it is ~/.tokenwatch.
+func ConfigDir() string {
+	if runtime.GOOS == "linux" {
+		if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+			return filepath.Join(xdg, "tokenwatch")
+		}
+	}
+	return legacyConfigDir()
+}
+
+// UserConfigPath returns the file user settings are written to, in the
+// preferred location from ConfigDir
+func UserConfigPath() string {
+	return filepath.Join(ConfigDir(), configFileName)
+}
+
+// ResolveConfigPath returns the config file to read. The preferred location from
+// ConfigDir is used when it exists; otherwise an existing legacy ~/.tokenwatch
+// file is read. Writes always go to UserConfigPath, so the first write migrates
+// a legacy config (the legacy file itself is left in place).
+func ResolveConfigPath() string {
+	preferred := UserConfigPath()
+	if _, err := os.Stat(preferred); err == nil {
+		return preferred
+	}
+
+	legacy := filepath.Join(legacyConfigDir(), configFileName)
+	if legacy != preferred {
+		if _, err := os.Stat(legacy); err == nil {
+			return legacy
+		}
+	}
+
+	return preferred
+}
+
 func Init() error {
 	Config = viper.New()
 	Config.SetConfigName("config")
 	Config.SetConfigType("yaml")
 
-	// Default config path
-	configDir := filepath.Join(os.Getenv("HOME"), ".tokenwatch")
-	configPath := filepath.Join(configDir, "config.yaml")
+	// Resolve config path (XDG on Linux, falling back to ~/.tokenwatch)
+	configPath := ResolveConfigPath()
+	configDir := filepath.Dir(configPath)
 
-	// Create dir if not exists
-	if err := os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
-		return fmt.Errorf("failed to create config dir: %w", err)
-	}
+	// The config dir is only created when something is written, so read-only
+	// commands work on read-only filesystems
 
 	// Bind env vars
 	Config.AutomaticEnv()
 	Config.SetEnvPrefix("TOKENWATCH")
 
-	// Set defaults
-	Config.SetDefault("settings.cache_duration", 300)
-	Config.SetDefault("settings.request_timeout", 10)
-	Config.SetDefault("settings.retry_attempts", 3)
-	Config.SetDefault("settings.debug", false)
-	Config.SetDefault("data_dir", configDir)
-	Config.SetDefault("display.date_format", "2006-01-02 15:04:05")
-	Config.SetDefault("display.colors", true)
-	Config.SetDefault("display.table_style", "fancy")
-	Config.SetDefault("display.show_progress", true)
-	Config.SetDefault("output.default_format", "table")
-
-	// Read config file if exists
+	SetDefaults(Config, configDir)
+
+	// Read the system config first, then merge the user config over it so user
+	// values (including api_keys) always win
+	if err := mergeConfigFile(SystemConfigPath); err != nil {
+		return err
+	}
+	if err := mergeConfigFile(configPath); err != nil {
+		return err
+	}
+
+	// The user file is the one written to and reset
 	Config.SetConfigFile(configPath)
-	err := Config.ReadInConfig()
+
+	return nil
+}
+
+// SetDefaults sets the default value of every known setting on v. configDir
+// is the default data_dir. Anything building its own viper for the config
+// file (such as setup) uses it, so the defaults are declared only here.
+func SetDefaults(v *viper.Viper, configDir string) {
+	v.SetDefault("settings.cache_duration", 300)
+	v.SetDefault("settings.request_timeout", 10)
+	v.SetDefault("settings.dial_timeout", 10)
+	v.SetDefault("settings.response_header_timeout", 20)
+	v.SetDefault("settings.read_timeout", 30)
+	v.SetDefault("settings.retry_attempts", 3)
+	v.SetDefault("settings.max_concurrency", 4)
+	v.SetDefault("settings.max_pages", 50)
+	v.SetDefault("settings.max_response_bytes", 32<<20)
+	v.SetDefault("settings.empty_page_retries", 1)
+	v.SetDefault("settings.debug", false)
+	v.SetDefault("settings.persist_circuit_state", false)
+	v.SetDefault("settings.watch_cache_completed_days", true)
+	v.SetDefault("data_dir", configDir)
+	v.SetDefault("display.date_format", "2006-01-02 15:04:05")
+	v.SetDefault("display.colors", true)
+	v.SetDefault("display.table_style", "fancy")
+	v.SetDefault("display.show_progress", true)
+	v.SetDefault("display.icons", "emoji")
+	v.SetDefault("display.number_grouping", false)
+	v.SetDefault("display.show_recommendations", true)
+	v.SetDefault("output.default_format", "table")
+	v.SetDefault("openai.cost_in_cents", false)
+	v.SetDefault("pricing.source_url", "")
+	v.SetDefault("update.release_url", "")
+	v.SetDefault("providers.openai.rate_limit_rps", 1.0)
+	v.SetDefault("providers.openai.burst", 5)
+	v.SetDefault("telemetry.enabled", false)
+	v.SetDefault("telemetry.endpoint", "")
+}
+
+// mergeConfigFile merges path into the current config, ignoring missing files
+func mergeConfigFile(path string) error {
+	Config.SetConfigFile(path)
+	err := Config.MergeInConfig()
 	if err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
 			// File not found; use defaults and env - no error
 		} else if strings.Contains(err.Error(), "no such file or directory") {
 			// File was deleted or doesn't exist - use defaults and env - no error
 		} else {
-			return fmt.Errorf("failed to read config: %w", err)
+			return fmt.Errorf("failed to read config %s: %w", path, err)
 		}
 	}
-
 	return nil
 }
 
+// GetAPIKey returns the API key for platform. Keys in the config file win,
+// then the platform's environment variable. For OpenAI the key is then read
+// from the file named by OPENAI_API_KEY_FILE, and finally from the conventional
+// files listed by openAIKeyFiles.
 func GetAPIKey(platform string) string {
 	key := Config.GetString("api_keys." + platform)
 	if key == "" {
@@ -64,6 +158,9 @@ func GetAPIKey(platform string) string {
 		switch platform {
 		case "openai":
 			key = os.Getenv("OPENAI_API_KEY")
+			if key == "" {
+				key = openAIKeyFromFile()
+			}
 		case "anthropic":
 			key = os.Getenv("ANTHROPIC_API_KEY")
 		case "grok":
@@ -75,6 +172,42 @@ func GetAPIKey(platform string) string {
 	return key
 }
 
+// openAIKeyFiles returns the conventional OpenAI key files, in the order they are tried
+func openAIKeyFiles() []string {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		configHome = filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return []string{
+		filepath.Join(configHome, "openai", "key"),
+		filepath.Join(os.Getenv("HOME"), ".openai", "key"),
+	}
+}
+
+// openAIKeyFromFile reads the OpenAI key from OPENAI_API_KEY_FILE or, if that
+// is unset, the first conventional key file that exists. Surrounding
+// whitespace is trimmed; an empty or unreadable file yields no key.
+func openAIKeyFromFile() string {
+	if path := os.Getenv("OPENAI_API_KEY_FILE"); path != "" {
+		return readKeyFile(path)
+	}
+	for _, path := range openAIKeyFiles() {
+		if key := readKeyFile(path); key != "" {
+			return key
+		}
+	}
+	return ""
+}
+
+// readKeyFile returns the trimmed contents of path, or "" if it can't be read
+func readKeyFile(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
 // GetCacheDuration retrieves the cache duration in seconds
 func GetCacheDuration() int {
 	// Default to 5 minutes if not set
@@ -85,6 +218,179 @@ func GetCacheDuration() int {
 	return duration
 }
 
+// GetDialTimeout retrieves the connection dial timeout
+func GetDialTimeout() time.Duration {
+	seconds := Config.GetInt("settings.dial_timeout")
+	if seconds <= 0 {
+		return 10 * time.Second // 10 seconds default
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// GetResponseHeaderTimeout retrieves the timeout for waiting on response headers
+func GetResponseHeaderTimeout() time.Duration {
+	seconds := Config.GetInt("settings.response_header_timeout")
+	if seconds <= 0 {
+		return 20 * time.Second // 20 seconds default
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// GetReadTimeout retrieves how long a response body may go without data
+func GetReadTimeout() time.Duration {
+	seconds := Config.GetInt("settings.read_timeout")
+	if seconds <= 0 {
+		return 30 * time.Second // 30 seconds default
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// Display holds the typed display settings
+type Display struct {
+	Colors         bool
+	TableStyle     string
+	DateFormat     string
+	ShowProgress   bool
+	Icons          string
+	NumberGrouping bool
+	// ShowRecommendations controls whether the recommendations section is shown by default
+	ShowRecommendations bool
+}
+
+// DisplaySettings returns the display settings with defaults applied
+func DisplaySettings() Display {
+	settings := Display{
+		Colors:       true,
+		TableStyle:   "fancy",
+		DateFormat:   "2006-01-02 15:04:05",
+		ShowProgress: true,
+		Icons:        "emoji",
+
+		ShowRecommendations: true,
+	}
+	if Config == nil {
+		return settings
+	}
+
+	settings.Colors = Config.GetBool("display.colors")
+	settings.ShowProgress = Config.GetBool("display.show_progress")
+	settings.NumberGrouping = Config.GetBool("display.number_grouping")
+	settings.ShowRecommendations = Config.GetBool("display.show_recommendations")
+	if style := strings.ToLower(strings.TrimSpace(Config.GetString("display.table_style"))); style != "" {
+		settings.TableStyle = style
+	}
+	if icons := strings.ToLower(strings.TrimSpace(Config.GetString("display.icons"))); icons != "" {
+		settings.Icons = icons
+	}
+	settings.DateFormat = GetDateFormat()
+
+	return settings
+}
+
+// GetDateFormat retrieves the layout used for user-facing dates
+func GetDateFormat() string {
+	format := Config.GetString("display.date_format")
+	if format == "" {
+		return "2006-01-02 15:04:05"
+	}
+	return format
+}
+
+// GetDataDir returns the directory TokenWatch keeps its state in (cache,
+// daemon report, circuit breaker state). A leading ~ in data_dir is expanded
+// to the home directory.
+func GetDataDir() string {
+	dir := Config.GetString("data_dir")
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		dir = filepath.Join(os.Getenv("HOME"), strings.TrimPrefix(dir, "~"))
+	}
+	return filepath.Clean(dir)
+}
+
+// EnsureDataDir creates the data directory if needed and checks it is
+// writable, returning its path. Commands that must write state call it up front
+// so a misconfigured data_dir fails with a clear error instead of later.
+func EnsureDataDir() (string, error) {
+	dir := GetDataDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("data_dir %s can't be created: %w (set data_dir in your config to a writable directory)", dir, err)
+	}
+
+	probe, err := os.CreateTemp(dir, ".write-test-*")
+	if err != nil {
+		return "", fmt.Errorf("data_dir %s is not writable: %w (set data_dir in your config to a writable directory)", dir, err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	return dir, nil
+}
+
+// GetCacheDir returns the directory where cached API responses are stored
+func GetCacheDir() string {
+	return filepath.Join(GetDataDir(), "cache")
+}
+
+// GetMaxConcurrency retrieves the maximum number of concurrent fetches
+func GetMaxConcurrency() int {
+	n := Config.GetInt("settings.max_concurrency")
+	if n <= 0 {
+		return 4 // 4 concurrent fetches default
+	}
+	return n
+}
+
+// GetRateLimit retrieves the requests per second and burst configured for
+// platform under providers.<platform>. Zero means use the provider's default.
+func GetRateLimit(platform string) (float64, int) {
+	rps := Config.GetFloat64("providers." + platform + ".rate_limit_rps")
+	burst := Config.GetInt("providers." + platform + ".burst")
+	return rps, burst
+}
+
+// GetModelBudgets retrieves the monthly budget per model from budgets.models.
+// Model names containing dots (gpt-4.1-mini) are nested by the YAML parser, so
+// they are rebuilt from the flattened keys.
+func GetModelBudgets() map[string]float64 {
+	const prefix = "budgets.models."
+	budgets := make(map[string]float64)
+	for _, key := range Config.AllKeys() {
+		// Negative amounts can't be spent under, so they are ignored
+		if amount := Config.GetFloat64(key); strings.HasPrefix(key, prefix) && amount >= 0 {
+			budgets[strings.TrimPrefix(key, prefix)] = amount
+		}
+	}
+	return budgets
+}
+
+// GetEmptyPageRetries retrieves how many times a page with has_more but no
+// next_page token is re-requested. Zero disables the retry.
+func GetEmptyPageRetries() int {
+	n := Config.GetInt("settings.empty_page_retries")
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+// GetMaxPages retrieves the cap on pages requested by a single fetch; 0 means no cap
+func GetMaxPages() int {
+	n := Config.GetInt("settings.max_pages")
+	if n < 0 {
+		return 50 // 50 pages default
+	}
+	return n
+}
+
+// GetMaxResponseBytes retrieves the cap on response bytes read by a single fetch
+func GetMaxResponseBytes() int64 {
+	n := Config.GetInt64("settings.max_response_bytes")
+	if n <= 0 {
+		return 32 << 20 // 32 MiB default
+	}
+	return n
+}
+
 // GetString retrieves a string configuration value
 func GetString(key string) string {
 	return Config.GetString(key)
@@ -105,14 +411,66 @@ func GetFloat64(key string) float64 {
 	return Config.GetFloat64(key)
 }
 
+// GetStringMapString retrieves a map of string values
+func GetStringMapString(key string) map[string]string {
+	return Config.GetStringMapString(key)
+}
+
 // Set sets a configuration value
 func Set(key string, value interface{}) {
 	Config.Set(key, value)
 }
 
-// WriteConfig writes the current configuration to file
-func WriteConfig() error {
-	return Config.WriteConfig()
+// ResetKey removes key from the user config file so it falls back to the
+// system config or the default, returning the path written. Only the user
+// layer is read and written: Config also holds the system config and the
+// defaults, and writing those out would shadow later changes to them.
+func ResetKey(key string) (string, error) {
+	user := viper.New()
+	user.SetConfigFile(ResolveConfigPath())
+	if err := user.ReadInConfig(); err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read user config: %w", err)
+	}
+
+	settings := user.AllSettings()
+	deleteKey(settings, strings.Split(strings.ToLower(key), "."))
+
+	out := viper.New()
+	if err := out.MergeConfigMap(settings); err != nil {
+		return "", fmt.Errorf("failed to update user config: %w", err)
+	}
+	hasSecrets := false
+	for _, k := range out.AllKeys() {
+		if isSecretKey(k) && out.GetString(k) != "" {
+			hasSecrets = true
+		}
+	}
+
+	target := UserConfigPath()
+	if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		return "", fmt.Errorf("failed to create config dir: %w", err)
+	}
+	if err := writePortable(out, target, hasSecrets); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// deleteKey removes the nested key at path from settings, dropping sections
+// left empty
+func deleteKey(settings map[string]interface{}, path []string) {
+	if len(path) == 1 {
+		delete(settings, path[0])
+		return
+	}
+	section, ok := settings[path[0]].(map[string]interface{})
+	if !ok {
+		return
+	}
+	deleteKey(section, path[1:])
+	if len(section) == 0 {
+		delete(settings, path[0])
+	}
 }
 
 // GetConfigFile returns the current config file path
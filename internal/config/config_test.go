@@ -0,0 +1,435 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// setupDirs points HOME, XDG_CONFIG_HOME and the system config at temporary
+// locations and returns the home directory
+func setupDirs(t *testing.T, xdg bool) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if xdg {
+		t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "xdg"))
+	} else {
+		t.Setenv("XDG_CONFIG_HOME", "")
+	}
+
+	saved := SystemConfigPath
+	SystemConfigPath = filepath.Join(home, "system", configFileName)
+	t.Cleanup(func() { SystemConfigPath = saved })
+	return home
+}
+
+// writeFile writes content to path, creating its directory
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readUserFile reads path into its own viper, without defaults or other layers
+func readUserFile(t *testing.T, path string) *viper.Viper {
+	t.Helper()
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return v
+}
+
+func TestResolveConfigPathPrefersXDG(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG_CONFIG_HOME is only honored on Linux")
+	}
+	home := setupDirs(t, true)
+
+	want := filepath.Join(home, "xdg", "tokenwatch", configFileName)
+	if got := ResolveConfigPath(); got != want {
+		t.Errorf("ResolveConfigPath() = %q, want %q", got, want)
+	}
+
+	// The XDG file wins even when a legacy file exists
+	writeFile(t, filepath.Join(home, ".tokenwatch", configFileName), "settings:\n  debug: true\n")
+	writeFile(t, want, "settings:\n  debug: false\n")
+	if got := ResolveConfigPath(); got != want {
+		t.Errorf("ResolveConfigPath() with both files = %q, want %q", got, want)
+	}
+}
+
+func TestResolveConfigPathFallsBackToLegacy(t *testing.T) {
+	home := setupDirs(t, true)
+	legacy := filepath.Join(home, ".tokenwatch", configFileName)
+	writeFile(t, legacy, "settings:\n  debug: true\n")
+
+	if got := ResolveConfigPath(); got != legacy {
+		t.Errorf("ResolveConfigPath() = %q, want legacy %q", got, legacy)
+	}
+}
+
+func TestResolveConfigPathWithoutXDG(t *testing.T) {
+	home := setupDirs(t, false)
+
+	want := filepath.Join(home, ".tokenwatch", configFileName)
+	if got := ResolveConfigPath(); got != want {
+		t.Errorf("ResolveConfigPath() = %q, want %q", got, want)
+	}
+}
+
+func TestInitLayersUserOverSystem(t *testing.T) {
+	setupDirs(t, false)
+	writeFile(t, SystemConfigPath, `
+api_keys:
+  openai: sk-system
+settings:
+  max_concurrency: 8
+  retry_attempts: 5
+`)
+	writeFile(t, UserConfigPath(), `
+api_keys:
+  openai: sk-user
+settings:
+  retry_attempts: 2
+`)
+
+	if err := Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	if got := Config.GetString("api_keys.openai"); got != "sk-user" {
+		t.Errorf("api_keys.openai = %q, want the user key", got)
+	}
+	if got := Config.GetInt("settings.retry_attempts"); got != 2 {
+		t.Errorf("settings.retry_attempts = %d, want the user value 2", got)
+	}
+	if got := Config.GetInt("settings.max_concurrency"); got != 8 {
+		t.Errorf("settings.max_concurrency = %d, want the system value 8", got)
+	}
+	if got := Config.GetInt("settings.request_timeout"); got != 10 {
+		t.Errorf("settings.request_timeout = %d, want the default 10", got)
+	}
+}
+
+func TestResetKeyWritesOnlyTheUserLayer(t *testing.T) {
+	setupDirs(t, false)
+	writeFile(t, SystemConfigPath, "settings:\n  max_concurrency: 8\n")
+	writeFile(t, UserConfigPath(), "api_keys:\n  openai: sk-user\nsettings:\n  debug: true\n  retry_attempts: 2\n")
+
+	path, err := ResetKey("settings.debug")
+	if err != nil {
+		t.Fatalf("ResetKey: %v", err)
+	}
+	if path != UserConfigPath() {
+		t.Errorf("ResetKey wrote %q, want %q", path, UserConfigPath())
+	}
+
+	user := readUserFile(t, path)
+	if user.IsSet("settings.debug") {
+		t.Error("settings.debug is still in the user config")
+	}
+	if got := user.GetInt("settings.retry_attempts"); got != 2 {
+		t.Errorf("settings.retry_attempts = %d, want the untouched user value 2", got)
+	}
+	if got := user.GetString("api_keys.openai"); got != "sk-user" {
+		t.Errorf("api_keys.openai = %q, want it kept", got)
+	}
+	for _, key := range []string{"settings.max_concurrency", "settings.request_timeout"} {
+		if user.IsSet(key) {
+			t.Errorf("%s from the system config or defaults was written to the user config", key)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("user config with an API key has mode %o, want 0600", mode)
+	}
+
+	// A later change to the system config is still seen
+	writeFile(t, SystemConfigPath, "settings:\n  max_concurrency: 12\n")
+	if err := Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	if got := Config.GetInt("settings.max_concurrency"); got != 12 {
+		t.Errorf("settings.max_concurrency = %d, want the updated system value 12", got)
+	}
+}
+
+func TestResetKeyMigratesLegacyConfig(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("XDG_CONFIG_HOME is only honored on Linux")
+	}
+	home := setupDirs(t, true)
+	legacy := filepath.Join(home, ".tokenwatch", configFileName)
+	writeFile(t, legacy, "settings:\n  debug: true\n  retry_attempts: 2\n")
+
+	path, err := ResetKey("settings.debug")
+	if err != nil {
+		t.Fatalf("ResetKey: %v", err)
+	}
+	want := filepath.Join(home, "xdg", "tokenwatch", configFileName)
+	if path != want {
+		t.Fatalf("ResetKey wrote %q, want the XDG location %q", path, want)
+	}
+	if got := readUserFile(t, path).GetInt("settings.retry_attempts"); got != 2 {
+		t.Errorf("migrated settings.retry_attempts = %d, want 2", got)
+	}
+
+	// The legacy file is left alone, and the new file is read from now on
+	if !readUserFile(t, legacy).GetBool("settings.debug") {
+		t.Error("the legacy config file was modified")
+	}
+	if got := ResolveConfigPath(); got != want {
+		t.Errorf("ResolveConfigPath() after migration = %q, want %q", got, want)
+	}
+}
+
+func TestResetKeyDropsEmptySections(t *testing.T) {
+	setupDirs(t, false)
+	writeFile(t, UserConfigPath(), "settings:\n  debug: true\nmodels:\n  gpt-4o: 1\n")
+
+	path, err := ResetKey("settings.debug")
+	if err != nil {
+		t.Fatalf("ResetKey: %v", err)
+	}
+	if readUserFile(t, path).IsSet("settings") {
+		t.Error("the empty settings section was kept")
+	}
+}
+
+func TestGetModelBudgetsKeepsDottedModelNames(t *testing.T) {
+	setupDirs(t, false)
+	writeFile(t, UserConfigPath(), `
+budgets:
+  models:
+    gpt-4o: 100
+    gpt-3.5-turbo: 20
+    o1: -5
+`)
+	if err := Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	budgets := GetModelBudgets()
+	want := map[string]float64{"gpt-4o": 100, "gpt-3.5-turbo": 20}
+	if len(budgets) != len(want) {
+		t.Fatalf("GetModelBudgets() = %v, want %v", budgets, want)
+	}
+	for model, amount := range want {
+		if budgets[model] != amount {
+			t.Errorf("budget for %s = %v, want %v", model, budgets[model], amount)
+		}
+	}
+}
+
+func TestGetAPIKeyPrecedence(t *testing.T) {
+	home := setupDirs(t, true)
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("OPENAI_API_KEY_FILE", "")
+	writeFile(t, UserConfigPath(), "api_keys:\n  openai: sk-config\n")
+	writeFile(t, filepath.Join(home, "xdg", "openai", "key"), "sk-xdg-file\n")
+	writeFile(t, filepath.Join(home, ".openai", "key"), "sk-home-file\n")
+	named := filepath.Join(home, "named-key")
+	writeFile(t, named, "  sk-named-file  \n")
+
+	steps := []struct {
+		name  string
+		apply func()
+		want  string
+	}{
+		{"config file", func() {}, "sk-config"},
+		{"environment variable", func() {
+			writeFile(t, UserConfigPath(), "settings:\n  debug: false\n")
+			t.Setenv("OPENAI_API_KEY", "sk-env")
+		}, "sk-env"},
+		{"OPENAI_API_KEY_FILE", func() {
+			t.Setenv("OPENAI_API_KEY", "")
+			t.Setenv("OPENAI_API_KEY_FILE", named)
+		}, "sk-named-file"},
+		{"XDG key file", func() { t.Setenv("OPENAI_API_KEY_FILE", "") }, "sk-xdg-file"},
+		{"home key file", func() { os.Remove(filepath.Join(home, "xdg", "openai", "key")) }, "sk-home-file"},
+		{"no key", func() { os.Remove(filepath.Join(home, ".openai", "key")) }, ""},
+	}
+	// Each step removes the source the previous one found, so the next in line is used
+	for _, step := range steps {
+		step.apply()
+		if err := Init(); err != nil {
+			t.Fatalf("Init: %v", err)
+		}
+		if got := GetAPIKey("openai"); got != step.want {
+			t.Errorf("%s: GetAPIKey(openai) = %q, want %q", step.name, got, step.want)
+		}
+	}
+
+	// The config file wins over the environment variable
+	writeFile(t, UserConfigPath(), "api_keys:\n  openai: sk-config\n")
+	t.Setenv("OPENAI_API_KEY", "sk-env")
+	if err := Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	if got := GetAPIKey("openai"); got != "sk-config" {
+		t.Errorf("GetAPIKey(openai) with config and env = %q, want the config key", got)
+	}
+}
+
+func TestGetDataDirRelocation(t *testing.T) {
+	home := setupDirs(t, false)
+
+	tests := []struct {
+		config string
+		want   string
+	}{
+		{config: "", want: filepath.Join(home, ".tokenwatch")},
+		{config: "data_dir: ~/state\n", want: filepath.Join(home, "state")},
+		{config: "data_dir: \"~\"\n", want: home},
+		{config: "data_dir: /var/lib/tokenwatch/\n", want: "/var/lib/tokenwatch"},
+	}
+	for _, tt := range tests {
+		writeFile(t, UserConfigPath(), tt.config)
+		if err := Init(); err != nil {
+			t.Fatalf("Init: %v", err)
+		}
+		if got := GetDataDir(); got != tt.want {
+			t.Errorf("GetDataDir() with %q = %q, want %q", tt.config, got, tt.want)
+		}
+		if got, want := GetCacheDir(), filepath.Join(tt.want, "cache"); got != want {
+			t.Errorf("GetCacheDir() with %q = %q, want %q", tt.config, got, want)
+		}
+	}
+}
+
+func TestEnsureDataDir(t *testing.T) {
+	home := setupDirs(t, false)
+	writeFile(t, UserConfigPath(), "data_dir: ~/nested/state\n")
+	if err := Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	dir, err := EnsureDataDir()
+	if err != nil {
+		t.Fatalf("EnsureDataDir: %v", err)
+	}
+	if want := filepath.Join(home, "nested", "state"); dir != want {
+		t.Errorf("EnsureDataDir() = %q, want %q", dir, want)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("data dir was not created: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("the write probe was left behind: %v", entries)
+	}
+
+	// A data_dir that can't be created fails up front, naming the setting
+	writeFile(t, filepath.Join(home, "blocker"), "")
+	writeFile(t, UserConfigPath(), "data_dir: ~/blocker/state\n")
+	if err := Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	if _, err := EnsureDataDir(); err == nil || !strings.Contains(err.Error(), "set data_dir") {
+		t.Errorf("EnsureDataDir() under a file = %v, want an error pointing at data_dir", err)
+	}
+}
+
+func TestEnsureDataDirRejectsReadOnlyDir(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can write to read-only directories")
+	}
+	home := setupDirs(t, false)
+	readOnly := filepath.Join(home, "readonly")
+	if err := os.Mkdir(readOnly, 0500); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, UserConfigPath(), "data_dir: "+readOnly+"\n")
+	if err := Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	if _, err := EnsureDataDir(); err == nil || !strings.Contains(err.Error(), "not writable") {
+		t.Errorf("EnsureDataDir() on a read-only dir = %v, want a not writable error", err)
+	}
+}
+
+func TestDisplaySettings(t *testing.T) {
+	setupDirs(t, false)
+	if err := Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	want := Display{
+		Colors:              true,
+		TableStyle:          "fancy",
+		DateFormat:          "2006-01-02 15:04:05",
+		ShowProgress:        true,
+		Icons:               "emoji",
+		ShowRecommendations: true,
+	}
+	if got := DisplaySettings(); got != want {
+		t.Errorf("defaults = %+v, want %+v", got, want)
+	}
+
+	writeFile(t, UserConfigPath(), `
+display:
+  colors: false
+  table_style: " Simple "
+  date_format: "02 Jan 15:04"
+  show_progress: false
+  icons: ASCII
+  number_grouping: true
+  show_recommendations: false
+`)
+	if err := Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+	want = Display{
+		TableStyle:     "simple",
+		DateFormat:     "02 Jan 15:04",
+		Icons:          "ascii",
+		NumberGrouping: true,
+	}
+	if got := DisplaySettings(); got != want {
+		t.Errorf("overrides = %+v, want %+v", got, want)
+	}
+}
+
+func TestGetRateLimitPerPlatform(t *testing.T) {
+	setupDirs(t, false)
+	writeFile(t, UserConfigPath(), `
+providers:
+  anthropic:
+    rate_limit_rps: 50
+    burst: 20
+  grok:
+    rate_limit_rps: 2.5
+`)
+	if err := Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	tests := []struct {
+		platform string
+		rps      float64
+		burst    int
+	}{
+		{platform: "openai", rps: 1, burst: 5}, // defaults
+		{platform: "anthropic", rps: 50, burst: 20},
+		{platform: "grok", rps: 2.5}, // no burst: the provider's default
+		{platform: "cursor"},
+	}
+	for _, tt := range tests {
+		if rps, burst := GetRateLimit(tt.platform); rps != tt.rps || burst != tt.burst {
+			t.Errorf("GetRateLimit(%s) = %v, %d; want %v, %d", tt.platform, rps, burst, tt.rps, tt.burst)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// RedactedValue replaces secret values in a config exported without secrets.
+// Importing a redacted value keeps the value already configured on this machine.
+const RedactedValue = "[REDACTED]"
+
+// secretKeyPatterns match keys holding credentials
+var secretKeyPatterns = []string{
+	"api_keys.*",
+	"openai.extra_headers.*",
+	"export.push_token",
+}
+
+// extraKeyPatterns match known keys that have no default
+var extraKeyPatterns = []string{
+	"api_keys.*",
+	"openai.organization_id",
+	"openai.organization_label",
+	"openai.project_id",
+	"openai.extra_headers.*",
+	"providers.*.rate_limit_rps",
+	"providers.*.burst",
+	"export.push_token",
+	"budgets.models.*",
+}
+
+// matchesAny reports whether key matches one of the path.Match patterns
+func matchesAny(key string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, key); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isSecretKey reports whether key holds a credential
+func isSecretKey(key string) bool {
+	return matchesAny(key, secretKeyPatterns)
+}
+
+// isKnownKey reports whether key is a setting TokenWatch reads
+func isKnownKey(key string) bool {
+	defaults := viper.New()
+	SetDefaults(defaults, "")
+	for _, known := range defaults.AllKeys() {
+		if key == known {
+			return true
+		}
+	}
+	return matchesAny(key, extraKeyPatterns)
+}
+
+// ExportFile writes the effective config to file. Secret values are replaced
+// with RedactedValue unless includeSecrets is set, in which case the file is
+// written with 0600 permissions. data_dir is only exported when it was
+// configured explicitly, since the default is specific to this machine.
+func ExportFile(file string, includeSecrets bool) error {
+	out := viper.New()
+	hasSecrets := false
+	for _, key := range Config.AllKeys() {
+		if key == "data_dir" && !Config.InConfig(key) {
+			continue
+		}
+		value := Config.Get(key)
+		if isSecretKey(key) {
+			if !includeSecrets {
+				value = RedactedValue
+			} else if fmt.Sprint(value) != "" {
+				hasSecrets = true
+			}
+		}
+		out.Set(key, value)
+	}
+
+	return writePortable(out, file, hasSecrets)
+}
+
+// ImportFile validates the config in file and writes it to the user config
+// location, returning the path written. Unknown keys are rejected; redacted
+// secrets keep the value currently configured, or are dropped when there is
+// none.
+func ImportFile(file string) (string, error) {
+	in := viper.New()
+	in.SetConfigFile(file)
+	if err := in.ReadInConfig(); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	var unknown []string
+	for _, key := range in.AllKeys() {
+		if !isKnownKey(key) {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return "", fmt.Errorf("unknown config keys in %s: %s", file, strings.Join(unknown, ", "))
+	}
+
+	// Redacted secrets fall back to the user's existing config file
+	existing := viper.New()
+	existing.SetConfigFile(ResolveConfigPath())
+	_ = existing.ReadInConfig()
+
+	out := viper.New()
+	hasSecrets := false
+	for _, key := range in.AllKeys() {
+		value := in.Get(key)
+		if isSecretKey(key) {
+			if fmt.Sprint(value) == RedactedValue {
+				if !existing.IsSet(key) {
+					continue
+				}
+				value = existing.Get(key)
+			}
+			if fmt.Sprint(value) != "" {
+				hasSecrets = true
+			}
+		}
+		out.Set(key, value)
+	}
+
+	target := UserConfigPath()
+	if err := os.MkdirAll(filepath.Dir(target), 0700); err != nil {
+		return "", fmt.Errorf("failed to create config dir: %w", err)
+	}
+	if err := writePortable(out, target, hasSecrets); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// writePortable writes v to file as YAML, restricting it to 0600 when it
+// contains secrets
+func writePortable(v *viper.Viper, file string, hasSecrets bool) error {
+	v.SetConfigType("yaml")
+	if hasSecrets {
+		v.SetConfigPermissions(0600)
+	}
+	if err := v.WriteConfigAs(file); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	// An existing file keeps its mode on write, so tighten it explicitly
+	if hasSecrets {
+		if err := os.Chmod(file, 0600); err != nil {
+			return fmt.Errorf("failed to set permissions on %s: %w", file, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportImportRoundTrip(t *testing.T) {
+	home := setupDirs(t, false)
+	systemConfig := "settings:\n  max_concurrency: 8\n"
+	writeFile(t, SystemConfigPath, systemConfig)
+	writeFile(t, UserConfigPath(), "api_keys:\n  openai: sk-user\nsettings:\n  retry_attempts: 2\n")
+	if err := Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	exported := filepath.Join(home, "exported.yaml")
+	if err := ExportFile(exported, false); err != nil {
+		t.Fatalf("ExportFile: %v", err)
+	}
+	file := readUserFile(t, exported)
+	if got := file.GetString("api_keys.openai"); got != RedactedValue {
+		t.Errorf("exported api_keys.openai = %q, want it redacted", got)
+	}
+	if got := file.GetInt("settings.max_concurrency"); got != 8 {
+		t.Errorf("exported settings.max_concurrency = %d, want the system value 8", got)
+	}
+	if file.IsSet("data_dir") {
+		t.Error("the machine-specific default data_dir was exported")
+	}
+
+	// Import on a machine whose user config has drifted
+	writeFile(t, UserConfigPath(), "api_keys:\n  openai: sk-user\nsettings:\n  retry_attempts: 7\n")
+	path, err := ImportFile(exported)
+	if err != nil {
+		t.Fatalf("ImportFile: %v", err)
+	}
+	if path != UserConfigPath() {
+		t.Errorf("ImportFile wrote %q, want the user config %q", path, UserConfigPath())
+	}
+
+	user := readUserFile(t, path)
+	if got := user.GetString("api_keys.openai"); got != "sk-user" {
+		t.Errorf("imported api_keys.openai = %q, want the existing key kept", got)
+	}
+	if got := user.GetInt("settings.retry_attempts"); got != 2 {
+		t.Errorf("imported settings.retry_attempts = %d, want the exported value 2", got)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("imported config with an API key has mode %o, want 0600", mode)
+	}
+
+	// The system config is never written
+	data, err := os.ReadFile(SystemConfigPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != systemConfig {
+		t.Errorf("system config changed to %q", data)
+	}
+}
+
+func TestExportIncludeSecrets(t *testing.T) {
+	home := setupDirs(t, false)
+	writeFile(t, UserConfigPath(), "api_keys:\n  openai: sk-user\n")
+	if err := Init(); err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	exported := filepath.Join(home, "exported.yaml")
+	if err := ExportFile(exported, true); err != nil {
+		t.Fatalf("ExportFile: %v", err)
+	}
+	if got := readUserFile(t, exported).GetString("api_keys.openai"); got != "sk-user" {
+		t.Errorf("exported api_keys.openai = %q, want the key", got)
+	}
+	info, err := os.Stat(exported)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("export with secrets has mode %o, want 0600", mode)
+	}
+}
+
+func TestImportRejectsUnknownKeys(t *testing.T) {
+	home := setupDirs(t, false)
+	file := filepath.Join(home, "import.yaml")
+	writeFile(t, file, "settings:\n  debug: true\n  colour: blue\nextra: 1\n")
+
+	_, err := ImportFile(file)
+	if err == nil || !strings.Contains(err.Error(), "extra, settings.colour") {
+		t.Errorf("ImportFile error = %v, want one listing the unknown keys", err)
+	}
+	if _, err := os.Stat(UserConfigPath()); !os.IsNotExist(err) {
+		t.Errorf("a rejected import wrote the user config (stat: %v)", err)
+	}
+}
+
+func TestImportDropsRedactedSecretWithoutExistingValue(t *testing.T) {
+	home := setupDirs(t, false)
+	file := filepath.Join(home, "import.yaml")
+	writeFile(t, file, "api_keys:\n  openai: \""+RedactedValue+"\"\nsettings:\n  debug: true\n")
+
+	path, err := ImportFile(file)
+	if err != nil {
+		t.Fatalf("ImportFile: %v", err)
+	}
+	user := readUserFile(t, path)
+	if user.IsSet("api_keys.openai") {
+		t.Errorf("api_keys.openai = %q, want the redacted placeholder dropped", user.GetString("api_keys.openai"))
+	}
+	if !user.GetBool("settings.debug") {
+		t.Error("settings.debug was not imported")
+	}
+}
@@ -0,0 +1,224 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// entryExt is the file extension used for cache entries
+const entryExt = ".json"
+
+// Temporary files that entries are written to before being renamed into place
+const (
+	tempPrefix = ".entry-"
+	tempExt    = ".tmp"
+)
+
+// staleTempAge is how old a temporary file must be before Prune removes it.
+// Writes finish in well under this, so an older file was left behind by a
+// run that died between creating and renaming it.
+const staleTempAge = time.Hour
+
+// Entry is the on-disk representation of a cached API response
+type Entry struct {
+	Key       string          `json:"key"`
+	ExpiresAt time.Time       `json:"expires_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// IsExpired reports whether the entry has expired at the given time
+func (e *Entry) IsExpired(now time.Time) bool {
+	return now.After(e.ExpiresAt)
+}
+
+// entryPath returns the file for key in dir. Keys are hashed so they never
+// appear on disk and are always safe file names.
+func entryPath(dir, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dir, hex.EncodeToString(sum[:])+entryExt)
+}
+
+// Load decodes the unexpired entry for key in dir into v, reporting whether one was found
+func Load(dir, key string, now time.Time, v interface{}) bool {
+	data, err := os.ReadFile(entryPath(dir, key))
+	if err != nil {
+		return false
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Key != key || entry.IsExpired(now) {
+		return false
+	}
+	return json.Unmarshal(entry.Data, v) == nil
+}
+
+// Store writes v as the entry for key in dir, expiring after ttl
+func Store(dir, key string, v interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	entry, err := json.Marshal(Entry{Key: key, ExpiresAt: time.Now().Add(ttl), Data: data})
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return writeAtomic(entryPath(dir, key), entry)
+}
+
+// writeAtomic writes data to a temporary file next to path and renames it into
+// place, so concurrent runs never read a partially written entry
+func writeAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), tempPrefix+"*"+tempExt)
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// Clear removes every cache entry from dir, expired or not. Files that
+// aren't cache entries are left alone. A missing directory is not an error.
+func Clear(dir string) (PruneResult, error) {
+	var result PruneResult
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return result, nil
+		}
+		return result, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), entryExt) {
+			continue
+		}
+		result.Scanned++
+
+		var size int64
+		if info, err := file.Info(); err == nil {
+			size = info.Size()
+		}
+		if err := os.Remove(filepath.Join(dir, file.Name())); err != nil {
+			return result, fmt.Errorf("failed to remove cache entry %s: %w", file.Name(), err)
+		}
+		result.Removed++
+		result.BytesReclaimed += size
+	}
+
+	return result, nil
+}
+
+// PruneResult summarizes a prune or clear run
+type PruneResult struct {
+	Scanned        int
+	Removed        int
+	BytesReclaimed int64
+}
+
+// pruneStart picks where a limited prune starts in a directory of n files;
+// tests replace it to make the scan order deterministic
+var pruneStart = func(n int) int { return rand.IntN(n) }
+
+// Prune removes expired cache entries from dir, along with temporary files
+// older than staleTempAge. At most maxFiles files are examined (0 means no
+// limit), which keeps opportunistic pruning cheap. A limited prune starts at
+// a random file and wraps around, so repeated runs reach every entry rather
+// than rechecking the first maxFiles by name. A missing directory is not an error.
+func Prune(dir string, now time.Time, maxFiles int) (PruneResult, error) {
+	var result PruneResult
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return result, nil
+		}
+		return result, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+	if maxFiles > 0 && len(files) > maxFiles {
+		start := pruneStart(len(files))
+		files = append(append([]os.DirEntry{}, files[start:]...), files[:start]...)
+	}
+
+	for _, file := range files {
+		if maxFiles > 0 && result.Scanned >= maxFiles {
+			break
+		}
+		if file.IsDir() {
+			continue
+		}
+		if isTempFile(file.Name()) {
+			result.Scanned++
+			if err := pruneTempFile(dir, file, now, &result); err != nil {
+				return result, err
+			}
+			continue
+		}
+		if !strings.HasSuffix(file.Name(), entryExt) {
+			continue
+		}
+		result.Scanned++
+
+		path := filepath.Join(dir, file.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		// Unreadable entries can never be served, so treat them as expired
+		var entry Entry
+		if err := json.Unmarshal(data, &entry); err == nil && !entry.IsExpired(now) {
+			continue
+		}
+
+		if err := os.Remove(path); err != nil {
+			return result, fmt.Errorf("failed to remove cache entry %s: %w", file.Name(), err)
+		}
+		result.Removed++
+		result.BytesReclaimed += int64(len(data))
+	}
+
+	return result, nil
+}
+
+// isTempFile reports whether name is a temporary file created by writeAtomic
+func isTempFile(name string) bool {
+	return strings.HasPrefix(name, tempPrefix) && strings.HasSuffix(name, tempExt)
+}
+
+// pruneTempFile removes a temporary file that is older than staleTempAge
+func pruneTempFile(dir string, file os.DirEntry, now time.Time, result *PruneResult) error {
+	info, err := file.Info()
+	if err != nil || now.Sub(info.ModTime()) < staleTempAge {
+		return nil
+	}
+	if err := os.Remove(filepath.Join(dir, file.Name())); err != nil {
+		return fmt.Errorf("failed to remove temporary file %s: %w", file.Name(), err)
+	}
+	result.Removed++
+	result.BytesReclaimed += info.Size()
+	return nil
+}
@@ -0,0 +1,245 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type payload struct {
+	Name  string `json:"name"`
+	Items []int  `json:"items"`
+}
+
+func TestStoreLoadRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	in := payload{Name: "usage", Items: []int{1, 2, 3}}
+	if err := Store(dir, "key", in, time.Hour); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+
+	var out payload
+	if !Load(dir, "key", time.Now(), &out) {
+		t.Fatal("Load found no entry")
+	}
+	if out.Name != in.Name || len(out.Items) != 3 {
+		t.Errorf("Load = %+v, want %+v", out, in)
+	}
+
+	if Load(dir, "other", time.Now(), &out) {
+		t.Error("Load found an entry for a key that was never stored")
+	}
+	if Load(dir, "key", time.Now().Add(2*time.Hour), &out) {
+		t.Error("Load returned an expired entry")
+	}
+}
+
+func TestStoreLeavesOnlyTheEntry(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 3; i++ {
+		if err := Store(dir, "key", payload{Items: []int{i}}, time.Hour); err != nil {
+			t.Fatalf("Store: %v", err)
+		}
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || !strings.HasSuffix(files[0].Name(), entryExt) {
+		var names []string
+		for _, f := range files {
+			names = append(names, f.Name())
+		}
+		t.Fatalf("expected a single entry file, got %v", names)
+	}
+
+	info, err := files[0].Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mode := info.Mode().Perm(); mode != 0600 {
+		t.Errorf("entry has mode %o, want 0600", mode)
+	}
+}
+
+func TestStoreIsAtomicUnderConcurrentLoads(t *testing.T) {
+	dir := t.TempDir()
+	big := payload{Name: "big", Items: make([]int, 10000)}
+	if err := Store(dir, "key", big, time.Hour); err != nil {
+		t.Fatalf("Store: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			if err := Store(dir, "key", big, time.Hour); err != nil {
+				t.Errorf("Store: %v", err)
+			}
+		}
+		close(stop)
+	}()
+
+	for {
+		select {
+		case <-stop:
+			wg.Wait()
+			return
+		default:
+		}
+		var out payload
+		// A reader always sees a whole entry, never a truncated one
+		if !Load(dir, "key", time.Now(), &out) || len(out.Items) != len(big.Items) {
+			t.Fatal("Load saw a partially written entry")
+		}
+	}
+}
+
+func TestClearKeepsOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := Store(dir, "a", 1, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := Store(dir, "b", 2, -time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	other := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(other, []byte("keep"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Clear(dir)
+	if err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	if result.Removed != 2 {
+		t.Errorf("Clear removed %d entries, want 2", result.Removed)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("Clear removed a file that isn't a cache entry: %v", err)
+	}
+
+	if _, err := Clear(filepath.Join(dir, "missing")); err != nil {
+		t.Errorf("Clear of a missing directory: %v", err)
+	}
+}
+
+func TestPruneRemovesOnlyExpired(t *testing.T) {
+	dir := t.TempDir()
+	for _, key := range []string{"fresh-1", "fresh-2"} {
+		if err := Store(dir, key, key, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, key := range []string{"old-1", "old-2", "old-3"} {
+		if err := Store(dir, key, key, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	corrupt := filepath.Join(dir, "corrupt"+entryExt)
+	if err := os.WriteFile(corrupt, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Prune(dir, time.Now().Add(10*time.Minute), 0)
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if result.Scanned != 6 || result.Removed != 4 {
+		t.Errorf("Prune scanned %d and removed %d, want 6 and 4", result.Scanned, result.Removed)
+	}
+	if result.BytesReclaimed <= 0 {
+		t.Errorf("BytesReclaimed = %d, want > 0", result.BytesReclaimed)
+	}
+
+	var v string
+	for _, key := range []string{"fresh-1", "fresh-2"} {
+		if !Load(dir, key, time.Now(), &v) {
+			t.Errorf("unexpired entry %s was pruned", key)
+		}
+	}
+	if _, err := os.Stat(corrupt); !os.IsNotExist(err) {
+		t.Error("an unreadable entry was kept")
+	}
+}
+
+func TestPruneLimitRotatesStart(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 6; i++ {
+		if err := Store(dir, string(rune('a'+i)), i, -time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	starts := []int{0, 2, 4}
+	saved := pruneStart
+	pruneStart = func(n int) int {
+		start := starts[0]
+		starts = starts[1:]
+		return start
+	}
+	t.Cleanup(func() { pruneStart = saved })
+
+	// Each limited run examines a different slice of the directory, so three
+	// runs of two files remove all six expired entries
+	for i := 0; i < 3; i++ {
+		result, err := Prune(dir, time.Now(), 2)
+		if err != nil {
+			t.Fatalf("Prune: %v", err)
+		}
+		if result.Scanned != 2 || result.Removed != 2 {
+			t.Errorf("run %d scanned %d and removed %d, want 2 and 2", i, result.Scanned, result.Removed)
+		}
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Errorf("%d expired entries left after covering the directory", len(files))
+	}
+}
+
+func TestPruneRemovesStaleTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, tempPrefix+"123"+tempExt)
+	recent := filepath.Join(dir, tempPrefix+"456"+tempExt)
+	for _, path := range []string{stale, recent} {
+		if err := os.WriteFile(path, []byte(`{"key":`), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// The stale file was left by a run that died before renaming it
+	old := time.Now().Add(-2 * staleTempAge)
+	if err := os.Chtimes(stale, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Prune(dir, time.Now(), 0)
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+	if result.Removed != 1 {
+		t.Errorf("Prune removed %d files, want 1", result.Removed)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Error("a stale temporary file was kept")
+	}
+	if _, err := os.Stat(recent); err != nil {
+		t.Errorf("a temporary file that may still be written was removed: %v", err)
+	}
+}
+
+func TestPruneMissingDir(t *testing.T) {
+	result, err := Prune(filepath.Join(t.TempDir(), "missing"), time.Now(), 0)
+	if err != nil || result.Scanned != 0 {
+		t.Errorf("Prune of a missing directory = %+v, %v", result, err)
+	}
+}
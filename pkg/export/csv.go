@@ -0,0 +1,40 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// CSVHeader is the header line written by WriteCSV
+var CSVHeader = []string{"platform", "model", "input_tokens", "output_tokens", "total_tokens", "requests", "cost", "currency"}
+
+// WriteCSV writes report as CSV: a header line followed by one line per model.
+// There is no totals line, so spreadsheets can sum the columns themselves.
+func WriteCSV(w io.Writer, report *UsageReport) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(CSVHeader); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	for _, m := range report.Models {
+		row := []string{
+			report.Platform,
+			m.Model,
+			strconv.FormatInt(m.InputTokens, 10),
+			strconv.FormatInt(m.OutputTokens, 10),
+			strconv.FormatInt(m.TotalTokens, 10),
+			strconv.FormatInt(m.Requests, 10),
+			strconv.FormatFloat(m.Cost, 'f', -1, 64),
+			report.Totals.Currency,
+		}
+		if err := cw.Write(row); err != nil {
+			return fmt.Errorf("failed to write CSV: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write CSV: %w", err)
+	}
+	return nil
+}
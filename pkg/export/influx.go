@@ -0,0 +1,58 @@
+package export
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// InfluxMeasurement is the measurement name used for every exported point
+const InfluxMeasurement = "tokenwatch"
+
+// InfluxPoint holds the usage of one model in one time bucket
+type InfluxPoint struct {
+	Platform     string
+	Model        string
+	Time         time.Time // start of the bucket
+	InputTokens  int64
+	OutputTokens int64
+	TotalTokens  int64
+	Requests     int64
+	Cost         float64
+}
+
+// influxTagEscaper escapes the characters that are special in tag keys and values
+var influxTagEscaper = strings.NewReplacer(`,`, `\,`, ` `, `\ `, `=`, `\=`)
+
+// RenderInflux renders points as InfluxDB line protocol, one line per point:
+//
+//	tokenwatch,platform=openai,model=gpt-4o input_tokens=123i,...,cost=4.5 1700000000000000000
+//
+// Token and request counts are integer fields, cost is a float field, and the
+// timestamp is the bucket start in nanoseconds.
+func RenderInflux(points []InfluxPoint) string {
+	var b strings.Builder
+	for _, p := range points {
+		b.WriteString(InfluxMeasurement)
+		b.WriteString(",platform=")
+		b.WriteString(influxTagEscaper.Replace(p.Platform))
+		b.WriteString(",model=")
+		b.WriteString(influxTagEscaper.Replace(p.Model))
+
+		b.WriteString(" input_tokens=")
+		b.WriteString(strconv.FormatInt(p.InputTokens, 10) + "i")
+		b.WriteString(",output_tokens=")
+		b.WriteString(strconv.FormatInt(p.OutputTokens, 10) + "i")
+		b.WriteString(",total_tokens=")
+		b.WriteString(strconv.FormatInt(p.TotalTokens, 10) + "i")
+		b.WriteString(",requests=")
+		b.WriteString(strconv.FormatInt(p.Requests, 10) + "i")
+		b.WriteString(",cost=")
+		b.WriteString(strconv.FormatFloat(p.Cost, 'f', -1, 64))
+
+		b.WriteString(" ")
+		b.WriteString(strconv.FormatInt(p.Time.UnixNano(), 10))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
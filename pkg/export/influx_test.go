@@ -0,0 +1,44 @@
+package export
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderInfluxLayout(t *testing.T) {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	points := []InfluxPoint{
+		{Platform: "openai", Model: "gpt-4o", Time: day, InputTokens: 123, OutputTokens: 45, TotalTokens: 168, Requests: 3, Cost: 4.5},
+		{Platform: "openai", Model: "o1", Time: day.AddDate(0, 0, 1), Cost: 0.000125},
+	}
+
+	want := "tokenwatch,platform=openai,model=gpt-4o input_tokens=123i,output_tokens=45i,total_tokens=168i,requests=3i,cost=4.5 1704067200000000000\n" +
+		"tokenwatch,platform=openai,model=o1 input_tokens=0i,output_tokens=0i,total_tokens=0i,requests=0i,cost=0.000125 1704153600000000000\n"
+	if got := RenderInflux(points); got != want {
+		t.Errorf("RenderInflux =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestRenderInfluxEscapesTags(t *testing.T) {
+	points := []InfluxPoint{{Platform: "open ai", Model: "ft:gpt-4o,org=acme team", Time: time.Unix(0, 0)}}
+
+	got := RenderInflux(points)
+	wantTags := `tokenwatch,platform=open\ ai,model=ft:gpt-4o\,org\=acme\ team `
+	if !strings.HasPrefix(got, wantTags) {
+		t.Errorf("RenderInflux = %q, want it to start with %q", got, wantTags)
+	}
+
+	// Only the two separators between tags, fields and timestamp stay unescaped
+	line := strings.TrimSuffix(got, "\n")
+	unescaped := strings.Count(line, " ") - strings.Count(line, `\ `)
+	if unescaped != 2 {
+		t.Errorf("line has %d unescaped spaces, want 2 separating tags, fields and timestamp: %q", unescaped, line)
+	}
+}
+
+func TestRenderInfluxEmpty(t *testing.T) {
+	if got := RenderInflux(nil); got != "" {
+		t.Errorf("RenderInflux(nil) = %q, want empty", got)
+	}
+}
@@ -0,0 +1,101 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// UsageReport is the JSON document written by `usage --format json`
+type UsageReport struct {
+	Platform   string       `json:"platform"`
+	Period     string       `json:"period"`
+	StartTime  time.Time    `json:"start_time"`
+	EndTime    time.Time    `json:"end_time"`
+	Models     []ModelUsage `json:"models"`
+	Totals     UsageTotals  `json:"totals"`
+	CostStatus string       `json:"cost_status"` // "billed", "free_tier", or "unavailable"
+
+	// BudgetBreaches lists models over their budgets.models amount, if any
+	BudgetBreaches []BudgetBreach `json:"budget_breaches,omitempty"`
+}
+
+// BudgetBreach is a model whose cost exceeded its budget for the report period
+type BudgetBreach struct {
+	Model         string  `json:"model"`
+	Cost          float64 `json:"cost"`
+	Budget        float64 `json:"budget"`         // monthly budget scaled to the report period
+	MonthlyBudget float64 `json:"monthly_budget"` // budget as configured
+}
+
+// ModelUsage holds the aggregated usage for a single model
+type ModelUsage struct {
+	Model        string  `json:"model"`
+	InputTokens  int64   `json:"input_tokens"`
+	CachedTokens int64   `json:"cached_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	TotalTokens  int64   `json:"total_tokens"`
+	Requests     int64   `json:"requests"`
+	Cost         float64 `json:"cost"`
+
+	// Buckets holds the model's usage per time bucket, oldest first. It is only
+	// filled when requested (usage --format json --buckets).
+	Buckets []Bucket `json:"buckets,omitempty"`
+}
+
+// Bucket holds a model's usage within one time bucket
+type Bucket struct {
+	StartTime    time.Time `json:"start_time"`
+	EndTime      time.Time `json:"end_time"`
+	InputTokens  int64     `json:"input_tokens"`
+	OutputTokens int64     `json:"output_tokens"`
+	TotalTokens  int64     `json:"total_tokens"`
+	Requests     int64     `json:"requests"`
+	Cost         float64   `json:"cost"`
+}
+
+// UsageTotals holds the totals across all models
+type UsageTotals struct {
+	InputTokens  int64   `json:"input_tokens"`
+	CachedTokens int64   `json:"cached_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	TotalTokens  int64   `json:"total_tokens"`
+	Requests     int64   `json:"requests"`
+	Cost         float64 `json:"cost"`
+	Currency     string  `json:"currency,omitempty"` // empty when there is no cost data
+}
+
+// TotalReport is the JSON document written by `total --format json`.
+// It is a frozen contract for budget scripts (schema version 1): fields may
+// never be renamed, removed, or retyped, and no fields are added.
+type TotalReport struct {
+	Platform      string  `json:"platform"`
+	Period        string  `json:"period"`
+	TotalTokens   int64   `json:"total_tokens"`
+	TotalRequests int64   `json:"total_requests"`
+	TotalCost     float64 `json:"total_cost"`
+	Currency      string  `json:"currency"`
+}
+
+// WriteJSON writes v as a single JSON document followed by a newline.
+// Pretty output is indented with two spaces; otherwise it is a single line.
+func WriteJSON(w io.Writer, v interface{}, pretty bool) error {
+	var (
+		data []byte
+		err  error
+	)
+	if pretty {
+		data, err = json.MarshalIndent(v, "", "  ")
+	} else {
+		data, err = json.Marshal(v)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+
+	if _, err := w.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
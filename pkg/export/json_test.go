@@ -0,0 +1,42 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWriteJSONPrettyAndCompact(t *testing.T) {
+	report := map[string]interface{}{
+		"period": "7d",
+		"totals": map[string]int{"tokens": 150, "requests": 2},
+	}
+
+	var pretty bytes.Buffer
+	if err := WriteJSON(&pretty, report, true); err != nil {
+		t.Fatalf("WriteJSON(pretty): %v", err)
+	}
+	if lines := strings.Count(pretty.String(), "\n"); lines < 5 {
+		t.Errorf("pretty output has %d newlines, want one per field:\n%s", lines, pretty.String())
+	}
+	if !strings.Contains(pretty.String(), "\n  \"totals\": {\n    \"requests\": 2,") {
+		t.Errorf("pretty output is not indented by two spaces per level:\n%s", pretty.String())
+	}
+
+	var compact bytes.Buffer
+	if err := WriteJSON(&compact, report, false); err != nil {
+		t.Fatalf("WriteJSON(compact): %v", err)
+	}
+	body, ok := strings.CutSuffix(compact.String(), "\n")
+	if !ok || strings.ContainsAny(body, "\n ") {
+		t.Errorf("compact output = %q, want a single line without indentation", compact.String())
+	}
+
+	for name, out := range map[string]*bytes.Buffer{"pretty": &pretty, "compact": &compact} {
+		var decoded map[string]interface{}
+		if err := json.Unmarshal(out.Bytes(), &decoded); err != nil {
+			t.Errorf("%s output does not decode: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Record types emitted by the JSON-lines writer
+const (
+	RecordTypeModel   = "model"
+	RecordTypeSummary = "summary"
+)
+
+// ModelRecord represents a single model line in a JSON-lines export
+type ModelRecord struct {
+	Type         string  `json:"type"`
+	Platform     string  `json:"platform"`
+	Model        string  `json:"model"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	TotalTokens  int64   `json:"total_tokens"`
+	Requests     int64   `json:"requests"`
+	Cost         float64 `json:"cost"`
+}
+
+// SummaryRecord represents the trailing summary line in a JSON-lines export
+type SummaryRecord struct {
+	Type          string    `json:"type"`
+	Platform      string    `json:"platform"`
+	Period        string    `json:"period"`
+	StartTime     time.Time `json:"start_time"`
+	EndTime       time.Time `json:"end_time"`
+	Models        int       `json:"models"`
+	InputTokens   int64     `json:"input_tokens"`
+	OutputTokens  int64     `json:"output_tokens"`
+	TotalTokens   int64     `json:"total_tokens"`
+	TotalRequests int64     `json:"total_requests"`
+	TotalCost     float64   `json:"total_cost"`
+	CostStatus    string    `json:"cost_status"` // "billed", "free_tier", or "unavailable"
+}
+
+// JSONLWriter writes records as newline-delimited JSON, one object per line.
+// Each record is encoded straight to the underlying writer, so no enclosing
+// document is built; the records themselves come from usage that has already
+// been fetched and aggregated in full.
+type JSONLWriter struct {
+	encoder *json.Encoder
+	count   int
+}
+
+// NewJSONLWriter creates a new JSON-lines writer on top of w
+func NewJSONLWriter(w io.Writer) *JSONLWriter {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+
+	return &JSONLWriter{
+		encoder: encoder,
+	}
+}
+
+// WriteModel emits a model record
+func (jw *JSONLWriter) WriteModel(record ModelRecord) error {
+	record.Type = RecordTypeModel
+	return jw.write(record)
+}
+
+// WriteSummary emits a summary record
+func (jw *JSONLWriter) WriteSummary(record SummaryRecord) error {
+	record.Type = RecordTypeSummary
+	return jw.write(record)
+}
+
+// Count returns the number of records written so far
+func (jw *JSONLWriter) Count() int {
+	return jw.count
+}
+
+// write encodes a single record followed by a newline
+func (jw *JSONLWriter) write(record interface{}) error {
+	if err := jw.encoder.Encode(record); err != nil {
+		return fmt.Errorf("failed to write JSON line: %w", err)
+	}
+	jw.count++
+	return nil
+}
@@ -0,0 +1,68 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestJSONLWriterLines(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewJSONLWriter(&buf)
+
+	models := []ModelRecord{
+		{Platform: "openai", Model: "gpt-4o", InputTokens: 100, OutputTokens: 50, TotalTokens: 150, Requests: 3, Cost: 1.5},
+		{Platform: "openai", Model: "<script>&\"quoted\"", TotalTokens: 1},
+		{Platform: "openai", Model: "o1\nnewline", Cost: 0.25},
+	}
+	for _, m := range models {
+		if err := w.WriteModel(m); err != nil {
+			t.Fatalf("WriteModel: %v", err)
+		}
+	}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := w.WriteSummary(SummaryRecord{Platform: "openai", Period: "7d", StartTime: start, EndTime: start.AddDate(0, 0, 7), Models: len(models)}); err != nil {
+		t.Fatalf("WriteSummary: %v", err)
+	}
+
+	if got, want := w.Count(), len(models)+1; got != want {
+		t.Errorf("Count() = %d, want %d", got, want)
+	}
+
+	var types []string
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("line %d is not valid JSON: %v\n%s", len(types)+1, err, scanner.Text())
+		}
+		types = append(types, record["type"].(string))
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(types) != len(models)+1 {
+		t.Fatalf("got %d lines, want %d models plus one summary", len(types), len(models))
+	}
+	for i, typ := range types[:len(models)] {
+		if typ != RecordTypeModel {
+			t.Errorf("line %d has type %q, want %q", i+1, typ, RecordTypeModel)
+		}
+	}
+	if last := types[len(types)-1]; last != RecordTypeSummary {
+		t.Errorf("last line has type %q, want %q", last, RecordTypeSummary)
+	}
+}
+
+func TestJSONLWriterDoesNotEscapeHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := NewJSONLWriter(&buf).WriteModel(ModelRecord{Model: "a<b>&c"}); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"model":"a<b>&c"`)) {
+		t.Errorf("model name was escaped: %s", buf.String())
+	}
+}
@@ -0,0 +1,65 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"tokenwatch/pkg/utils"
+)
+
+// pushTimeout bounds how long a single push may take, including retries
+const pushTimeout = 30 * time.Second
+
+// Pusher sends JSON reports to an HTTP endpoint with PUT
+type Pusher struct {
+	url    string
+	token  string
+	client *utils.RateLimitedClient
+}
+
+// NewPusher creates a pusher for url. When token is set it is sent as a bearer token.
+func NewPusher(url, token string) *Pusher {
+	return &Pusher{
+		url:    url,
+		token:  token,
+		client: utils.NewRateLimitedClient(1.0, 1, pushTimeout),
+	}
+}
+
+// Push PUTs v to the endpoint as a JSON document. Any non-2xx response is an error.
+func (p *Pusher) Push(v interface{}) error {
+	var body bytes.Buffer
+	if err := WriteJSON(&body, v, false); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), pushTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, p.url, bytes.NewReader(body.Bytes()))
+	if err != nil {
+		return utils.NewValidationError("push", fmt.Sprintf("invalid push URL: %v", err))
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return utils.NewNetworkError("failed to push report", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// Include the start of the response body to help diagnose the endpoint
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("push failed with status %d: %s", resp.StatusCode, bytes.TrimSpace(snippet))
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package export
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPushPutsReportWithAuth(t *testing.T) {
+	var method, auth, contentType string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		auth = r.Header.Get("Authorization")
+		contentType = r.Header.Get("Content-Type")
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	report := UsageReport{Platform: "openai", Period: "7d", Models: []ModelUsage{{Model: "gpt-4o", TotalTokens: 42, Cost: 1.5}}}
+	if err := NewPusher(server.URL, "secret").Push(report); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+
+	if method != http.MethodPut {
+		t.Errorf("method = %s, want PUT", method)
+	}
+	if auth != "Bearer secret" {
+		t.Errorf("Authorization = %q, want %q", auth, "Bearer secret")
+	}
+	if contentType != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", contentType)
+	}
+	var want bytes.Buffer
+	if err := WriteJSON(&want, report, false); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(body, want.Bytes()) {
+		t.Errorf("body = %s, want the report %s", body, want.Bytes())
+	}
+}
+
+func TestPushWithoutTokenSendsNoAuth(t *testing.T) {
+	sent := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, sent = r.Header["Authorization"]
+	}))
+	defer server.Close()
+
+	if err := NewPusher(server.URL, "").Push(UsageReport{}); err != nil {
+		t.Fatalf("Push: %v", err)
+	}
+	if sent {
+		t.Error("Authorization header sent without a token")
+	}
+}
+
+func TestPushRejectedIncludesResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bucket is read-only", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	err := NewPusher(server.URL, "").Push(UsageReport{})
+	if err == nil || !strings.Contains(err.Error(), "403") || !strings.Contains(err.Error(), "bucket is read-only") {
+		t.Errorf("Push = %v, want an error with the status and response body", err)
+	}
+}
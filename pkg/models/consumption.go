@@ -1,6 +1,7 @@
 package models
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -8,7 +9,10 @@ import (
 type Consumption struct {
 	Platform     string    `json:"platform"`
 	Model        string    `json:"model"`
+	ProjectID    string    `json:"project_id,omitempty"` // only set when usage is grouped by project
+	APIKeyID     string    `json:"api_key_id,omitempty"` // only set when usage is grouped by API key
 	InputTokens  int64     `json:"input_tokens"`
+	CachedTokens int64     `json:"cached_tokens"` // part of InputTokens served from the prompt cache
 	OutputTokens int64     `json:"output_tokens"`
 	TotalTokens  int64     `json:"total_tokens"`
 	RequestCount int64     `json:"request_count"`
@@ -22,6 +26,7 @@ type ConsumptionSummary struct {
 	Platform          string    `json:"platform"`
 	Model             string    `json:"model"`
 	TotalInputTokens  int64     `json:"total_input_tokens"`
+	TotalCachedTokens int64     `json:"total_cached_tokens"`
 	TotalOutputTokens int64     `json:"total_output_tokens"`
 	TotalTokens       int64     `json:"total_tokens"`
 	TotalRequests     int64     `json:"total_requests"`
@@ -30,7 +35,8 @@ type ConsumptionSummary struct {
 	EndTime           time.Time `json:"end_time"`
 }
 
-// NewConsumption creates a new Consumption instance
+// NewConsumption creates a new Consumption instance. CachedTokens starts at
+// zero; providers that report cached input set it afterwards.
 func NewConsumption(platform, model string, inputTokens, outputTokens, requestCount int64, startTime, endTime time.Time) *Consumption {
 	return &Consumption{
 		Platform:     platform,
@@ -59,7 +65,27 @@ func NewConsumptionSummary(platform, model string, period string, startTime, end
 // AddConsumption adds consumption data to the summary
 func (cs *ConsumptionSummary) AddConsumption(consumption *Consumption) {
 	cs.TotalInputTokens += consumption.InputTokens
+	cs.TotalCachedTokens += consumption.CachedTokens
 	cs.TotalOutputTokens += consumption.OutputTokens
 	cs.TotalTokens += consumption.TotalTokens
 	cs.TotalRequests += consumption.RequestCount
 }
+
+// Validate checks that the totals are consistent: token counts are never
+// negative, cached tokens never exceed input tokens, and TotalTokens is the
+// sum of input and output tokens
+func (cs *ConsumptionSummary) Validate() error {
+	if cs.TotalInputTokens < 0 || cs.TotalCachedTokens < 0 || cs.TotalOutputTokens < 0 || cs.TotalRequests < 0 {
+		return fmt.Errorf("consumption summary has negative totals (input %d, cached %d, output %d, requests %d)",
+			cs.TotalInputTokens, cs.TotalCachedTokens, cs.TotalOutputTokens, cs.TotalRequests)
+	}
+	if cs.TotalCachedTokens > cs.TotalInputTokens {
+		return fmt.Errorf("consumption summary has %d cached tokens but only %d input tokens",
+			cs.TotalCachedTokens, cs.TotalInputTokens)
+	}
+	if cs.TotalTokens != cs.TotalInputTokens+cs.TotalOutputTokens {
+		return fmt.Errorf("consumption summary total %d does not match input %d + output %d tokens",
+			cs.TotalTokens, cs.TotalInputTokens, cs.TotalOutputTokens)
+	}
+	return nil
+}
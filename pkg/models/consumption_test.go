@@ -0,0 +1,48 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConsumptionSummaryValidate(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		items   []Consumption
+		wantErr string // empty when the totals reconcile
+	}{
+		{name: "empty"},
+		{name: "matching", items: []Consumption{
+			{InputTokens: 100, CachedTokens: 40, OutputTokens: 50, TotalTokens: 150, RequestCount: 2},
+			{InputTokens: 10, OutputTokens: 5, TotalTokens: 15, RequestCount: 1},
+		}},
+		{name: "total drift", items: []Consumption{
+			{InputTokens: 100, OutputTokens: 50, TotalTokens: 300, RequestCount: 2},
+		}, wantErr: "does not match input 100 + output 50"},
+		{name: "negative", items: []Consumption{
+			{InputTokens: -10, OutputTokens: 5, TotalTokens: -5},
+		}, wantErr: "negative totals"},
+		{name: "cached above input", items: []Consumption{
+			{InputTokens: 10, CachedTokens: 20, OutputTokens: 5, TotalTokens: 15},
+		}, wantErr: "20 cached tokens but only 10 input tokens"},
+	}
+
+	for _, tt := range tests {
+		summary := NewConsumptionSummary("openai", "", "7d", start, start.AddDate(0, 0, 7))
+		for i := range tt.items {
+			summary.AddConsumption(&tt.items[i])
+		}
+		err := summary.Validate()
+		if tt.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s: Validate() = %v, want nil", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: Validate() = %v, want an error containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -1,7 +1,19 @@
 package models
 
 import (
+	"fmt"
+	"math"
+	"strings"
 	"time"
+
+	"tokenwatch/pkg/money"
+)
+
+// Cost status values describing the qualitative state of cost data
+const (
+	CostStatusBilled      = "billed"
+	CostStatusFreeTier    = "free_tier"
+	CostStatusUnavailable = "unavailable"
 )
 
 // Pricing represents cost data from any platform
@@ -26,6 +38,8 @@ type PricingSummary struct {
 	StartTime time.Time `json:"start_time"`
 	EndTime   time.Time `json:"end_time"`
 	LineItems []Pricing `json:"line_items"` // Breakdown by line item
+
+	total money.Amount // exact running total behind TotalCost
 }
 
 // NewPricing creates a new Pricing instance
@@ -56,9 +70,128 @@ func NewPricingSummary(platform, model, period string, startTime, endTime time.T
 
 // AddPricing adds pricing data to the summary
 func (ps *PricingSummary) AddPricing(pricing *Pricing) {
-	ps.TotalCost += pricing.Amount
+	ps.total = ps.total.AddFloat(pricing.Amount)
+	ps.TotalCost = ps.total.Float()
 	if ps.Currency == "" {
 		ps.Currency = pricing.Currency
 	}
 	ps.LineItems = append(ps.LineItems, *pricing)
 }
+
+// costTolerance is the largest drift between a summary total and the sum of
+// its line items that is put down to floating-point rounding
+const costTolerance = 1e-6
+
+// Validate recomputes the total from the line items and returns an error if
+// it has drifted from TotalCost, which points to an aggregation bug
+func (ps *PricingSummary) Validate() error {
+	var total money.Amount
+	for _, item := range ps.LineItems {
+		total = total.AddFloat(item.Amount)
+	}
+	sum := total.Float()
+	if math.Abs(sum-ps.TotalCost) > costTolerance {
+		return fmt.Errorf("pricing summary total %.6f does not match its %d line items (%.6f)", ps.TotalCost, len(ps.LineItems), sum)
+	}
+	return nil
+}
+
+// GrossCost returns the sum of all positive line items, before credits
+func (ps *PricingSummary) GrossCost() float64 {
+	var gross money.Amount
+	for _, item := range ps.LineItems {
+		if item.Amount > 0 {
+			gross = gross.AddFloat(item.Amount)
+		}
+	}
+	return gross.Float()
+}
+
+// Credits returns the sum of all negative line items (refunds, credits, and
+// adjustments) as a negative number. TotalCost is the net of GrossCost and Credits.
+func (ps *PricingSummary) Credits() float64 {
+	var credits money.Amount
+	for _, item := range ps.LineItems {
+		if item.Amount < 0 {
+			credits = credits.AddFloat(item.Amount)
+		}
+	}
+	return credits.Float()
+}
+
+// CostByModel returns the total cost for each model in the summary
+func (ps *PricingSummary) CostByModel() map[string]float64 {
+	sums := make(map[string]money.Amount)
+	for _, item := range ps.LineItems {
+		sums[item.Model] = sums[item.Model].AddFloat(item.Amount)
+	}
+	return floats(sums)
+}
+
+// CostByType returns the total cost for each usage type (e.g. "input", "output",
+// "batch input") parsed from the line item suffix
+func (ps *PricingSummary) CostByType() map[string]float64 {
+	sums := make(map[string]money.Amount)
+	for _, item := range ps.LineItems {
+		usageType := LineItemType(item.LineItem)
+		sums[usageType] = sums[usageType].AddFloat(item.Amount)
+	}
+	return floats(sums)
+}
+
+// floats converts exact sums to float64 for display
+func floats(sums map[string]money.Amount) map[string]float64 {
+	costs := make(map[string]float64, len(sums))
+	for key, sum := range sums {
+		costs[key] = sum.Float()
+	}
+	return costs
+}
+
+// LineItemType extracts the usage type from a line item such as
+// "gpt-4o-2024-08-06, input". Line items without a type suffix are reported as "other".
+func LineItemType(lineItem string) string {
+	idx := strings.LastIndex(lineItem, ", ")
+	if idx < 0 {
+		return "other"
+	}
+	usageType := strings.ToLower(strings.TrimSpace(lineItem[idx+2:]))
+	if usageType == "" {
+		return "other"
+	}
+	return usageType
+}
+
+// CostDirection classifies a usage type from LineItemType as "input" (including
+// cached and batch input), "output", or "other"
+func CostDirection(usageType string) string {
+	switch {
+	case strings.Contains(usageType, "input"):
+		return "input"
+	case strings.Contains(usageType, "output"):
+		return "output"
+	default:
+		return "other"
+	}
+}
+
+// CostLagDays returns how many whole days the latest cost bucket trails the
+// latest usage bucket. It returns 0 when either set is empty or costs are current.
+func CostLagDays(consumptions []*Consumption, pricings []*Pricing) int {
+	var latestUsage, latestCost time.Time
+	for _, c := range consumptions {
+		if c.StartTime.After(latestUsage) {
+			latestUsage = c.StartTime
+		}
+	}
+	for _, p := range pricings {
+		if p.StartTime.After(latestCost) {
+			latestCost = p.StartTime
+		}
+	}
+
+	if latestUsage.IsZero() || latestCost.IsZero() || !latestUsage.After(latestCost) {
+		return 0
+	}
+	return int(latestUsage.Sub(latestCost).Hours() / 24)
+}
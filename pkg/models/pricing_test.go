@@ -0,0 +1,86 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newSummary returns a pricing summary holding one line item per amount
+func newSummary(amounts ...float64) *PricingSummary {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	summary := NewPricingSummary("openai", "", "7d", start, start.AddDate(0, 0, 7))
+	for _, amount := range amounts {
+		summary.AddPricing(NewPricing("openai", "gpt-4o", "gpt-4o, input", amount, "usd", start, start.AddDate(0, 0, 1)))
+	}
+	return summary
+}
+
+func TestPricingSummaryValidate(t *testing.T) {
+	// Many small items sum exactly, so the total reconciles
+	amounts := make([]float64, 1000)
+	for i := range amounts {
+		amounts[i] = 0.001
+	}
+	summary := newSummary(amounts...)
+	if err := summary.Validate(); err != nil {
+		t.Errorf("Validate() on a consistent summary = %v", err)
+	}
+
+	// A total that drifted from its line items is reported
+	summary.TotalCost += 0.01
+	err := summary.Validate()
+	if err == nil || !strings.Contains(err.Error(), "does not match its 1000 line items") {
+		t.Errorf("Validate() on a drifted summary = %v, want a mismatch error", err)
+	}
+}
+
+func TestPricingSummaryCreditsSplit(t *testing.T) {
+	summary := newSummary(12.5, -2.25, 0.75, -0.5)
+
+	if got := summary.GrossCost(); got != 13.25 {
+		t.Errorf("GrossCost() = %v, want 13.25", got)
+	}
+	if got := summary.Credits(); got != -2.75 {
+		t.Errorf("Credits() = %v, want -2.75", got)
+	}
+	if got := summary.TotalCost; got != 10.5 {
+		t.Errorf("TotalCost = %v, want the net 10.5", got)
+	}
+
+	// Without negative items there are no credits and gross equals net
+	summary = newSummary(1.25, 2)
+	if summary.Credits() != 0 || summary.GrossCost() != summary.TotalCost {
+		t.Errorf("gross %v, credits %v, net %v; want no credits", summary.GrossCost(), summary.Credits(), summary.TotalCost)
+	}
+}
+
+func TestPricingSummaryCostBreakdowns(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	summary := NewPricingSummary("openai", "", "7d", start, start.AddDate(0, 0, 7))
+	items := []struct {
+		model, lineItem string
+		amount          float64
+	}{
+		{"gpt-4o", "gpt-4o-2024-08-06, input", 1.5},
+		{"gpt-4o", "gpt-4o-2024-08-06, output", 3},
+		{"gpt-4o-mini", "gpt-4o-mini, input", 0.25},
+		{"gpt-4o-mini", "gpt-4o-mini, batch output", 0.1},
+		{"gpt-4o", "gpt-4o, input", 0.5},
+		{"", "Web search", 0.2},
+	}
+	for _, item := range items {
+		summary.AddPricing(NewPricing("openai", item.model, item.lineItem, item.amount, "usd", start, start.AddDate(0, 0, 1)))
+	}
+
+	wantByModel := map[string]float64{"gpt-4o": 5, "gpt-4o-mini": 0.35, "": 0.2}
+	if got := summary.CostByModel(); !reflect.DeepEqual(got, wantByModel) {
+		t.Errorf("CostByModel() = %v, want %v", got, wantByModel)
+	}
+
+	wantByType := map[string]float64{"input": 2.25, "output": 3, "batch output": 0.1, "other": 0.2}
+	if got := summary.CostByType(); !reflect.DeepEqual(got, wantByType) {
+		t.Errorf("CostByType() = %v, want %v", got, wantByType)
+	}
+}
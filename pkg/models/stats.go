@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// ModelStats holds aggregated usage and cost for a single model
+type ModelStats struct {
+	Model        string  `json:"model"`
+	InputTokens  int64   `json:"input_tokens"`
+	CachedTokens int64   `json:"cached_tokens"` // part of InputTokens served from the prompt cache
+	OutputTokens int64   `json:"output_tokens"`
+	TotalTokens  int64   `json:"total_tokens"`
+	Requests     int64   `json:"requests"`
+	Cost         float64 `json:"cost"`
+	Currency     string  `json:"currency,omitempty"` // currency of Cost, empty when there is no cost data
+
+	// InputCost and OutputCost split Cost by line item type. Line items that
+	// are neither (see OtherCost) are only counted in Cost.
+	InputCost  float64 `json:"input_cost,omitempty"`
+	OutputCost float64 `json:"output_cost,omitempty"`
+}
+
+// OtherCost returns the part of Cost that is neither input nor output
+func (m ModelStats) OtherCost() float64 {
+	return m.Cost - m.InputCost - m.OutputCost
+}
+
+// TotalStats holds the totals across all models
+type TotalStats struct {
+	TotalInput    int64   `json:"input_tokens"`
+	TotalCached   int64   `json:"cached_tokens"`
+	TotalOutput   int64   `json:"output_tokens"`
+	TotalTokens   int64   `json:"total_tokens"`
+	TotalRequests int64   `json:"requests"`
+	TotalCost     float64 `json:"cost"`
+	Currency      string  `json:"currency,omitempty"`
+
+	TotalInputCost  float64 `json:"input_cost,omitempty"`
+	TotalOutputCost float64 `json:"output_cost,omitempty"`
+}
+
+// OtherCost returns the part of TotalCost that is neither input nor output
+func (t TotalStats) OtherCost() float64 {
+	return t.TotalCost - t.TotalInputCost - t.TotalOutputCost
+}
+
+// UsageGroup holds aggregated usage for one combination of grouping values,
+// e.g. a model within a project
+type UsageGroup struct {
+	Values       []string `json:"values"` // one per grouping dimension, in the order requested
+	InputTokens  int64    `json:"input_tokens"`
+	OutputTokens int64    `json:"output_tokens"`
+	TotalTokens  int64    `json:"total_tokens"`
+	Requests     int64    `json:"requests"`
+}
+
+// BucketStats holds usage and cost for a single model within one time bucket
+type BucketStats struct {
+	StartTime    time.Time `json:"start_time"`
+	EndTime      time.Time `json:"end_time"`
+	Model        string    `json:"model"`
+	InputTokens  int64     `json:"input_tokens"`
+	OutputTokens int64     `json:"output_tokens"`
+	TotalTokens  int64     `json:"total_tokens"`
+	Requests     int64     `json:"requests"`
+	Cost         float64   `json:"cost"`
+}
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"encoding/json"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+var snakeCase = regexp.MustCompile(`^[a-z]+(_[a-z]+)*$`)
+
+// jsonKeys marshals v and returns its top-level keys, sorted
+func jsonKeys(t *testing.T, v interface{}) []string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestStatsJSONFieldNames(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{
+			name: "ModelStats",
+			v:    ModelStats{Model: "gpt-4o", InputTokens: 1, CachedTokens: 1, OutputTokens: 1, TotalTokens: 2, Requests: 1, Cost: 1, Currency: "usd", InputCost: 0.5, OutputCost: 0.5},
+			want: "cached_tokens,cost,currency,input_cost,input_tokens,model,output_cost,output_tokens,requests,total_tokens",
+		},
+		{
+			name: "TotalStats",
+			v:    TotalStats{TotalInput: 1, TotalCached: 1, TotalOutput: 1, TotalTokens: 2, TotalRequests: 1, TotalCost: 1, Currency: "usd", TotalInputCost: 0.5, TotalOutputCost: 0.5},
+			want: "cached_tokens,cost,currency,input_cost,input_tokens,output_cost,output_tokens,requests,total_tokens",
+		},
+		{
+			name: "UsageGroup",
+			v:    UsageGroup{Values: []string{"gpt-4o"}, InputTokens: 1},
+			want: "input_tokens,output_tokens,requests,total_tokens,values",
+		},
+		{
+			name: "BucketStats",
+			v:    BucketStats{StartTime: now, EndTime: now, Model: "gpt-4o"},
+			want: "cost,end_time,input_tokens,model,output_tokens,requests,start_time,total_tokens",
+		},
+	}
+	for _, tt := range tests {
+		keys := jsonKeys(t, tt.v)
+		for _, k := range keys {
+			if !snakeCase.MatchString(k) {
+				t.Errorf("%s: field %q is not snake_case", tt.name, k)
+			}
+		}
+		if got := strings.Join(keys, ","); got != tt.want {
+			t.Errorf("%s: fields = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStatsJSONOmitsEmptyCostDetail(t *testing.T) {
+	keys := strings.Join(jsonKeys(t, ModelStats{Model: "gpt-4o"}), ",")
+	for _, omitted := range []string{"currency", "input_cost", "output_cost"} {
+		if strings.Contains(keys, omitted) {
+			t.Errorf("zero %s should be omitted, got fields %s", omitted, keys)
+		}
+	}
+}
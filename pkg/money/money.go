@@ -0,0 +1,87 @@
+// Package money sums currency amounts exactly. Costs arrive from the APIs as
+// float64, and adding thousands of them in floating point leaves trailing
+// digits such as 30.000000000000263. Amounts are instead held as whole
+// billionths of a currency unit and only converted back to float64 for display.
+package money
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Amount is a currency amount in billionths of a unit (nano-dollars for USD).
+// int64 holds about ±9.2 billion units at this scale.
+type Amount int64
+
+// unitsPerWhole is the number of Amount units in one currency unit
+const unitsPerWhole = 1_000_000_000
+
+// FromFloat converts f to the nearest Amount
+func FromFloat(f float64) Amount {
+	return Amount(math.Round(f * unitsPerWhole))
+}
+
+// Float returns a as a float64, for display and JSON output
+func (a Amount) Float() float64 {
+	return float64(a) / unitsPerWhole
+}
+
+// Add returns a + b
+func (a Amount) Add(b Amount) Amount {
+	return a + b
+}
+
+// AddFloat returns a plus f converted with FromFloat
+func (a Amount) AddFloat(f float64) Amount {
+	return a + FromFloat(f)
+}
+
+// Sum adds values exactly and returns the total as a float64
+func Sum(values ...float64) float64 {
+	var total Amount
+	for _, v := range values {
+		total = total.AddFloat(v)
+	}
+	return total.Float()
+}
+
+// Format returns a with the given number of decimals, rounding half away
+// from zero, e.g. "12.3457" for 4 decimals. decimals is capped at 9.
+func (a Amount) Format(decimals int) string {
+	if decimals < 0 {
+		decimals = 0
+	}
+	if decimals > 9 {
+		decimals = 9
+	}
+
+	negative := a < 0
+	// Work on the magnitude in uint64 so math.MinInt64 doesn't overflow
+	magnitude := uint64(a)
+	if negative {
+		magnitude = -magnitude
+	}
+
+	step := uint64(math.Pow10(9 - decimals))
+	rounded := (magnitude + step/2) / step
+	scale := uint64(math.Pow10(decimals))
+
+	var b strings.Builder
+	if negative && rounded != 0 {
+		b.WriteByte('-')
+	}
+	b.WriteString(strconv.FormatUint(rounded/scale, 10))
+	if decimals > 0 {
+		frac := strconv.FormatUint(rounded%scale, 10)
+		b.WriteByte('.')
+		b.WriteString(strings.Repeat("0", decimals-len(frac)))
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+// String formats a with 4 decimals, the precision costs are displayed at
+func (a Amount) String() string {
+	return a.Format(4)
+}
@@ -0,0 +1,107 @@
+package money
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFromFloat(t *testing.T) {
+	tests := []struct {
+		f    float64
+		want Amount
+	}{
+		{0, 0},
+		{1, 1_000_000_000},
+		{0.1, 100_000_000},
+		{-2.5, -2_500_000_000},
+		{0.0000000004, 0},   // below half a unit rounds to zero
+		{0.0000000006, 1},   // above half a unit rounds up
+		{-0.0000000006, -1}, // and away from zero when negative
+		{123.456789123, 123_456_789_123},
+	}
+	for _, tt := range tests {
+		if got := FromFloat(tt.f); got != tt.want {
+			t.Errorf("FromFloat(%v) = %d, want %d", tt.f, got, tt.want)
+		}
+	}
+}
+
+func TestSumHasNoFloatingPointDrift(t *testing.T) {
+	tenths := make([]float64, 10)
+	for i := range tenths {
+		tenths[i] = 0.1
+	}
+	// Adding 0.1 ten times in float64 gives 0.9999999999999999
+	var naive float64
+	for _, v := range tenths {
+		naive += v
+	}
+	if naive == 1 {
+		t.Fatal("expected float64 addition to drift; the test no longer shows anything")
+	}
+	if got := Sum(tenths...); got != 1 {
+		t.Errorf("Sum of ten 0.1s = %v, want exactly 1", got)
+	}
+
+	thousand := make([]float64, 3000)
+	for i := range thousand {
+		thousand[i] = 0.01
+	}
+	if got := Sum(thousand...); got != 30 {
+		t.Errorf("Sum of 3000 0.01s = %v, want exactly 30", got)
+	}
+}
+
+func TestSumOfNothing(t *testing.T) {
+	if got := Sum(); got != 0 {
+		t.Errorf("Sum() = %v, want 0", got)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	a := FromFloat(0.1).Add(FromFloat(0.2))
+	if a != FromFloat(0.3) {
+		t.Errorf("0.1 + 0.2 = %d units, want %d", a, FromFloat(0.3))
+	}
+	if got := a.Float(); got != 0.3 {
+		t.Errorf("(0.1 + 0.2).Float() = %v, want 0.3", got)
+	}
+	if got := FromFloat(1.25).AddFloat(-1.5); got != FromFloat(-0.25) {
+		t.Errorf("1.25 + -1.5 = %d units, want %d", got, FromFloat(-0.25))
+	}
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		amount   Amount
+		decimals int
+		want     string
+	}{
+		{FromFloat(12.345678), 4, "12.3457"},
+		{FromFloat(12.345678), 2, "12.35"},
+		{FromFloat(12.345678), 0, "12"},
+		{FromFloat(12.5), 0, "13"}, // half rounds away from zero
+		{FromFloat(0.125), 2, "0.13"},
+		{FromFloat(0.5), 9, "0.500000000"},
+		{FromFloat(0.5), 12, "0.500000000"}, // capped at 9
+		{FromFloat(2.75), -1, "3"},          // negative treated as 0
+		{FromFloat(0.00005), 4, "0.0001"},
+		{FromFloat(0.00004), 4, "0.0000"},
+		{0, 2, "0.00"},
+		{FromFloat(-12.345678), 4, "-12.3457"},
+		{FromFloat(-12.5), 0, "-13"},
+		{FromFloat(-0.00004), 4, "0.0000"}, // no "-0.0000"
+		{Amount(math.MinInt64), 0, "-9223372037"},
+	}
+	for _, tt := range tests {
+		if got := tt.amount.Format(tt.decimals); got != tt.want {
+			t.Errorf("Amount(%d).Format(%d) = %q, want %q", tt.amount, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	if got := FromFloat(3.14159).String(); got != "3.1416" {
+		t.Errorf("String() = %q, want 3.1416", got)
+	}
+}
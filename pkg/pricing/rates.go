@@ -0,0 +1,98 @@
+package pricing
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Rate holds the list price for a model in USD per one million tokens
+type Rate struct {
+	Model       string  `json:"model"`
+	InputPer1M  float64 `json:"input_per_1m"`
+	OutputPer1M float64 `json:"output_per_1m"`
+}
+
+// Cost computes the cost in USD for the given token counts at this rate
+func (r Rate) Cost(inputTokens, outputTokens int64) float64 {
+	return (float64(inputTokens)*r.InputPer1M + float64(outputTokens)*r.OutputPer1M) / 1_000_000
+}
+
+// RateTable maps model names to their rates
+type RateTable struct {
+	rates map[string]Rate
+}
+
+// defaultRates are OpenAI list prices for standard (non-batch) usage
+var defaultRates = []Rate{
+	{Model: "gpt-4o", InputPer1M: 2.50, OutputPer1M: 10.00},
+	{Model: "gpt-4o-mini", InputPer1M: 0.15, OutputPer1M: 0.60},
+	{Model: "gpt-4.1", InputPer1M: 2.00, OutputPer1M: 8.00},
+	{Model: "gpt-4.1-mini", InputPer1M: 0.40, OutputPer1M: 1.60},
+	{Model: "gpt-4.1-nano", InputPer1M: 0.10, OutputPer1M: 0.40},
+	{Model: "gpt-4-turbo", InputPer1M: 10.00, OutputPer1M: 30.00},
+	{Model: "gpt-4", InputPer1M: 30.00, OutputPer1M: 60.00},
+	{Model: "gpt-3.5-turbo", InputPer1M: 0.50, OutputPer1M: 1.50},
+	{Model: "o1", InputPer1M: 15.00, OutputPer1M: 60.00},
+	{Model: "o1-mini", InputPer1M: 1.10, OutputPer1M: 4.40},
+	{Model: "o3", InputPer1M: 2.00, OutputPer1M: 8.00},
+	{Model: "o3-mini", InputPer1M: 1.10, OutputPer1M: 4.40},
+	{Model: "o4-mini", InputPer1M: 1.10, OutputPer1M: 4.40},
+}
+
+// NewRateTable creates a rate table from the given rates
+func NewRateTable(rates []Rate) *RateTable {
+	table := &RateTable{
+		rates: make(map[string]Rate, len(rates)),
+	}
+	for _, rate := range rates {
+		table.rates[rate.Model] = rate
+	}
+	return table
+}
+
+// DefaultRateTable returns the built-in rate table
+func DefaultRateTable() *RateTable {
+	return NewRateTable(defaultRates)
+}
+
+// Lookup returns the rate for a model. Dated snapshots such as
+// "gpt-4o-2024-08-06" resolve to the longest matching base model.
+func (t *RateTable) Lookup(model string) (Rate, error) {
+	if rate, ok := t.rates[model]; ok {
+		return rate, nil
+	}
+
+	var best Rate
+	found := false
+	for name, rate := range t.rates {
+		if strings.HasPrefix(model, name+"-") && len(name) > len(best.Model) {
+			best = rate
+			found = true
+		}
+	}
+	if found {
+		return best, nil
+	}
+
+	return Rate{}, fmt.Errorf("unknown model %q. Supported models: %s", model, strings.Join(t.Models(), ", "))
+}
+
+// Models returns the sorted list of models in the table
+func (t *RateTable) Models() []string {
+	names := make([]string, 0, len(t.rates))
+	for name := range t.rates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Estimate computes the projected cost in USD for a single call to the model
+func (t *RateTable) Estimate(model string, inputTokens, outputTokens int64) (float64, error) {
+	rate, err := t.Lookup(model)
+	if err != nil {
+		return 0, err
+	}
+	return rate.Cost(inputTokens, outputTokens), nil
+}
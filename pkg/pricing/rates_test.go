@@ -0,0 +1,83 @@
+package pricing
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// approx reports whether a and b are equal to within floating point rounding
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-12
+}
+
+func TestRateCost(t *testing.T) {
+	rate := Rate{Model: "gpt-4o", InputPer1M: 2.50, OutputPer1M: 10.00}
+	tests := []struct {
+		input, output int64
+		want          float64
+	}{
+		{input: 1_000_000, output: 0, want: 2.50},
+		{input: 0, output: 1_000_000, want: 10.00},
+		{input: 1000, output: 500, want: 0.0025 + 0.005},
+		{input: 0, output: 0, want: 0},
+	}
+	for _, tt := range tests {
+		if got := rate.Cost(tt.input, tt.output); !approx(got, tt.want) {
+			t.Errorf("Cost(%d, %d) = %v, want %v", tt.input, tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	table := DefaultRateTable()
+
+	got, err := table.Estimate("gpt-4o-mini", 2000, 300)
+	if err != nil {
+		t.Fatalf("Estimate: %v", err)
+	}
+	// 2,000 input at $0.15/1M plus 300 output at $0.60/1M
+	if want := 0.0003 + 0.00018; !approx(got, want) {
+		t.Errorf("Estimate = %v, want %v", got, want)
+	}
+}
+
+func TestLookupResolvesSnapshots(t *testing.T) {
+	table := DefaultRateTable()
+	tests := map[string]string{
+		"gpt-4o":                 "gpt-4o",
+		"gpt-4o-2024-08-06":      "gpt-4o",
+		"gpt-4o-mini-2024-07-18": "gpt-4o-mini", // longest base wins over gpt-4o
+		"o3-mini-2025-01-31":     "o3-mini",
+	}
+	for model, want := range tests {
+		rate, err := table.Lookup(model)
+		if err != nil {
+			t.Errorf("Lookup(%q): %v", model, err)
+			continue
+		}
+		if rate.Model != want {
+			t.Errorf("Lookup(%q) priced as %q, want %q", model, rate.Model, want)
+		}
+	}
+}
+
+func TestLookupUnknownModelListsModels(t *testing.T) {
+	table := NewRateTable([]Rate{{Model: "b-model"}, {Model: "a-model"}})
+
+	_, err := table.Lookup("gpt-unknown")
+	if err == nil {
+		t.Fatal("expected an error for an unknown model")
+	}
+	if !strings.Contains(err.Error(), `"gpt-unknown"`) {
+		t.Errorf("error %q does not name the model", err)
+	}
+	if !strings.Contains(err.Error(), "Supported models: a-model, b-model") {
+		t.Errorf("error %q does not list the supported models in order", err)
+	}
+
+	// A name that only shares a prefix without the dash separator is not a snapshot
+	if _, err := table.Lookup("a-modelx"); err == nil {
+		t.Error("a-modelx resolved to a-model")
+	}
+}
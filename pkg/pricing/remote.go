@@ -0,0 +1,106 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"tokenwatch/pkg/cache"
+	"tokenwatch/pkg/utils"
+)
+
+// RemoteRates is the JSON document served at pricing.source_url:
+//
+//	{"rates": [{"model": "gpt-4o", "input_per_1m": 2.5, "output_per_1m": 10}]}
+//
+// Listed models override the bundled rates; models not listed keep them.
+type RemoteRates struct {
+	Rates []Rate `json:"rates"`
+}
+
+// RemoteRefreshInterval is how long fetched rates are cached before the source is asked again
+const RemoteRefreshInterval = 24 * time.Hour
+
+// remoteFetchTimeout bounds a single rate fetch, including retries
+const remoteFetchTimeout = 15 * time.Second
+
+// maxRemoteBytes caps the size of a rate document
+const maxRemoteBytes = 1 << 20
+
+// FetchRates downloads and validates the rate document at url
+func FetchRates(url string) ([]Rate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), remoteFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, utils.NewValidationError("pricing.source_url", fmt.Sprintf("invalid URL: %v", err))
+	}
+	req.Header.Set("Accept", "application/json")
+
+	client := utils.NewRateLimitedClient(1.0, 1, remoteFetchTimeout)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, utils.NewNetworkError("failed to fetch rates", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("rate source returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rates: %w", err)
+	}
+	if len(data) > maxRemoteBytes {
+		return nil, fmt.Errorf("rate document exceeds %d bytes", maxRemoteBytes)
+	}
+
+	var doc RemoteRates
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to decode rates: %w", err)
+	}
+	if len(doc.Rates) == 0 {
+		return nil, fmt.Errorf("rate document lists no rates")
+	}
+	for _, rate := range doc.Rates {
+		if rate.Model == "" {
+			return nil, fmt.Errorf("rate document has an entry without a model")
+		}
+		if rate.InputPer1M < 0 || rate.OutputPer1M < 0 {
+			return nil, fmt.Errorf("rate for %s is negative", rate.Model)
+		}
+	}
+	return doc.Rates, nil
+}
+
+// LoadRateTable returns the bundled rates overridden by the rates at sourceURL.
+// Fetched rates are cached in cacheDir for RemoteRefreshInterval. An empty
+// sourceURL returns DefaultRateTable; when the fetch fails the bundled table is
+// returned together with the error, so callers can warn and carry on.
+func LoadRateTable(sourceURL, cacheDir string) (*RateTable, error) {
+	if sourceURL == "" {
+		return DefaultRateTable(), nil
+	}
+
+	key := "rates:" + sourceURL
+	var rates []Rate
+	if !cache.Load(cacheDir, key, time.Now(), &rates) {
+		var err error
+		rates, err = FetchRates(sourceURL)
+		if err != nil {
+			return DefaultRateTable(), err
+		}
+		if err := cache.Store(cacheDir, key, rates, RemoteRefreshInterval); err != nil {
+			utils.Debug("Failed to cache fetched rates", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	merged := append(append([]Rate(nil), defaultRates...), rates...)
+	return NewRateTable(merged), nil
+}
@@ -0,0 +1,99 @@
+package pricing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// rateServer serves body with status and counts the requests it gets
+func rateServer(t *testing.T, status int, body string) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestLoadRateTableOverridesBundledRates(t *testing.T) {
+	server, requests := rateServer(t, http.StatusOK, `{"rates": [
+		{"model": "gpt-4o", "input_per_1m": 1.25, "output_per_1m": 5},
+		{"model": "new-model", "input_per_1m": 3, "output_per_1m": 12}
+	]}`)
+	cacheDir := t.TempDir()
+
+	table, err := LoadRateTable(server.URL, cacheDir)
+	if err != nil {
+		t.Fatalf("LoadRateTable: %v", err)
+	}
+	rate, err := table.Lookup("gpt-4o")
+	if err != nil || rate.InputPer1M != 1.25 || rate.OutputPer1M != 5 {
+		t.Errorf("gpt-4o = %+v, %v; want the fetched 1.25/5 rate", rate, err)
+	}
+	if _, err := table.Lookup("new-model"); err != nil {
+		t.Errorf("fetched model missing: %v", err)
+	}
+	// Models the source doesn't list keep their bundled rate
+	bundled, _ := DefaultRateTable().Lookup("gpt-4o-mini")
+	if rate, err := table.Lookup("gpt-4o-mini"); err != nil || rate != bundled {
+		t.Errorf("gpt-4o-mini = %+v, %v; want the bundled %+v", rate, err, bundled)
+	}
+
+	// A second load within the refresh interval is served from the cache
+	if _, err := LoadRateTable(server.URL, cacheDir); err != nil {
+		t.Fatalf("LoadRateTable: %v", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("made %d requests, want 1 with the second load cached", got)
+	}
+}
+
+func TestLoadRateTableFallsBackToBundledRates(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{"malformed JSON", http.StatusOK, `{"rates": [`, "failed to decode rates"},
+		{"no rates", http.StatusOK, `{"rates": []}`, "lists no rates"},
+		{"missing model", http.StatusOK, `{"rates": [{"input_per_1m": 1}]}`, "without a model"},
+		{"negative rate", http.StatusOK, `{"rates": [{"model": "gpt-4o", "input_per_1m": -1}]}`, "negative"},
+		{"not found", http.StatusNotFound, `{}`, "status 404"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, _ := rateServer(t, tt.status, tt.body)
+			cacheDir := t.TempDir()
+
+			table, err := LoadRateTable(server.URL, cacheDir)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+			if table == nil {
+				t.Fatal("expected the bundled table alongside the error")
+			}
+			bundled, _ := DefaultRateTable().Lookup("gpt-4o")
+			if rate, err := table.Lookup("gpt-4o"); err != nil || rate != bundled {
+				t.Errorf("gpt-4o = %+v, %v; want the bundled %+v", rate, err, bundled)
+			}
+		})
+	}
+}
+
+func TestLoadRateTableWithoutSource(t *testing.T) {
+	table, err := LoadRateTable("", t.TempDir())
+	if err != nil {
+		t.Fatalf("LoadRateTable: %v", err)
+	}
+	if got, want := len(table.Models()), len(DefaultRateTable().Models()); got != want {
+		t.Errorf("table has %d models, want the %d bundled ones", got, want)
+	}
+}
@@ -0,0 +1,140 @@
+package providers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"tokenwatch/pkg/utils"
+)
+
+// FixturesEnv names the environment variable that points the OpenAI provider at
+// a directory of recorded API responses instead of the network
+const FixturesEnv = "TOKENWATCH_FIXTURES"
+
+// FixtureTransport is an http.RoundTripper that serves recorded API responses
+// from a directory, for reproducible demos and regression checks.
+//
+// A request for /v1/organization/usage/completions grouped by model is served
+// from the first file that exists of:
+//
+//	usage_completions.model.json
+//	usage_completions.json
+//
+// A page token adds ".page-<token>" before ".json". Buckets outside the requested
+// start_time/end_time are dropped, so one fixture can serve any period.
+type FixtureTransport struct {
+	Dir string
+}
+
+// RoundTrip serves the fixture for req, or a 404 naming the files it looked for
+func (t *FixtureTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	query := req.URL.Query()
+
+	candidates := fixtureNames(req.URL.Path, query["group_by"], query.Get("page"))
+	for _, name := range candidates {
+		data, err := os.ReadFile(filepath.Join(t.Dir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture %s: %w", name, err)
+		}
+
+		filtered, err := filterFixtureBuckets(data, query.Get("start_time"), query.Get("end_time"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid fixture %s: %w", name, err)
+		}
+		return fixtureResponse(req, http.StatusOK, filtered), nil
+	}
+
+	message := fmt.Sprintf("no fixture in %s (tried %s)", t.Dir, strings.Join(candidates, ", "))
+	utils.Warn("Fixture not found", map[string]interface{}{
+		"dir":   t.Dir,
+		"tried": strings.Join(candidates, ", "),
+	})
+	body, _ := json.Marshal(OpenAIErrorResponse{Error: OpenAIErrorDetail{Message: message}})
+	return fixtureResponse(req, http.StatusNotFound, body), nil
+}
+
+// fixtureNames returns the fixture file names for a request, most specific first
+func fixtureNames(path string, groupBy []string, page string) []string {
+	path = strings.TrimPrefix(path, "/v1")
+	path = strings.TrimPrefix(path, "/organization")
+	base := strings.ReplaceAll(strings.Trim(path, "/"), "/", "_")
+
+	suffix := ".json"
+	if page != "" {
+		suffix = ".page-" + page + suffix
+	}
+
+	var names []string
+	if len(groupBy) > 0 {
+		names = append(names, base+"."+strings.Join(groupBy, ".")+suffix)
+	}
+	return append(names, base+suffix)
+}
+
+// filterFixtureBuckets drops buckets from a recorded page that start outside
+// [start, end). Missing bounds leave that side open.
+func filterFixtureBuckets(data []byte, start, end string) ([]byte, error) {
+	var page map[string]json.RawMessage
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, err
+	}
+
+	var buckets []json.RawMessage
+	if raw, ok := page["data"]; ok {
+		if err := json.Unmarshal(raw, &buckets); err != nil {
+			return nil, err
+		}
+	}
+
+	startUnix, _ := strconv.ParseInt(start, 10, 64)
+	endUnix, _ := strconv.ParseInt(end, 10, 64)
+
+	kept := make([]json.RawMessage, 0, len(buckets))
+	for _, raw := range buckets {
+		var bucket struct {
+			StartTime int64 `json:"start_time"`
+		}
+		if err := json.Unmarshal(raw, &bucket); err != nil {
+			return nil, err
+		}
+		if start != "" && bucket.StartTime < startUnix {
+			continue
+		}
+		if end != "" && bucket.StartTime >= endUnix {
+			continue
+		}
+		kept = append(kept, raw)
+	}
+
+	keptData, err := json.Marshal(kept)
+	if err != nil {
+		return nil, err
+	}
+	page["data"] = keptData
+	return json.Marshal(page)
+}
+
+// fixtureResponse builds a JSON response for req
+func fixtureResponse(req *http.Request, status int, body []byte) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
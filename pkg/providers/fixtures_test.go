@@ -0,0 +1,148 @@
+package providers
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFixtureNames(t *testing.T) {
+	tests := []struct {
+		path    string
+		groupBy []string
+		page    string
+		want    string
+	}{
+		{"/v1/organization/usage/completions", []string{"model"}, "", "usage_completions.model.json,usage_completions.json"},
+		{"/v1/organization/usage/completions", nil, "", "usage_completions.json"},
+		{"/v1/organization/costs", []string{"line_item"}, "p2", "costs.line_item.page-p2.json,costs.page-p2.json"},
+		{"/v1/organization/usage/completions", []string{"model", "project_id"}, "", "usage_completions.model.project_id.json,usage_completions.json"},
+	}
+	for _, tt := range tests {
+		if got := strings.Join(fixtureNames(tt.path, tt.groupBy, tt.page), ","); got != tt.want {
+			t.Errorf("fixtureNames(%q, %v, %q) = %s, want %s", tt.path, tt.groupBy, tt.page, got, tt.want)
+		}
+	}
+}
+
+func TestFilterFixtureBuckets(t *testing.T) {
+	page := `{"object":"page","data":[{"start_time":100},{"start_time":200},{"start_time":300}],"has_more":false}`
+	tests := []struct {
+		start, end string
+		want       []int64
+	}{
+		{"", "", []int64{100, 200, 300}},
+		{"200", "", []int64{200, 300}},
+		{"", "300", []int64{100, 200}},
+		{"150", "250", []int64{200}},
+		{"400", "500", []int64{}},
+	}
+	for _, tt := range tests {
+		filtered, err := filterFixtureBuckets([]byte(page), tt.start, tt.end)
+		if err != nil {
+			t.Fatalf("filterFixtureBuckets: %v", err)
+		}
+		var resp struct {
+			Object string `json:"object"`
+			Data   []struct {
+				StartTime int64 `json:"start_time"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(filtered, &resp); err != nil {
+			t.Fatalf("filtered page is invalid JSON: %v", err)
+		}
+		if resp.Object != "page" {
+			t.Errorf("other fields were dropped: %s", filtered)
+		}
+		got := make([]int64, len(resp.Data))
+		for i, b := range resp.Data {
+			got[i] = b.StartTime
+		}
+		if jsonString(got) != jsonString(tt.want) {
+			t.Errorf("[%s, %s) kept %v, want %v", tt.start, tt.end, got, tt.want)
+		}
+	}
+
+	if _, err := filterFixtureBuckets([]byte("not json"), "", ""); err == nil {
+		t.Error("expected an error for an invalid fixture")
+	}
+}
+
+func jsonString(v interface{}) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func TestFixtureTransportMissingFixture(t *testing.T) {
+	transport := &FixtureTransport{Dir: t.TempDir()}
+	req, _ := http.NewRequest("GET", "https://api.openai.com/v1/organization/costs?group_by=line_item", nil)
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", resp.StatusCode)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "costs.line_item.json") {
+		t.Errorf("error body %s doesn't name the fixture files tried", body)
+	}
+}
+
+// newFixtureProvider returns a provider serving testdata/fixtures
+func newFixtureProvider(t *testing.T) *OpenAIProvider {
+	t.Helper()
+	p := NewOpenAIProvider("sk-test", "")
+	p.UseFixtures("testdata/fixtures")
+	p.SetRateLimit(1000, 1000)
+	t.Cleanup(func() { p.SetRateLimit(DefaultOpenAIRateLimit, DefaultOpenAIBurst) })
+	p.circuitBreaker.Reset()
+	t.Cleanup(p.circuitBreaker.Reset)
+	return p
+}
+
+func TestProviderRunsAgainstFixtures(t *testing.T) {
+	p := newFixtureProvider(t)
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	// Both usage pages are read, and the bucket before start is filtered out
+	consumptions, err := p.GetConsumption(start, end, true, false)
+	if err != nil {
+		t.Fatalf("GetConsumption: %v", err)
+	}
+	var tokens, cached, requests int64
+	for _, c := range consumptions {
+		tokens += c.TotalTokens
+		cached += c.CachedTokens
+		requests += c.RequestCount
+	}
+	if len(consumptions) != 3 || tokens != 4600 || cached != 200 || requests != 35 {
+		t.Errorf("got %d records with %d tokens (%d cached) over %d requests; want 3 with 4600 (200) over 35",
+			len(consumptions), tokens, cached, requests)
+	}
+
+	pricings, err := p.GetPricing(start, end, true, false)
+	if err != nil {
+		t.Fatalf("GetPricing: %v", err)
+	}
+	var cost float64
+	models := make(map[string]bool)
+	for _, pr := range pricings {
+		cost += pr.Amount
+		models[pr.Model] = true
+	}
+	if len(pricings) != 4 || cost < 2.009 || cost > 2.011 {
+		t.Errorf("got %d cost records totalling %v, want 4 totalling 2.01", len(pricings), cost)
+	}
+	for _, model := range []string{"gpt-4o-2024-08-06", "gpt-4o-mini", "gpt-4o"} {
+		if !models[model] {
+			t.Errorf("no cost record for %s in %v", model, models)
+		}
+	}
+}
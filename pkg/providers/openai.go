@@ -2,33 +2,79 @@ package providers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"tokenwatch/pkg/cache"
 	"tokenwatch/pkg/models"
+	"tokenwatch/pkg/money"
 	"tokenwatch/pkg/utils"
 )
 
 // OpenAIProvider handles API calls to OpenAI
 type OpenAIProvider struct {
-	client         *utils.RateLimitedClient
-	circuitBreaker *utils.CircuitBreaker
-	apiKey         string
-	baseURL        string
-	orgID          string
-	cache          map[string]cacheItem
-	cacheTTL       time.Duration
+	client           *utils.RateLimitedClient
+	circuitBreaker   *utils.CircuitBreaker
+	apiKey           string
+	baseURL          string
+	orgID            string
+	projectID        string
+	cacheMu          sync.Mutex
+	cache            map[string]cacheItem
+	cacheTTL         time.Duration
+	cacheDir         string        // disk cache shared across runs; empty keeps the cache in memory only
+	maxCacheAge      time.Duration // 0 accepts any entry that hasn't expired
+	maxConcurrency   int
+	failFast         bool // cancel the remaining chunk fetches once one fails
+	maxPages         int  // 0 means no cap
+	maxResponseBytes int64
+	emptyPageRetries int
+	extraHeaders     map[string]string
+	icons            func(name string) string // debug output icons; nil uses defaultIcon
+	costInCents      bool
+	cacheDays        bool // reuse cached completed days even when bypassing the cache
+
+	// Fetch counters, read via FetchStats
+	pagesFetched atomic.Int64
+	cacheHits    atomic.Int64
+	cacheMisses  atomic.Int64
+	bytesRead    atomic.Int64
+}
+
+// FetchStats counts the API pages and cache lookups behind a provider's results
+type FetchStats struct {
+	Pages       int64 // API response pages decoded, including re-requested pages
+	CacheHits   int64
+	CacheMisses int64
+	BytesRead   int64 // response body bytes decoded
 }
 
 // cacheItem represents a cached API response
 type cacheItem struct {
 	data      interface{}
+	storedAt  time.Time
 	expiresAt time.Time
 }
 
+// diskCacheItem is the JSON form of a cacheItem in the disk cache. Expiry is
+// kept by the cache.Entry wrapping it. Exactly one response is set.
+type diskCacheItem struct {
+	StoredAt time.Time            `json:"stored_at"`
+	Usage    *OpenAIUsageResponse `json:"usage,omitempty"`
+	Costs    *OpenAICostResponse  `json:"costs,omitempty"`
+}
+
 // OpenAIUsageResponse represents the usage API response
 type OpenAIUsageResponse struct {
 	Data     []OpenAIUsageBucket `json:"data"`
@@ -44,7 +90,10 @@ type OpenAIUsageBucket struct {
 
 type OpenAIUsageResult struct {
 	Model            string `json:"model"`
+	ProjectID        string `json:"project_id"` // only set when grouped by project_id
+	APIKeyID         string `json:"api_key_id"` // only set when grouped by api_key_id
 	InputTokens      int64  `json:"input_tokens"`
+	CachedTokens     int64  `json:"input_cached_tokens"` // part of InputTokens billed at the cached input rate
 	OutputTokens     int64  `json:"output_tokens"`
 	NumModelRequests int64  `json:"num_model_requests"`
 }
@@ -63,35 +112,91 @@ type OpenAICostBucket struct {
 }
 
 type OpenAICostResult struct {
-	LineItem string           `json:"line_item"`
-	Amount   OpenAICostAmount `json:"amount"`
+	LineItem  string           `json:"line_item"`
+	ProjectID string           `json:"project_id"` // only set when grouped by project_id
+	Amount    OpenAICostAmount `json:"amount"`
 }
 
 type OpenAICostAmount struct {
 	Value    float64 `json:"value"`
 	Currency string  `json:"currency"`
+	Unit     string  `json:"unit,omitempty"` // "cents" when Value is in minor units
+}
+
+// Dollars returns the amount in major currency units. Values are taken as
+// dollars unless the payload marks them as cents or inCents is set.
+func (a OpenAICostAmount) Dollars(inCents bool) float64 {
+	switch strings.ToLower(a.Unit) {
+	case "cents", "cent", "minor":
+		return a.Value / 100
+	case "":
+		if inCents {
+			return a.Value / 100
+		}
+	}
+	return a.Value
+}
+
+// usageChunkDays is the longest range fetched from the usage API in a single
+// paginated request; longer ranges are split into chunks of this many days
+const usageChunkDays = 90
+
+// costChunkDays is the same limit for the costs API. At the default page size a
+// chunk needs far fewer pages than maxPages, so long periods such as "all" are
+// fetched in full rather than cut off by the page cap.
+const costChunkDays = 90
+
+// emptyPageRetryDelay is the pause before re-requesting a page that reported
+// has_more without a next_page token
+var emptyPageRetryDelay = 500 * time.Millisecond
+
+// maxBuckets caps the number of buckets accumulated by a single paginated fetch
+const maxBuckets = 10000
+
+// ErrResponseTooLarge is returned when the API returns more data than the configured limits allow
+var ErrResponseTooLarge = errors.New("response exceeds size limit")
+
+// OpenAIErrorResponse represents the error body returned by the API
+type OpenAIErrorResponse struct {
+	Error OpenAIErrorDetail `json:"error"`
 }
 
+type OpenAIErrorDetail struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Param   string `json:"param"`
+	Code    string `json:"code"`
+}
+
+// openAIHost keys the rate limiter and circuit breaker shared by all OpenAI providers
+const openAIHost = "api.openai.com"
+
 // NewOpenAIProvider creates a new OpenAI provider instance
 func NewOpenAIProvider(apiKey, orgID string) *OpenAIProvider {
 	// Default cache TTL of 5 minutes for normal operations
 	cacheTTL := 5 * time.Minute
 
 	// Rate limiting: OpenAI has various rate limits, using conservative defaults
-	// 60 requests per minute = 1 request per second with burst of 5
-	rateLimitedClient := utils.NewRateLimitedClient(1.0, 5, 30*time.Second)
+	// 60 requests per minute = 1 request per second with burst of 5. The limiter
+	// is shared by every OpenAI provider in the process.
+	rateLimitedClient := utils.NewHostRateLimitedClient(openAIHost, DefaultOpenAIRateLimit, DefaultOpenAIBurst, 30*time.Second)
 
-	// Circuit breaker: Open after 5 consecutive failures, reset after 1 minute
-	circuitBreaker := utils.NewCircuitBreaker(5, 1*time.Minute)
+	// Circuit breaker: Open after 5 consecutive failures, reset after 1 minute.
+	// Shared like the limiter, so every provider sees when OpenAI is failing.
+	circuitBreaker := utils.HostCircuitBreaker(openAIHost, 5, 1*time.Minute)
 
 	return &OpenAIProvider{
-		client:         rateLimitedClient,
-		circuitBreaker: circuitBreaker,
-		apiKey:         apiKey,
-		baseURL:        "https://api.openai.com/v1",
-		orgID:          orgID,
-		cache:          make(map[string]cacheItem),
-		cacheTTL:       cacheTTL,
+		client:           rateLimitedClient,
+		circuitBreaker:   circuitBreaker,
+		apiKey:           apiKey,
+		baseURL:          "https://api.openai.com/v1",
+		orgID:            orgID,
+		cache:            make(map[string]cacheItem),
+		cacheTTL:         cacheTTL,
+		maxConcurrency:   DefaultMaxConcurrency,
+		maxPages:         DefaultMaxPages,
+		maxResponseBytes: DefaultMaxResponseBytes,
+		emptyPageRetries: DefaultEmptyPageRetries,
 	}
 }
 
@@ -105,14 +210,230 @@ func (o *OpenAIProvider) IsAvailable() bool {
 	return o.apiKey != ""
 }
 
-// ClearCache clears all cached data
+// SetTimeouts configures the connection-level timeouts used for API requests
+func (o *OpenAIProvider) SetTimeouts(timeouts utils.TimeoutConfig) {
+	o.client.SetTimeouts(timeouts)
+}
+
+// EnableCircuitPersistence persists the circuit breaker state to path across runs
+func (o *OpenAIProvider) EnableCircuitPersistence(path string) error {
+	return o.circuitBreaker.EnablePersistence(path)
+}
+
+// ClientStats returns the retries and wait times accumulated by the HTTP client
+func (o *OpenAIProvider) ClientStats() utils.ClientStats {
+	return o.client.Stats()
+}
+
+// FetchStats returns the pages, cache lookups and bytes accumulated by the provider
+func (o *OpenAIProvider) FetchStats() FetchStats {
+	return FetchStats{
+		Pages:       o.pagesFetched.Load(),
+		CacheHits:   o.cacheHits.Load(),
+		CacheMisses: o.cacheMisses.Load(),
+		BytesRead:   o.bytesRead.Load(),
+	}
+}
+
+// SetRateLimit overrides the default request rate (requests per second) and
+// burst. Values of zero or less fall back to the OpenAI defaults.
+func (o *OpenAIProvider) SetRateLimit(requestsPerSecond float64, burst int) {
+	if requestsPerSecond <= 0 {
+		requestsPerSecond = DefaultOpenAIRateLimit
+	}
+	if burst <= 0 {
+		burst = DefaultOpenAIBurst
+	}
+	o.client.SetRateLimit(requestsPerSecond, burst)
+}
+
+// RateLimit returns the request rate (requests per second) and burst in use
+func (o *OpenAIProvider) RateLimit() (float64, int) {
+	return o.client.RateLimit()
+}
+
+// SetMaxConcurrency limits how many requests the provider fetches in parallel.
+// The shared rate limiter still governs the actual request pacing.
+func (o *OpenAIProvider) SetMaxConcurrency(n int) {
+	if n < 1 {
+		n = 1
+	}
+	o.maxConcurrency = n
+}
+
+// SetFailFast makes a failing chunk of a long range cancel the chunks still
+// being fetched. By default every chunk is fetched before the first error is
+// returned.
+func (o *OpenAIProvider) SetFailFast(enabled bool) {
+	o.failFast = enabled
+}
+
+// SetMaxResponseBytes caps the total number of response bytes read by a single
+// paginated fetch. Values below 1 keep the current limit.
+func (o *OpenAIProvider) SetMaxResponseBytes(n int64) {
+	if n > 0 {
+		o.maxResponseBytes = n
+	}
+}
+
+// SetMaxPages sets the cap on pages requested by a single paginated fetch.
+// 0 removes the cap: pagination then stops only when the API has no more pages,
+// a cursor repeats, or the response size and bucket limits are reached.
+// Negative values are ignored.
+func (o *OpenAIProvider) SetMaxPages(n int) {
+	if n >= 0 {
+		o.maxPages = n
+	}
+}
+
+// SetEmptyPageRetries sets how many times a page that reports has_more without a
+// next_page token is re-requested. Some gateways return this transiently.
+func (o *OpenAIProvider) SetEmptyPageRetries(n int) {
+	if n < 0 {
+		n = 0
+	}
+	o.emptyPageRetries = n
+}
+
+// SetMaxCacheAge makes cached responses older than maxAge count as misses, so
+// they are fetched again even within the cache TTL. Zero removes the limit.
+func (o *OpenAIProvider) SetMaxCacheAge(maxAge time.Duration) {
+	o.maxCacheAge = maxAge
+}
+
+// SetProject scopes every request to a single project via the OpenAI-Project
+// header. An empty ID reports on the whole organization.
+func (o *OpenAIProvider) SetProject(projectID string) {
+	o.projectID = projectID
+}
+
+// SetExtraHeaders adds headers (e.g. for an API gateway) to every request.
+// The standard Authorization and OpenAI-Organization headers always take precedence.
+func (o *OpenAIProvider) SetExtraHeaders(headers map[string]string) {
+	o.extraHeaders = headers
+}
+
+// UseFixtures serves every request from recorded responses in dir instead of
+// the network. See FixtureTransport for the file layout.
+func (o *OpenAIProvider) UseFixtures(dir string) {
+	o.client.SetTransport(&FixtureTransport{Dir: dir})
+}
+
+// SetIcons sets how icons in debug output are rendered. fn receives a semantic
+// name ("check" or "warning") and returns the icon followed by a space, or ""
+// to drop it.
+func (o *OpenAIProvider) SetIcons(fn func(name string) string) {
+	o.icons = fn
+}
+
+// defaultIcon renders the debug output icons as emoji
+func defaultIcon(name string) string {
+	switch name {
+	case "check":
+		return "🔍 "
+	case "warning":
+		return "⚠️  "
+	}
+	return ""
+}
+
+// icon returns the named debug output icon
+func (o *OpenAIProvider) icon(name string) string {
+	if o.icons == nil {
+		return defaultIcon(name)
+	}
+	return o.icons(name)
+}
+
+// SetCostInCents treats cost amounts without an explicit unit as cents rather
+// than dollars
+func (o *OpenAIProvider) SetCostInCents(enabled bool) {
+	o.costInCents = enabled
+}
+
+// setHeaders applies the extra headers followed by the standard auth headers
+func (o *OpenAIProvider) setHeaders(req *http.Request) {
+	utils.ApplyHeaders(req, o.extraHeaders)
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", o.apiKey))
+	if o.orgID != "" {
+		req.Header.Set("OpenAI-Organization", o.orgID)
+	}
+	if o.projectID != "" {
+		req.Header.Set("OpenAI-Project", o.projectID)
+	}
+}
+
+// SetCacheTTL sets how long API responses are cached. Values of zero or less
+// keep the current TTL.
+func (o *OpenAIProvider) SetCacheTTL(ttl time.Duration) {
+	if ttl > 0 {
+		o.cacheTTL = ttl
+	}
+}
+
+// EnableDiskCache also stores API responses in dir, so later runs can reuse
+// them until they expire. Daily data is stored one completed UTC day at a
+// time (see useDayCache), so rolling periods reuse the days they share.
+func (o *OpenAIProvider) EnableDiskCache(dir string) {
+	o.cacheDir = dir
+}
+
+// SetCacheCompletedDays caches daily usage and costs one completed UTC day at
+// a time and reuses those days even when the cache is bypassed, so watch mode
+// refreshes only refetch the current day and the partial day at the start of
+// the range
+func (o *OpenAIProvider) SetCacheCompletedDays(enabled bool) {
+	o.cacheDays = enabled
+}
+
+// ClearCache clears all cached data held in memory
 func (o *OpenAIProvider) ClearCache() {
+	o.cacheMu.Lock()
+	defer o.cacheMu.Unlock()
 	o.cache = make(map[string]cacheItem)
 }
 
+// Bucket widths requested from the usage and costs endpoints. The costs API
+// only supports daily buckets, so cost granularity is capped at 1d whatever
+// width usage is fetched at.
+const (
+	UsageBucketWidth = "1d"
+	CostBucketWidth  = "1d"
+)
+
 // GetConsumption retrieves consumption data and converts to common models
 func (o *OpenAIProvider) GetConsumption(startTime, endTime time.Time, bypassCache bool, debug bool) ([]*models.Consumption, error) {
-	usageResp, err := o.GetUsage(startTime, endTime, "1d", []string{"model"}, bypassCache, debug)
+	return o.GetConsumptionContext(context.Background(), startTime, endTime, bypassCache, debug)
+}
+
+// GetConsumptionContext is GetConsumption with requests made under ctx
+func (o *OpenAIProvider) GetConsumptionContext(ctx context.Context, startTime, endTime time.Time, bypassCache bool, debug bool) ([]*models.Consumption, error) {
+	return o.consumptionGrouped(ctx, startTime, endTime, []string{GroupByModel}, bypassCache, debug)
+}
+
+// Usage grouping dimensions accepted by GetConsumptionGrouped, as named by
+// the usage API's group_by parameter
+const (
+	GroupByModel   = "model"
+	GroupByProject = "project_id"
+	GroupByAPIKey  = "api_key_id"
+)
+
+// GetConsumptionGrouped retrieves usage broken down by the groupBy dimensions.
+// Fields for dimensions that weren't requested are left empty.
+func (o *OpenAIProvider) GetConsumptionGrouped(startTime, endTime time.Time, groupBy []string, bypassCache bool, debug bool) ([]*models.Consumption, error) {
+	return o.consumptionGrouped(context.Background(), startTime, endTime, groupBy, bypassCache, debug)
+}
+
+// consumptionGrouped is GetConsumptionGrouped with requests made under ctx
+func (o *OpenAIProvider) consumptionGrouped(ctx context.Context, startTime, endTime time.Time, groupBy []string, bypassCache bool, debug bool) ([]*models.Consumption, error) {
+	for _, group := range groupBy {
+		if group != GroupByModel && group != GroupByProject && group != GroupByAPIKey {
+			return nil, utils.NewValidationError("group-by", fmt.Sprintf("unsupported usage grouping: %s", group))
+		}
+	}
+
+	usageResp, err := o.getUsage(ctx, startTime, endTime, UsageBucketWidth, groupBy, bypassCache, debug)
 	if err != nil {
 		return nil, err
 	}
@@ -129,6 +450,9 @@ func (o *OpenAIProvider) GetConsumption(startTime, endTime time.Time, bypassCach
 				time.Unix(bucket.StartTime, 0),
 				time.Unix(bucket.EndTime, 0),
 			)
+			consumption.CachedTokens = result.CachedTokens
+			consumption.ProjectID = result.ProjectID
+			consumption.APIKeyID = result.APIKeyID
 			consumptions = append(consumptions, consumption)
 		}
 	}
@@ -136,24 +460,78 @@ func (o *OpenAIProvider) GetConsumption(startTime, endTime time.Time, bypassCach
 	return consumptions, nil
 }
 
+// GetTotals retrieves only the grand totals for a time range. Usage and costs
+// are requested without any grouping, so each daily bucket holds a single
+// result and far fewer pages are needed than for the per-model breakdown.
+func (o *OpenAIProvider) GetTotals(startTime, endTime time.Time, bypassCache bool, debug bool) (models.TotalStats, error) {
+	var totals models.TotalStats
+
+	usageResp, err := o.GetUsage(startTime, endTime, UsageBucketWidth, nil, bypassCache, debug)
+	if err != nil {
+		return totals, err
+	}
+	for _, bucket := range usageResp.Data {
+		for _, result := range bucket.Results {
+			totals.TotalInput += result.InputTokens
+			totals.TotalOutput += result.OutputTokens
+			totals.TotalRequests += result.NumModelRequests
+		}
+	}
+	totals.TotalTokens = totals.TotalInput + totals.TotalOutput
+
+	costResp, err := o.GetCosts(startTime, endTime, nil, bypassCache, debug)
+	if err != nil {
+		return totals, err
+	}
+	var totalCost money.Amount
+	for _, bucket := range costResp.Data {
+		for _, result := range bucket.Results {
+			// Amounts in different currencies can't be summed
+			currency := strings.ToUpper(strings.TrimSpace(result.Amount.Currency))
+			if currency != "" && totals.Currency != "" && currency != totals.Currency {
+				return totals, fmt.Errorf("cost data mixes currencies (%s and %s), which can't be summed", totals.Currency, currency)
+			}
+			if currency != "" {
+				totals.Currency = currency
+			}
+			totalCost = totalCost.AddFloat(result.Amount.Dollars(o.costInCents))
+		}
+	}
+	totals.TotalCost = totalCost.Float()
+
+	return totals, nil
+}
+
 // GetPricing retrieves pricing data and converts to common models
 func (o *OpenAIProvider) GetPricing(startTime, endTime time.Time, bypassCache bool, debug bool) ([]*models.Pricing, error) {
-	costResp, err := o.GetCosts(startTime, endTime, []string{"line_item"}, bypassCache, debug)
+	return o.GetPricingContext(context.Background(), startTime, endTime, bypassCache, debug)
+}
+
+// GetPricingContext is GetPricing with requests made under ctx
+func (o *OpenAIProvider) GetPricingContext(ctx context.Context, startTime, endTime time.Time, bypassCache bool, debug bool) ([]*models.Pricing, error) {
+	costResp, err := o.getCosts(ctx, startTime, endTime, []string{"line_item"}, bypassCache, debug)
 	if err != nil {
 		return nil, err
 	}
 
 	var pricings []*models.Pricing
+	unrecognized := make(map[string]bool) // line items already logged
 	for _, bucket := range costResp.Data {
 		for _, result := range bucket.Results {
-			// Extract model from line item (e.g., "gpt-4o-input" -> "gpt-4o")
-			model := o.extractModelFromLineItem(result.LineItem)
+			// Extract model from line item (e.g., "gpt-4o, input" -> "gpt-4o")
+			model, ok := o.extractModelFromLineItem(result.LineItem)
+			if !ok && !unrecognized[result.LineItem] {
+				unrecognized[result.LineItem] = true
+				utils.Debug("Unrecognized cost line item format, using it as the model label", map[string]interface{}{
+					"line_item": result.LineItem,
+				})
+			}
 
 			pricing := models.NewPricing(
 				o.GetPlatform(),
 				model,
 				result.LineItem,
-				result.Amount.Value,
+				result.Amount.Dollars(o.costInCents),
 				result.Amount.Currency,
 				time.Unix(bucket.StartTime, 0),
 				time.Unix(bucket.EndTime, 0),
@@ -165,9 +543,38 @@ func (o *OpenAIProvider) GetPricing(startTime, endTime time.Time, bypassCache bo
 	return pricings, nil
 }
 
+// NoProjectID labels costs that are not attributed to a project
+const NoProjectID = "(no project)"
+
+// GetProjectCosts retrieves costs grouped by project ID
+func (o *OpenAIProvider) GetProjectCosts(startTime, endTime time.Time, bypassCache bool, debug bool) (map[string]float64, error) {
+	costResp, err := o.GetCosts(startTime, endTime, []string{"project_id"}, bypassCache, debug)
+	if err != nil {
+		return nil, err
+	}
+
+	sums := make(map[string]money.Amount)
+	for _, bucket := range costResp.Data {
+		for _, result := range bucket.Results {
+			// Results without the dimension (e.g. org-level charges) are grouped together
+			projectID := result.ProjectID
+			if strings.TrimSpace(projectID) == "" {
+				projectID = NoProjectID
+			}
+			sums[projectID] = sums[projectID].AddFloat(result.Amount.Dollars(o.costInCents))
+		}
+	}
+
+	costs := make(map[string]float64, len(sums))
+	for projectID, sum := range sums {
+		costs[projectID] = sum.Float()
+	}
+	return costs, nil
+}
+
 // GetConsumptionSummary gets aggregated consumption data for common periods
 func (o *OpenAIProvider) GetConsumptionSummary(period string) (*models.ConsumptionSummary, error) {
-	startTime, endTime := GetPeriodTimeRange(period)
+	startTime, endTime := GetPeriodTimeRange(period, false)
 
 	consumptions, err := o.GetConsumption(startTime, endTime, false, false)
 	if err != nil {
@@ -196,7 +603,7 @@ func (o *OpenAIProvider) GetConsumptionSummary(period string) (*models.Consumpti
 
 // GetPricingSummary gets aggregated pricing data for common periods
 func (o *OpenAIProvider) GetPricingSummary(period string) (*models.PricingSummary, error) {
-	startTime, endTime := GetPeriodTimeRange(period)
+	startTime, endTime := GetPeriodTimeRange(period, false)
 
 	pricings, err := o.GetPricing(startTime, endTime, false, false)
 	if err != nil {
@@ -223,28 +630,72 @@ func (o *OpenAIProvider) GetPricingSummary(period string) (*models.PricingSummar
 	return models.NewPricingSummary(o.GetPlatform(), "", period, startTime, endTime), nil
 }
 
-// extractModelFromLineItem extracts the model name from OpenAI's line item format
-func (o *OpenAIProvider) extractModelFromLineItem(lineItem string) string {
-	// OpenAI line items format: "model, type" e.g., "gpt-4o-2024-08-06, input"
-	// We want to extract just the model name
-	parts := strings.Split(lineItem, ", ")
-	if len(parts) > 0 {
-		return parts[0]
+// extractModelFromLineItem extracts the model name from OpenAI's line item
+// format, "model, type" (e.g. "gpt-4o-2024-08-06, input") or a bare model
+// name. Any other format is returned whole with ok set to false, so new
+// formats show up under their own label instead of a misparsed model.
+func (o *OpenAIProvider) extractModelFromLineItem(lineItem string) (string, bool) {
+	lineItem = strings.TrimSpace(lineItem)
+	if lineItem == "" {
+		// No model at all; aggregation labels it UnknownModel
+		return "", true
+	}
+	model, usageType, found := strings.Cut(lineItem, ", ")
+	if !found {
+		return lineItem, isModelID(lineItem)
 	}
-	return lineItem
+	if !isModelID(model) || strings.TrimSpace(usageType) == "" || strings.Contains(usageType, ", ") {
+		return lineItem, false
+	}
+	return model, true
+}
+
+// isModelID reports whether s looks like a model identifier such as
+// "gpt-4o-2024-08-06" or "ft:gpt-4o-mini:acme::abc123": non-empty, no spaces
+func isModelID(s string) bool {
+	return s != "" && !strings.ContainsAny(s, " \t,")
 }
 
 // getCacheKey generates a cache key for the given request parameters
 func (o *OpenAIProvider) getCacheKey(endpoint string, params map[string]string) string {
-	key := endpoint
-	for k, v := range params {
-		key += fmt.Sprintf(":%s=%s", k, v)
+	// The disk cache is shared by every account used on this machine, so keys
+	// name the account by a fingerprint of its API key
+	sum := sha256.Sum256([]byte(o.apiKey))
+	key := endpoint + ":account=" + hex.EncodeToString(sum[:6])
+	if o.orgID != "" {
+		key += ":org=" + o.orgID
+	}
+	if o.projectID != "" {
+		key += ":project=" + o.projectID
+	}
+	// Sorted so the same request always maps to the same key
+	names := make([]string, 0, len(params))
+	for k := range params {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		key += fmt.Sprintf(":%s=%s", k, params[k])
 	}
 	return key
 }
 
-// getFromCache attempts to retrieve data from cache
+// getFromCache attempts to retrieve data from cache, counting the hit or miss.
+// The disk cache is consulted when the memory cache misses.
 func (o *OpenAIProvider) getFromCache(key string, result interface{}) bool {
+	if o.lookupCache(key, result) || o.lookupDiskCache(key, result) {
+		o.cacheHits.Add(1)
+		return true
+	}
+	o.cacheMisses.Add(1)
+	return false
+}
+
+// lookupCache copies a fresh cache entry for key into result
+func (o *OpenAIProvider) lookupCache(key string, result interface{}) bool {
+	o.cacheMu.Lock()
+	defer o.cacheMu.Unlock()
+
 	item, found := o.cache[key]
 	if !found {
 		return false
@@ -256,6 +707,12 @@ func (o *OpenAIProvider) getFromCache(key string, result interface{}) bool {
 		return false
 	}
 
+	// Entries older than the max age are skipped but kept, since they're still
+	// valid for runs without the limit
+	if o.maxCacheAge > 0 && time.Since(item.storedAt) > o.maxCacheAge {
+		return false
+	}
+
 	// Copy cached data to result
 	switch data := item.data.(type) {
 	case *OpenAIUsageResponse:
@@ -273,12 +730,111 @@ func (o *OpenAIProvider) getFromCache(key string, result interface{}) bool {
 	return false
 }
 
-// saveToCache stores data in cache
-func (o *OpenAIProvider) saveToCache(key string, data interface{}) {
+// lookupDiskCache copies an unexpired disk cache entry for key into result
+// and loads it into the memory cache
+func (o *OpenAIProvider) lookupDiskCache(key string, result interface{}) bool {
+	if o.cacheDir == "" {
+		return false
+	}
+
+	var item diskCacheItem
+	now := time.Now()
+	if !cache.Load(o.cacheDir, key, now, &item) {
+		return false
+	}
+	if o.maxCacheAge > 0 && now.Sub(item.StoredAt) > o.maxCacheAge {
+		return false
+	}
+
+	var data interface{}
+	switch resp := result.(type) {
+	case **OpenAIUsageResponse:
+		if item.Usage == nil {
+			return false
+		}
+		*resp = item.Usage
+		data = item.Usage
+	case **OpenAICostResponse:
+		if item.Costs == nil {
+			return false
+		}
+		*resp = item.Costs
+		data = item.Costs
+	default:
+		return false
+	}
+
+	o.cacheMu.Lock()
+	o.cache[key] = cacheItem{
+		data:      data,
+		storedAt:  item.StoredAt,
+		expiresAt: item.StoredAt.Add(o.cacheTTL),
+	}
+	o.cacheMu.Unlock()
+	return true
+}
+
+// saveToCache stores data in cache for the range ending at end. Only ranges
+// that ended before today's UTC midnight are written through to the disk
+// cache: a range ending later has a key that changes every run, so it could
+// never be reused and would only accumulate files.
+func (o *OpenAIProvider) saveToCache(key string, data interface{}, end time.Time) {
+	now := time.Now()
+	o.cacheMu.Lock()
 	o.cache[key] = cacheItem{
 		data:      data,
-		expiresAt: time.Now().Add(o.cacheTTL),
+		storedAt:  now,
+		expiresAt: now.Add(o.cacheTTL),
+	}
+	o.cacheMu.Unlock()
+
+	if o.cacheDir == "" || end.IsZero() || end.After(timeNow().UTC().Truncate(24*time.Hour)) {
+		return
+	}
+	item := diskCacheItem{StoredAt: now}
+	switch resp := data.(type) {
+	case *OpenAIUsageResponse:
+		item.Usage = resp
+	case *OpenAICostResponse:
+		item.Costs = resp
+	default:
+		return
 	}
+	if err := cache.Store(o.cacheDir, key, item, o.cacheTTL); err != nil {
+		utils.Debug("Failed to write disk cache entry", map[string]interface{}{
+			"error": err.Error(),
+		})
+	}
+}
+
+// newBadRequestError converts a 400 response body into a validation error that
+// names the flag responsible for the offending parameter
+func newBadRequestError(body io.Reader) error {
+	var errResp OpenAIErrorResponse
+	if err := json.NewDecoder(body).Decode(&errResp); err != nil || errResp.Error.Message == "" {
+		return newStatusError(http.StatusBadRequest)
+	}
+
+	var se *utils.StructuredError
+	detail := errResp.Error
+	switch detail.Param {
+	case "start_time", "end_time":
+		se = utils.NewValidationError("period", fmt.Sprintf("period/bucket produced an invalid %s: %s", detail.Param, detail.Message))
+	case "bucket_width":
+		se = utils.NewValidationError("period", fmt.Sprintf("period/bucket produced an invalid bucket_width: %s", detail.Message))
+	case "":
+		se = utils.NewValidationError("request", detail.Message)
+	default:
+		se = utils.NewValidationError(detail.Param, detail.Message)
+	}
+	se.Context["status_code"] = http.StatusBadRequest
+	return se
+}
+
+// newStatusError converts an unsuccessful response status into an API error
+// that carries the status, see utils.StructuredError.StatusCode
+func newStatusError(status int) error {
+	return utils.NewAPIError(fmt.Sprintf("API request failed with status: %d", status), status, nil)
 }
 
 // countTotalResults counts the total number of results across all buckets
@@ -301,183 +857,315 @@ func countTotalCostResults(buckets []OpenAICostBucket) int {
 
 // GetUsage retrieves token usage data from OpenAI (internal method)
 func (o *OpenAIProvider) GetUsage(startTime, endTime time.Time, bucketWidth string, groupBy []string, bypassCache bool, debug bool) (*OpenAIUsageResponse, error) {
-	// Create cache key
-	params := map[string]string{
-		"start_time":   fmt.Sprintf("%d", startTime.Unix()),
-		"end_time":     fmt.Sprintf("%d", endTime.Unix()),
-		"bucket_width": bucketWidth,
-	}
-	for i, group := range groupBy {
-		params[fmt.Sprintf("group_by_%d", i)] = group
+	return o.getUsage(context.Background(), startTime, endTime, bucketWidth, groupBy, bypassCache, debug)
+}
+
+// getUsage is GetUsage with requests made under ctx
+func (o *OpenAIProvider) getUsage(ctx context.Context, startTime, endTime time.Time, bucketWidth string, groupBy []string, bypassCache bool, debug bool) (*OpenAIUsageResponse, error) {
+	if o.useDayCache(bypassCache) && bucketWidth == UsageBucketWidth && !endTime.IsZero() {
+		if days := completedDays(startTime, endTime, timeNow()); len(days) > 0 {
+			resp, err := o.getByDay(ctx, "usage_day", startTime, endTime, days, groupBy, debug, func(key string) (dayResponse, bool) {
+				var resp *OpenAIUsageResponse
+				ok := o.getFromCache(key, &resp)
+				return resp, ok
+			}, func(ctx context.Context, start, end time.Time) (dayResponse, bool, error) {
+				return o.fetchUsage(ctx, start, end, UsageBucketWidth, groupBy, true, debug)
+			})
+			if err != nil {
+				return nil, err
+			}
+			return resp.(*OpenAIUsageResponse), nil
+		}
 	}
-	cacheKey := o.getCacheKey("usage", params)
+	resp, _, err := o.fetchUsage(ctx, startTime, endTime, bucketWidth, groupBy, bypassCache, debug)
+	return resp, err
+}
 
-	// Try to get from cache (unless bypassing)
-	if !bypassCache {
-		var result *OpenAIUsageResponse
-		if o.getFromCache(cacheKey, &result) {
-			return result, nil
+// useDayCache reports whether daily data should be cached one completed UTC
+// day at a time. That is the case when completed days are reused even while
+// bypassing the cache (watch mode), or when the disk cache is in use: a rolling
+// period such as 7d has a new range on every run, but the days it covers
+// that have ended are the same.
+func (o *OpenAIProvider) useDayCache(bypassCache bool) bool {
+	return o.cacheDays || (o.cacheDir != "" && !bypassCache)
+}
+
+// dayResponse is a usage or costs response as handled by the day cache
+type dayResponse interface {
+	// splitByDay groups the buckets under the UTC day they start on, keyed by
+	// its unix time, each as a response of the same type
+	splitByDay() map[int64]dayResponse
+	// join appends the buckets of other, a response of the same type
+	join(other dayResponse)
+	// empty returns a response of the same type without buckets
+	empty() dayResponse
+}
+
+func (r *OpenAIUsageResponse) splitByDay() map[int64]dayResponse {
+	days := make(map[int64]dayResponse)
+	for _, bucket := range r.Data {
+		day := time.Unix(bucket.StartTime, 0).UTC().Truncate(24 * time.Hour).Unix()
+		if days[day] == nil {
+			days[day] = &OpenAIUsageResponse{}
 		}
+		resp := days[day].(*OpenAIUsageResponse)
+		resp.Data = append(resp.Data, bucket)
 	}
+	return days
+}
 
-	// Not in cache or bypassing cache, make API request with pagination
-	var allData []OpenAIUsageBucket
-	var nextPage string
-	maxPages := 50 // Safety limit to prevent infinite loops
-	pageCount := 0
-	seenPages := make(map[string]bool) // Track seen pages to detect loops
+func (r *OpenAIUsageResponse) join(other dayResponse) {
+	r.Data = append(r.Data, other.(*OpenAIUsageResponse).Data...)
+}
 
-	for pageCount < maxPages {
-		pageCount++
+func (r *OpenAIUsageResponse) empty() dayResponse { return &OpenAIUsageResponse{} }
+
+func (r *OpenAICostResponse) splitByDay() map[int64]dayResponse {
+	days := make(map[int64]dayResponse)
+	for _, bucket := range r.Data {
+		day := time.Unix(bucket.StartTime, 0).UTC().Truncate(24 * time.Hour).Unix()
+		if days[day] == nil {
+			days[day] = &OpenAICostResponse{}
+		}
+		resp := days[day].(*OpenAICostResponse)
+		resp.Data = append(resp.Data, bucket)
+	}
+	return days
+}
 
-		// Build URL with query parameters
-		url := fmt.Sprintf("%s/organization/usage/completions", o.baseURL)
+func (r *OpenAICostResponse) join(other dayResponse) {
+	r.Data = append(r.Data, other.(*OpenAICostResponse).Data...)
+}
 
-		// Add timeout for longer periods to prevent hanging
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
+func (r *OpenAICostResponse) empty() dayResponse { return &OpenAICostResponse{} }
+
+// getByDay fetches daily data with each completed day in days cached on its
+// own under a key named by endpoint, so repeated fetches of a moving or
+// growing range (rolling periods, watch mode) reuse the days that can no
+// longer change. The partial days at either end of the range are always
+// refetched. lookup reads a day from the cache and fetch requests a range
+// from the API without consulting the cache.
+func (o *OpenAIProvider) getByDay(ctx context.Context, endpoint string, startTime, endTime time.Time, days []time.Time, groupBy []string, debug bool,
+	lookup func(key string) (dayResponse, bool),
+	fetch func(ctx context.Context, start, end time.Time) (dayResponse, bool, error)) (dayResponse, error) {
+	var result dayResponse
+	add := func(resp dayResponse) {
+		if result == nil {
+			result = resp.empty()
+		}
+		result.join(resp)
+	}
+	first, last := days[0], days[len(days)-1].Add(24*time.Hour)
 
-		// Create HTTP request with context
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if startTime.Before(first) {
+		resp, _, err := fetch(ctx, startTime, first)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
+			return nil, err
 		}
+		add(resp)
+	}
 
-		// Add query parameters
-		q := req.URL.Query()
-		q.Add("start_time", fmt.Sprintf("%d", startTime.Unix()))
-		if !endTime.IsZero() {
-			q.Add("end_time", fmt.Sprintf("%d", endTime.Unix()))
+	cached := make([]dayResponse, len(days))
+	hits := 0
+	for i, day := range days {
+		if resp, ok := lookup(o.dayKey(endpoint, day, groupBy)); ok {
+			cached[i] = resp
+			hits++
 		}
-		if bucketWidth != "" {
-			q.Add("bucket_width", bucketWidth)
+	}
+	if debug {
+		fmt.Printf(o.icon("check")+"%s CACHE: %d of %d completed days cached\n\n", strings.ToUpper(strings.ReplaceAll(endpoint, "_", " ")), hits, len(days))
+	}
+
+	for i := 0; i < len(days); {
+		if cached[i] != nil {
+			add(cached[i])
+			i++
+			continue
 		}
-		if len(groupBy) > 0 {
-			for _, group := range groupBy {
-				q.Add("group_by", group)
-			}
+		// Consecutive missing days are fetched in one request
+		j := i
+		for j < len(days) && cached[j] == nil {
+			j++
 		}
-		// Add next_page token if we have one
-		if nextPage != "" {
-			q.Add("page", nextPage)
+		resp, complete, err := fetch(ctx, days[i], days[j-1].Add(24*time.Hour))
+		if err != nil {
+			return nil, err
 		}
-		req.URL.RawQuery = q.Encode()
-
-		// Log request details for debugging (only when debug is enabled)
-		if debug {
-			fmt.Printf("🔍 OPENAI USAGE API REQUEST (Page %d, Token: %s):\n", pageCount, nextPage)
-			fmt.Printf("   URL: %s\n", req.URL.String())
-			fmt.Printf("   Start Time: %s (%d)\n", startTime.Format("2006-01-02 15:04:05"), startTime.Unix())
-			fmt.Printf("   End Time: %s (%d)\n", endTime.Format("2006-01-02 15:04:05"), endTime.Unix())
-			fmt.Printf("   Bucket Width: %s\n", bucketWidth)
-			fmt.Printf("   Group By: %v\n", groupBy)
-			if nextPage != "" {
-				fmt.Printf("   Next Page: %s\n", nextPage)
-			}
-			fmt.Println()
+		add(resp)
+		if complete {
+			o.saveDays(endpoint, days[i:j], resp, groupBy)
 		}
+		i = j
+	}
 
-		// Add headers
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", o.apiKey))
-		if o.orgID != "" {
-			req.Header.Set("OpenAI-Organization", o.orgID)
-		}
-
-		// Make request with circuit breaker
-		var resp *http.Response
-		err = o.circuitBreaker.Call(func() error {
-			var reqErr error
-			resp, reqErr = o.client.Do(req)
-			if reqErr != nil {
-				return fmt.Errorf("failed to make request: %w", reqErr)
-			}
-
-			if resp.StatusCode != http.StatusOK {
-				defer resp.Body.Close()
-				return fmt.Errorf("API request failed with status: %d", resp.StatusCode)
-			}
-			return nil
-		})
-
+	if last.Before(endTime) {
+		resp, _, err := fetch(ctx, last, endTime)
 		if err != nil {
 			return nil, err
 		}
-		defer resp.Body.Close()
+		add(resp)
+	}
 
-		// Parse response
-		var usageResp OpenAIUsageResponse
-		if err := json.NewDecoder(resp.Body).Decode(&usageResp); err != nil {
-			return nil, fmt.Errorf("failed to decode response: %w", err)
-		}
+	return result, nil
+}
 
-		// Log raw response for debugging
-		rawJSON, _ := json.MarshalIndent(usageResp, "", "  ")
-		if debug {
-			fmt.Printf("🔍 RAW OPENAI USAGE API RESPONSE (Page %d, Token: %s):\n", pageCount, nextPage)
-			fmt.Printf("   Has More: %v\n", usageResp.HasMore)
-			if usageResp.HasMore {
-				fmt.Printf("   Next Page: %s\n", usageResp.NextPage)
-			}
-			fmt.Printf("   Data Buckets: %d\n", len(usageResp.Data))
-			fmt.Printf("   Total Results: %d\n", countTotalResults(usageResp.Data))
-			fmt.Printf("%s\n\n", string(rawJSON))
+// dayKey returns the cache key of an endpoint's daily data for the UTC day starting at day
+func (o *OpenAIProvider) dayKey(endpoint string, day time.Time, groupBy []string) string {
+	params := map[string]string{
+		"day": day.UTC().Format("2006-01-02"),
+	}
+	for i, group := range groupBy {
+		params[fmt.Sprintf("group_by_%d", i)] = group
+	}
+	return o.getCacheKey(endpoint, params)
+}
+
+// saveDays caches the buckets of resp under the day each one starts on. Every
+// day in days is stored, including days without buckets, so idle days are
+// reused too.
+func (o *OpenAIProvider) saveDays(endpoint string, days []time.Time, resp dayResponse, groupBy []string) {
+	byDay := resp.splitByDay()
+	for _, day := range days {
+		data, ok := byDay[day.Unix()]
+		if !ok {
+			data = resp.empty()
 		}
+		o.saveToCache(o.dayKey(endpoint, day, groupBy), data, day.Add(24*time.Hour))
+	}
+}
 
-		// Append results to allData
-		allData = append(allData, usageResp.Data...)
+// fetchUsage fetches usage for a range as a single (possibly chunked and
+// paginated) request. complete is false when the result was cut short by a
+// page or size limit. With bypassCache the cache is neither read
+// nor written.
+func (o *OpenAIProvider) fetchUsage(ctx context.Context, startTime, endTime time.Time, bucketWidth string, groupBy []string, bypassCache bool, debug bool) (resp *OpenAIUsageResponse, complete bool, err error) {
+	// Create cache key
+	params := map[string]string{
+		"start_time":   fmt.Sprintf("%d", startTime.Unix()),
+		"end_time":     fmt.Sprintf("%d", endTime.Unix()),
+		"bucket_width": bucketWidth,
+	}
+	for i, group := range groupBy {
+		params[fmt.Sprintf("group_by_%d", i)] = group
+	}
+	cacheKey := o.getCacheKey("usage", params)
 
-		// Check if there's a next page
-		if !usageResp.HasMore {
-			if debug {
-				fmt.Printf("🔍 PAGINATION COMPLETE: Fetched %d pages, %d total buckets\n",
-					pageCount, len(allData))
-			}
-			break
+	// Try to get from cache (unless bypassing)
+	if !bypassCache {
+		var result *OpenAIUsageResponse
+		if o.getFromCache(cacheKey, &result) {
+			return result, true, nil
 		}
+	}
 
-		// Check for pagination loops
-		if usageResp.NextPage == "" {
+	// Very long ranges are fetched as sequential chunks, each cached on its own,
+	// which is more reliable than deep pagination within one huge range
+	if chunks := chunkRange(startTime, endTime, usageChunkDays); !endTime.IsZero() && len(chunks) > 1 {
+		// Fetch chunks concurrently (bounded), then concatenate in order
+		results := make([][]OpenAIUsageBucket, len(chunks))
+		completed := make([]bool, len(chunks))
+		// With fail-fast the first failing chunk cancels the rest
+		err := utils.RunBoundedContext(ctx, len(chunks), o.maxConcurrency, o.failFast, func(ctx context.Context, i int) error {
 			if debug {
-				fmt.Printf("⚠️  WARNING: API returned has_more=true but no next_page token\n")
+				fmt.Printf(o.icon("check")+"FETCHING USAGE CHUNK: %s to %s\n\n",
+					chunks[i][0].Format("2006-01-02"), chunks[i][1].Format("2006-01-02"))
+			}
+			chunkResp, chunkComplete, err := o.fetchUsage(ctx, chunks[i][0], chunks[i][1], bucketWidth, groupBy, bypassCache, debug)
+			if err != nil {
+				return err
 			}
-			break
+			results[i] = chunkResp.Data
+			completed[i] = chunkComplete
+			return nil
+		})
+		if err != nil {
+			return nil, false, err
 		}
 
-		// Check if we've seen this page token before (loop detection)
-		if seenPages[usageResp.NextPage] {
-			if debug {
-				fmt.Printf("⚠️  WARNING: Detected pagination loop at page %d, stopping\n", pageCount)
-			}
-			break
+		var allData []OpenAIUsageBucket
+		complete := true
+		for i, data := range results {
+			allData = append(allData, data...)
+			complete = complete && completed[i]
 		}
-		seenPages[usageResp.NextPage] = true
 
-		nextPage = usageResp.NextPage
-		if debug {
-			fmt.Printf("🔍 FETCHING NEXT PAGE: %s\n\n", nextPage)
+		if complete && !bypassCache {
+			o.saveToCache(cacheKey, &OpenAIUsageResponse{Data: allData}, endTime)
 		}
+		return &OpenAIUsageResponse{Data: allData}, complete, nil
 	}
 
-	// Safety check - if we hit max pages, log a warning
-	if pageCount >= maxPages {
-		if debug {
-			fmt.Printf("⚠️  WARNING: Hit maximum page limit (%d), stopping pagination\n", maxPages)
-		}
+	// Not in cache or bypassing cache, make API request with pagination
+	var allData []OpenAIUsageBucket
+	truncated, err := o.fetchAllPages(ctx, pageQuery{
+		label:     "USAGE",
+		endpoint:  "/organization/usage/completions",
+		params:    rangeParams(startTime, endTime, bucketWidth, groupBy),
+		startTime: startTime,
+		endTime:   endTime,
+	}, debug, func() pagedResponse {
+		return &OpenAIUsageResponse{}
+	}, func(page pagedResponse) {
+		allData = append(allData, page.(*OpenAIUsageResponse).Data...)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(allData) > maxBuckets {
+		allData = allData[:maxBuckets]
+	}
+
+	// Partial data is returned with a warning but never cached
+	if truncated {
+		warnTruncated(len(allData))
+		return &OpenAIUsageResponse{Data: allData}, false, nil
 	}
 
-	// Cache the result
-	o.saveToCache(cacheKey, &OpenAIUsageResponse{Data: allData})
+	// Cache the result, unless the cache is bypassed
+	if !bypassCache {
+		o.saveToCache(cacheKey, &OpenAIUsageResponse{Data: allData}, endTime)
+	}
 
-	return &OpenAIUsageResponse{Data: allData}, nil
+	return &OpenAIUsageResponse{Data: allData}, true, nil
 }
 
 // GetCosts retrieves cost data from OpenAI (internal method)
 func (o *OpenAIProvider) GetCosts(startTime, endTime time.Time, groupBy []string, bypassCache bool, debug bool) (*OpenAICostResponse, error) {
+	return o.getCosts(context.Background(), startTime, endTime, groupBy, bypassCache, debug)
+}
+
+// getCosts is GetCosts with requests made under ctx
+func (o *OpenAIProvider) getCosts(ctx context.Context, startTime, endTime time.Time, groupBy []string, bypassCache bool, debug bool) (*OpenAICostResponse, error) {
+	if o.useDayCache(bypassCache) && !endTime.IsZero() {
+		if days := completedDays(startTime, endTime, timeNow()); len(days) > 0 {
+			resp, err := o.getByDay(ctx, "costs_day", startTime, endTime, days, groupBy, debug, func(key string) (dayResponse, bool) {
+				var resp *OpenAICostResponse
+				ok := o.getFromCache(key, &resp)
+				return resp, ok
+			}, func(ctx context.Context, start, end time.Time) (dayResponse, bool, error) {
+				return o.fetchCosts(ctx, start, end, groupBy, true, debug)
+			})
+			if err != nil {
+				return nil, err
+			}
+			return resp.(*OpenAICostResponse), nil
+		}
+	}
+	resp, _, err := o.fetchCosts(ctx, startTime, endTime, groupBy, bypassCache, debug)
+	return resp, err
+}
+
+// fetchCosts fetches costs for a range as a single (possibly chunked and
+// paginated) request. complete is false when the result was cut short by a
+// page or size limit. With bypassCache the cache is neither read
+// nor written.
+func (o *OpenAIProvider) fetchCosts(ctx context.Context, startTime, endTime time.Time, groupBy []string, bypassCache bool, debug bool) (resp *OpenAICostResponse, complete bool, err error) {
 	// Create cache key
 	params := map[string]string{
 		"start_time":   fmt.Sprintf("%d", startTime.Unix()),
 		"end_time":     fmt.Sprintf("%d", endTime.Unix()),
-		"bucket_width": "1d", // Costs API only supports daily buckets
+		"bucket_width": CostBucketWidth,
 	}
 	for i, group := range groupBy {
 		params[fmt.Sprintf("group_by_%d", i)] = group
@@ -488,58 +1176,172 @@ func (o *OpenAIProvider) GetCosts(startTime, endTime time.Time, groupBy []string
 	if !bypassCache {
 		var result *OpenAICostResponse
 		if o.getFromCache(cacheKey, &result) {
-			return result, nil
+			return result, true, nil
 		}
 	}
 
+	// Very long ranges are fetched as sequential chunks, each cached on its own
+	if chunks := chunkRange(startTime, endTime, costChunkDays); !endTime.IsZero() && len(chunks) > 1 {
+		// Fetch chunks concurrently (bounded), then concatenate in order
+		results := make([][]OpenAICostBucket, len(chunks))
+		completed := make([]bool, len(chunks))
+		// With fail-fast the first failing chunk cancels the rest
+		err := utils.RunBoundedContext(ctx, len(chunks), o.maxConcurrency, o.failFast, func(ctx context.Context, i int) error {
+			if debug {
+				fmt.Printf(o.icon("check")+"FETCHING COSTS CHUNK: %s to %s\n\n",
+					chunks[i][0].Format("2006-01-02"), chunks[i][1].Format("2006-01-02"))
+			}
+			chunkResp, chunkComplete, err := o.fetchCosts(ctx, chunks[i][0], chunks[i][1], groupBy, bypassCache, debug)
+			if err != nil {
+				return err
+			}
+			results[i] = chunkResp.Data
+			completed[i] = chunkComplete
+			return nil
+		})
+		if err != nil {
+			return nil, false, err
+		}
+
+		// A truncated chunk makes the whole range partial, so it isn't cached
+		var allData []OpenAICostBucket
+		complete := true
+		for i, data := range results {
+			allData = append(allData, data...)
+			complete = complete && completed[i]
+		}
+
+		if complete && !bypassCache {
+			o.saveToCache(cacheKey, &OpenAICostResponse{Data: allData}, endTime)
+		}
+		return &OpenAICostResponse{Data: allData}, complete, nil
+	}
+
 	// Not in cache or bypassing cache, make API request with pagination
 	var allData []OpenAICostBucket
+	truncated, err := o.fetchAllPages(ctx, pageQuery{
+		label:     "COSTS",
+		endpoint:  "/organization/costs",
+		params:    rangeParams(startTime, endTime, CostBucketWidth, groupBy),
+		startTime: startTime,
+		endTime:   endTime,
+	}, debug, func() pagedResponse {
+		return &OpenAICostResponse{}
+	}, func(page pagedResponse) {
+		allData = append(allData, page.(*OpenAICostResponse).Data...)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	if len(allData) > maxBuckets {
+		allData = allData[:maxBuckets]
+	}
+
+	// Partial data is returned with a warning but never cached
+	if truncated {
+		warnTruncated(len(allData))
+		return &OpenAICostResponse{Data: allData}, false, nil
+	}
+
+	// Cache the result, unless the cache is bypassed
+	if !bypassCache {
+		o.saveToCache(cacheKey, &OpenAICostResponse{Data: allData}, endTime)
+	}
+
+	return &OpenAICostResponse{Data: allData}, true, nil
+}
+
+// pagedResponse is a decoded page of a paginated endpoint
+type pagedResponse interface {
+	// pagination returns the page's has_more flag and next_page token
+	pagination() (hasMore bool, nextPage string)
+	// size returns the number of buckets on the page and results across them
+	size() (buckets, results int)
+}
+
+func (r *OpenAIUsageResponse) pagination() (bool, string) { return r.HasMore, r.NextPage }
+
+func (r *OpenAIUsageResponse) size() (int, int) { return len(r.Data), countTotalResults(r.Data) }
+
+func (r *OpenAICostResponse) pagination() (bool, string) { return r.HasMore, r.NextPage }
+
+func (r *OpenAICostResponse) size() (int, int) { return len(r.Data), countTotalCostResults(r.Data) }
+
+// pageQuery describes a paginated fetch of one endpoint
+type pageQuery struct {
+	label     string     // names the endpoint in debug output, e.g. "USAGE"
+	endpoint  string     // path under baseURL
+	params    url.Values // query parameters other than the page token
+	startTime time.Time  // range bounds, only used for debug output
+	endTime   time.Time
+}
+
+// rangeParams returns the query parameters selecting a range, bucket width
+// and grouping. A zero endTime or empty bucketWidth is left to the API default.
+func rangeParams(startTime, endTime time.Time, bucketWidth string, groupBy []string) url.Values {
+	params := url.Values{}
+	params.Add("start_time", fmt.Sprintf("%d", startTime.Unix()))
+	if !endTime.IsZero() {
+		params.Add("end_time", fmt.Sprintf("%d", endTime.Unix()))
+	}
+	if bucketWidth != "" {
+		params.Add("bucket_width", bucketWidth)
+	}
+	for _, group := range groupBy {
+		params.Add("group_by", group)
+	}
+	return params
+}
+
+// fetchAllPages requests q page by page until the API reports no more pages.
+// Each page is decoded into a fresh value from newPage and, once accepted,
+// passed to accept. truncated is set when the page cap, the response size or
+// bucket limit, or a repeated or missing page token stopped pagination early;
+// accept has then seen only part of the data, and may have been handed more
+// than maxBuckets buckets in total.
+func (o *OpenAIProvider) fetchAllPages(ctx context.Context, q pageQuery, debug bool, newPage func() pagedResponse, accept func(pagedResponse)) (truncated bool, err error) {
 	var nextPage string
-	maxPages := 50 // Safety limit to prevent infinite loops
+	maxPages := o.maxPages // Safety limit to prevent infinite loops; 0 relies on loop detection
 	pageCount := 0
 	seenPages := make(map[string]bool) // Track seen pages to detect loops
+	var totalBytes int64               // Response bytes read so far, bounded by maxResponseBytes
+	totalBuckets := 0                  // Buckets accepted so far, bounded by maxBuckets
+	emptyRetries := 0                  // Re-requests of the current page after an empty next_page
 
-	for pageCount < maxPages {
+	for {
 		pageCount++
 
-		// Build URL with query parameters
-		url := fmt.Sprintf("%s/organization/costs", o.baseURL)
-
-		// Add timeout for longer periods to prevent hanging
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-
-		// Create HTTP request with context
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		// Create HTTP request under ctx, so cancelling it stops the fetch.
+		// The client's connection and read timeouts stop a stalled page
+		// without cutting off a slow but progressing one.
+		req, err := http.NewRequestWithContext(ctx, "GET", o.baseURL+q.endpoint, nil)
 		if err != nil {
-			return nil, fmt.Errorf("failed to create request: %w", err)
+			return false, fmt.Errorf("failed to create request: %w", err)
 		}
 
-		// Add query parameters
-		q := req.URL.Query()
-		q.Add("start_time", fmt.Sprintf("%d", startTime.Unix()))
-		if !endTime.IsZero() {
-			q.Add("end_time", fmt.Sprintf("%d", endTime.Unix()))
-		}
-		q.Add("bucket_width", "1d") // Costs API only supports daily buckets
-		if len(groupBy) > 0 {
-			for _, group := range groupBy {
-				q.Add("group_by", group)
-			}
+		// Add query parameters and the next_page token if we have one
+		query := url.Values{}
+		for k, v := range q.params {
+			query[k] = v
 		}
-		// Add next_page token if we have one
 		if nextPage != "" {
-			q.Add("page", nextPage)
+			query.Set("page", nextPage)
 		}
-		req.URL.RawQuery = q.Encode()
+		req.URL.RawQuery = query.Encode()
 
 		// Log request details for debugging (only when debug is enabled)
 		if debug {
-			fmt.Printf("🔍 OPENAI COSTS API REQUEST (Page %d, Token: %s):\n", pageCount, nextPage)
+			fmt.Printf(o.icon("check")+"OPENAI %s API REQUEST (Page %d, Token: %s):\n", q.label, pageCount, nextPage)
 			fmt.Printf("   URL: %s\n", req.URL.String())
-			fmt.Printf("   Start Time: %s (%d)\n", startTime.Format("2006-01-02 15:04:05"), startTime.Unix())
-			fmt.Printf("   End Time: %s (%d)\n", endTime.Format("2006-01-02 15:04:05"), endTime.Unix())
-			fmt.Printf("   Group By: %v\n", groupBy)
+			fmt.Printf("   Start Time: %s (%d)\n", q.startTime.Format("2006-01-02 15:04:05"), q.startTime.Unix())
+			fmt.Printf("   End Time: %s (%d)\n", q.endTime.Format("2006-01-02 15:04:05"), q.endTime.Unix())
+			if width := q.params.Get("bucket_width"); width != "" {
+				fmt.Printf("   Bucket Width: %s\n", width)
+			}
+			fmt.Printf("   Group By: %v\n", q.params["group_by"])
+			if len(o.extraHeaders) > 0 {
+				fmt.Printf("   Extra Headers: %v\n", utils.RedactedHeaders(o.extraHeaders))
+			}
 			if nextPage != "" {
 				fmt.Printf("   Next Page: %s\n", nextPage)
 			}
@@ -547,97 +1349,159 @@ func (o *OpenAIProvider) GetCosts(startTime, endTime time.Time, groupBy []string
 		}
 
 		// Add headers
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", o.apiKey))
-		if o.orgID != "" {
-			req.Header.Set("OpenAI-Organization", o.orgID)
-		}
-
-		// Make request with circuit breaker
-		var resp *http.Response
-		err = o.circuitBreaker.Call(func() error {
-			var reqErr error
-			resp, reqErr = o.client.Do(req)
-			if reqErr != nil {
-				return fmt.Errorf("failed to make request: %w", reqErr)
-			}
-
-			if resp.StatusCode != http.StatusOK {
-				defer resp.Body.Close()
-				return fmt.Errorf("API request failed with status: %d", resp.StatusCode)
-			}
-			return nil
-		})
+		o.setHeaders(req)
 
+		// Fetch and parse the page, never reading past the remaining byte budget
+		page := newPage()
+		n, err := o.fetchPage(req, o.maxResponseBytes-totalBytes, page)
+		if errors.Is(err, ErrResponseTooLarge) && totalBuckets > 0 {
+			return true, nil
+		}
 		if err != nil {
-			return nil, err
+			return false, err
 		}
-		defer resp.Body.Close()
+		totalBytes += n
+		o.pagesFetched.Add(1)
+		o.bytesRead.Add(n)
 
-		// Parse response
-		var costResp OpenAICostResponse
-		if err := json.NewDecoder(resp.Body).Decode(&costResp); err != nil {
-			return nil, fmt.Errorf("failed to decode response: %w", err)
-		}
+		hasMore, next := page.pagination()
+		buckets, results := page.size()
 
 		// Log raw response for debugging
-		rawJSON, _ := json.MarshalIndent(costResp, "", "  ")
 		if debug {
-			fmt.Printf("🔍 RAW OPENAI COSTS API RESPONSE (Page %d, Token: %s):\n", pageCount, nextPage)
-			fmt.Printf("   Has More: %v\n", costResp.HasMore)
-			if costResp.HasMore {
-				fmt.Printf("   Next Page: %s\n", costResp.NextPage)
+			rawJSON, _ := json.MarshalIndent(page, "", "  ")
+			fmt.Printf(o.icon("check")+"RAW OPENAI %s API RESPONSE (Page %d, Token: %s):\n", q.label, pageCount, nextPage)
+			fmt.Printf("   Has More: %v\n", hasMore)
+			if hasMore {
+				fmt.Printf("   Next Page: %s\n", next)
 			}
-			fmt.Printf("   Data Buckets: %d\n", len(costResp.Data))
-			fmt.Printf("   Total Results: %d\n", countTotalCostResults(costResp.Data))
+			fmt.Printf("   Data Buckets: %d\n", buckets)
+			fmt.Printf("   Total Results: %d\n", results)
 			fmt.Printf("%s\n\n", string(rawJSON))
 		}
 
-		// Append results to allData
-		allData = append(allData, costResp.Data...)
+		// has_more without a next_page token can be transient, so re-request the
+		// same page (discarding this response) before giving up on the rest
+		if hasMore && next == "" && emptyRetries < o.emptyPageRetries {
+			emptyRetries++
+			pageCount--
+			if debug {
+				fmt.Printf(o.icon("warning")+"WARNING: API returned has_more=true but no next_page token, retrying page (%d/%d)\n\n", emptyRetries, o.emptyPageRetries)
+			}
+			select {
+			case <-time.After(emptyPageRetryDelay):
+			case <-ctx.Done():
+				return false, ctx.Err()
+			}
+			continue
+		}
+
+		// Accept the page, stopping at the bucket ceiling
+		accept(page)
+		totalBuckets += buckets
+		if totalBuckets > maxBuckets {
+			return true, nil
+		}
 
 		// Check if there's a next page
-		if !costResp.HasMore {
+		if !hasMore {
 			if debug {
-				fmt.Printf("🔍 PAGINATION COMPLETE: Fetched %d pages, %d total buckets\n",
-					pageCount, len(allData))
+				fmt.Printf(o.icon("check")+"PAGINATION COMPLETE: Fetched %d pages, %d total buckets\n",
+					pageCount, totalBuckets)
 			}
-			break
+			return false, nil
 		}
 
 		// Check for pagination loops
-		if costResp.NextPage == "" {
+		if next == "" {
 			if debug {
-				fmt.Printf("⚠️  WARNING: API returned has_more=true but no next_page token\n")
+				fmt.Printf(o.icon("warning") + "WARNING: API returned has_more=true but no next_page token\n")
 			}
-			break
+			return true, nil
 		}
 
 		// Check if we've seen this page token before (loop detection)
-		if seenPages[costResp.NextPage] {
+		if seenPages[next] {
+			if debug {
+				fmt.Printf(o.icon("warning")+"WARNING: Detected pagination loop at page %d, stopping\n", pageCount)
+			}
+			return true, nil
+		}
+		seenPages[next] = true
+
+		// More pages remain, but the page cap is a hard stop
+		if maxPages > 0 && pageCount >= maxPages {
 			if debug {
-				fmt.Printf("⚠️  WARNING: Detected pagination loop at page %d, stopping\n", pageCount)
+				fmt.Printf(o.icon("warning")+"WARNING: Hit maximum page limit (%d), stopping pagination\n", maxPages)
 			}
-			break
+			return true, nil
 		}
-		seenPages[costResp.NextPage] = true
 
-		nextPage = costResp.NextPage
+		nextPage = next
+		emptyRetries = 0
 		if debug {
-			fmt.Printf("🔍 FETCHING NEXT PAGE: %s\n\n", nextPage)
+			fmt.Printf(o.icon("check")+"FETCHING NEXT PAGE: %s\n\n", nextPage)
 		}
 	}
+}
 
-	// Safety check - if we hit max pages, log a warning
-	if pageCount >= maxPages {
-		if debug {
-			fmt.Printf("⚠️  WARNING: Hit maximum page limit (%d), stopping pagination\n", maxPages)
+// fetchPage sends one page request through the circuit breaker and decodes
+// the body into v with decodeLimited. The body is closed before it returns,
+// so a long pagination loop holds at most one response open at a time.
+func (o *OpenAIProvider) fetchPage(req *http.Request, limit int64, v interface{}) (int64, error) {
+	var resp *http.Response
+	err := o.circuitBreaker.Call(func() error {
+		var reqErr error
+		resp, reqErr = o.client.Do(req)
+		if reqErr != nil {
+			return fmt.Errorf("failed to make request: %w", reqErr)
+		}
+
+		if resp.StatusCode == http.StatusBadRequest {
+			defer resp.Body.Close()
+			return newBadRequestError(resp.Body)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			defer resp.Body.Close()
+			return newStatusError(resp.StatusCode)
 		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	return decodeLimited(resp.Body, limit, v)
+}
+
+// decodeLimited decodes a JSON body into v, reading at most limit bytes.
+// It returns the number of bytes read, or ErrResponseTooLarge when the body is larger.
+func decodeLimited(body io.Reader, limit int64, v interface{}) (int64, error) {
+	if limit < 1 {
+		return 0, fmt.Errorf("%w: byte budget exhausted", ErrResponseTooLarge)
 	}
 
-	// Cache the result
-	o.saveToCache(cacheKey, &OpenAICostResponse{Data: allData})
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read response: %w", err)
+	}
+	if int64(len(data)) > limit {
+		return 0, fmt.Errorf("%w: more than %d bytes (raise settings.max_response_bytes or use a shorter period)", ErrResponseTooLarge, limit)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return 0, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return int64(len(data)), nil
+}
 
-	return &OpenAICostResponse{Data: allData}, nil
+// warnTruncated reports that a fetch stopped early because of a size or page limit
+func warnTruncated(buckets int) {
+	utils.Warn("Fetch limit reached, returning partial data - totals may be too low; try a shorter period", map[string]interface{}{
+		"buckets": buckets,
+	})
 }
 
 // Legacy methods for backward compatibility
@@ -645,7 +1509,7 @@ func (o *OpenAIProvider) GetLast7DaysUsage() (*OpenAIUsageResponse, error) {
 	endTime := time.Now()
 	startTime := endTime.AddDate(0, 0, -7)
 
-	return o.GetUsage(startTime, endTime, "1d", []string{"model"}, false, false)
+	return o.GetUsage(startTime, endTime, UsageBucketWidth, []string{"model"}, false, false)
 }
 
 func (o *OpenAIProvider) GetLast30DaysCosts() (*OpenAICostResponse, error) {
@@ -0,0 +1,969 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"unicode"
+
+	"tokenwatch/pkg/cache"
+	"tokenwatch/pkg/utils"
+)
+
+// newTestProvider returns a provider whose requests go to a test server
+// running handler. The shared OpenAI rate limiter is relaxed so tests don't
+// wait on it, and the shared circuit breaker starts and ends closed so one
+// test's failures don't short-circuit the next.
+func newTestProvider(t *testing.T, handler http.HandlerFunc) *OpenAIProvider {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	p := NewOpenAIProvider("sk-test", "")
+	p.baseURL = server.URL
+	p.SetRateLimit(1000, 1000)
+	t.Cleanup(func() { p.SetRateLimit(DefaultOpenAIRateLimit, DefaultOpenAIBurst) })
+	p.circuitBreaker.Reset()
+	t.Cleanup(p.circuitBreaker.Reset)
+	return p
+}
+
+// writeJSON encodes v as the response body
+func writeJSON(t *testing.T, w http.ResponseWriter, v interface{}) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Errorf("failed to encode response: %v", err)
+	}
+}
+
+func TestGetCostsDoesNotCacheTruncatedChunks(t *testing.T) {
+	var requests atomic.Int64
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		// Every page claims there is more, so each chunk stops at the page cap
+		writeJSON(t, w, OpenAICostResponse{
+			Data:     []OpenAICostBucket{{StartTime: n, EndTime: n + 1}},
+			HasMore:  true,
+			NextPage: "page",
+		})
+	})
+	p.SetMaxPages(1)
+
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, 0, -3*costChunkDays)
+
+	if _, err := p.GetCosts(start, end, nil, false, false); err != nil {
+		t.Fatalf("GetCosts: %v", err)
+	}
+	first := requests.Load()
+	if first != 3 {
+		t.Fatalf("expected one request per chunk (3), got %d", first)
+	}
+
+	if _, err := p.GetCosts(start, end, nil, false, false); err != nil {
+		t.Fatalf("GetCosts: %v", err)
+	}
+	if got := requests.Load() - first; got != 3 {
+		t.Errorf("partial result was served from cache: second fetch made %d requests, want 3", got)
+	}
+}
+
+func TestGetCostsCachesCompleteChunks(t *testing.T) {
+	var requests atomic.Int64
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		writeJSON(t, w, OpenAICostResponse{Data: []OpenAICostBucket{{StartTime: n, EndTime: n + 1}}})
+	})
+
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, 0, -3*costChunkDays)
+
+	for i := 0; i < 2; i++ {
+		resp, err := p.GetCosts(start, end, nil, false, false)
+		if err != nil {
+			t.Fatalf("GetCosts: %v", err)
+		}
+		if len(resp.Data) != 3 {
+			t.Fatalf("expected 3 buckets (one per chunk), got %d", len(resp.Data))
+		}
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("expected the second fetch to come from cache (3 requests total), got %d", got)
+	}
+}
+
+// pinNow replaces timeNow with a fixed clock for the rest of the test
+func pinNow(t *testing.T, now time.Time) {
+	t.Helper()
+	saved := timeNow
+	timeNow = func() time.Time { return now }
+	t.Cleanup(func() { timeNow = saved })
+}
+
+func TestDiskCacheReusesCompletedDaysOfRollingPeriods(t *testing.T) {
+	now := time.Date(2024, 6, 15, 12, 30, 0, 0, time.UTC)
+	pinNow(t, now)
+	dir := t.TempDir()
+
+	// Each request returns one bucket starting where the requested range does
+	var requests atomic.Int64
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		start, _ := strconv.ParseInt(r.URL.Query().Get("start_time"), 10, 64)
+		writeJSON(t, w, OpenAICostResponse{Data: []OpenAICostBucket{{StartTime: start, EndTime: start + 1}}})
+	}
+
+	// A 7d range ending now stores its 6 completed days, one entry each. The
+	// partial days at either end are fetched but not stored.
+	p := newTestProvider(t, handler)
+	p.EnableDiskCache(dir)
+	if _, err := p.GetCosts(now.AddDate(0, 0, -7), now, nil, false, false); err != nil {
+		t.Fatalf("GetCosts: %v", err)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("cold fetch made %d requests, want 3 (start day, completed days, today)", got)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 6 {
+		t.Fatalf("7d range wrote %d disk cache entries, want one per completed day (6)", len(files))
+	}
+
+	// The next run, an hour later, has a different range but reuses the
+	// completed days from disk and only fetches the open days
+	pinNow(t, now.Add(time.Hour))
+	before := requests.Load()
+	next := newTestProvider(t, handler)
+	next.EnableDiskCache(dir)
+	resp, err := next.GetCosts(now.Add(time.Hour).AddDate(0, 0, -7), now.Add(time.Hour), nil, false, false)
+	if err != nil {
+		t.Fatalf("GetCosts: %v", err)
+	}
+	if got := requests.Load() - before; got != 2 {
+		t.Errorf("second run made %d requests, want 2 (the partial start day and today)", got)
+	}
+	if len(resp.Data) != 3 {
+		t.Errorf("second run returned %d buckets, want 3 (start day, cached days, today)", len(resp.Data))
+	}
+}
+
+func TestDiskCacheSkippedWhenBypassed(t *testing.T) {
+	pinNow(t, time.Date(2024, 6, 15, 12, 30, 0, 0, time.UTC))
+	dir := t.TempDir()
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, OpenAICostResponse{Data: []OpenAICostBucket{{StartTime: 1, EndTime: 2}}})
+	})
+	p.EnableDiskCache(dir)
+
+	end := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := p.GetCosts(end.AddDate(0, 0, -7), end, nil, true, false); err != nil {
+		t.Fatalf("GetCosts: %v", err)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("bypassed fetch wrote %d disk cache entries, want 0", len(files))
+	}
+}
+
+func TestMaxCacheAgeIgnoresOlderEntries(t *testing.T) {
+	const key = "costs-test"
+	stored := time.Now().Add(-5 * time.Minute)
+	seeded := &OpenAICostResponse{Data: []OpenAICostBucket{{StartTime: 1, EndTime: 2}}}
+
+	tests := []struct {
+		maxAge time.Duration
+		hit    bool
+	}{
+		{maxAge: 0, hit: true},
+		{maxAge: 10 * time.Minute, hit: true},
+		{maxAge: 2 * time.Minute, hit: false},
+	}
+	for _, tt := range tests {
+		// Entry in the memory cache, well within its TTL
+		p := newTestProvider(t, nil)
+		p.SetMaxCacheAge(tt.maxAge)
+		p.cache[key] = cacheItem{data: seeded, storedAt: stored, expiresAt: stored.Add(time.Hour)}
+		var resp *OpenAICostResponse
+		if got := p.getFromCache(key, &resp); got != tt.hit {
+			t.Errorf("memory cache, max age %v: hit = %v, want %v", tt.maxAge, got, tt.hit)
+		}
+
+		// The same entry on disk only
+		dir := t.TempDir()
+		if err := cache.Store(dir, key, diskCacheItem{StoredAt: stored, Costs: seeded}, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		p = newTestProvider(t, nil)
+		p.EnableDiskCache(dir)
+		p.SetMaxCacheAge(tt.maxAge)
+		resp = nil
+		if got := p.getFromCache(key, &resp); got != tt.hit {
+			t.Errorf("disk cache, max age %v: hit = %v, want %v", tt.maxAge, got, tt.hit)
+		}
+	}
+}
+
+func TestBadRequestNamesParam(t *testing.T) {
+	tests := []struct {
+		param     string
+		wantField string
+		wantMsg   string
+	}{
+		{param: "bucket_width", wantField: "period", wantMsg: "invalid bucket_width: bad value"},
+		{param: "start_time", wantField: "period", wantMsg: "invalid start_time: bad value"},
+		{param: "group_by", wantField: "group_by", wantMsg: "Invalid group_by: bad value"},
+		{param: "", wantField: "request", wantMsg: "Invalid request: bad value"},
+	}
+	for _, tt := range tests {
+		p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			writeJSON(t, w, map[string]interface{}{
+				"error": map[string]string{"message": "bad value", "type": "invalid_request_error", "param": tt.param},
+			})
+		})
+
+		end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+		_, err := p.GetCosts(end.AddDate(0, 0, -7), end, nil, true, false)
+
+		var se *utils.StructuredError
+		if !errors.As(err, &se) {
+			t.Fatalf("param %q: error %v is not a StructuredError", tt.param, err)
+		}
+		if se.Type != utils.ErrorTypeValidation || se.Context["field"] != tt.wantField {
+			t.Errorf("param %q: got type %s field %v, want validation error on %s", tt.param, se.Type, se.Context["field"], tt.wantField)
+		}
+		if se.StatusCode() != http.StatusBadRequest {
+			t.Errorf("param %q: StatusCode() = %d, want 400", tt.param, se.StatusCode())
+		}
+		if !strings.Contains(se.Message, tt.wantMsg) {
+			t.Errorf("param %q: message %q does not contain %q", tt.param, se.Message, tt.wantMsg)
+		}
+	}
+}
+
+func TestBadRequestWithoutErrorBody(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("not json"))
+	})
+
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	_, err := p.GetCosts(end.AddDate(0, 0, -7), end, nil, true, false)
+
+	var se *utils.StructuredError
+	if !errors.As(err, &se) || se.Type != utils.ErrorTypeAPI || se.StatusCode() != http.StatusBadRequest {
+		t.Errorf("got %v, want an API error with status 400", err)
+	}
+}
+
+func TestMaxConcurrencyBoundsInFlightRequests(t *testing.T) {
+	var running, peak, requests atomic.Int64
+	// The first requests wait (up to a timeout) for a second one to arrive, so
+	// a fetch that never runs two at once can't pass
+	overlapped := make(chan struct{})
+	var overlapOnce sync.Once
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			old := peak.Load()
+			if n <= old || peak.CompareAndSwap(old, n) {
+				break
+			}
+		}
+		if n >= 2 {
+			overlapOnce.Do(func() { close(overlapped) })
+		}
+		requests.Add(1)
+		select {
+		case <-overlapped:
+		case <-time.After(2 * time.Second):
+		}
+		time.Sleep(20 * time.Millisecond)
+		writeJSON(t, w, OpenAICostResponse{Data: []OpenAICostBucket{{StartTime: 1, EndTime: 2}}})
+	})
+	p.SetMaxConcurrency(2)
+
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	if _, err := p.GetCosts(end.AddDate(0, 0, -6*costChunkDays), end, nil, true, false); err != nil {
+		t.Fatalf("GetCosts: %v", err)
+	}
+	if requests.Load() != 6 {
+		t.Fatalf("expected one request per chunk (6), got %d", requests.Load())
+	}
+	if got := peak.Load(); got > 2 {
+		t.Errorf("%d requests were in flight at once, want at most 2", got)
+	}
+	if got := peak.Load(); got < 2 {
+		t.Errorf("at most %d request was in flight at once, want 2 with max concurrency 2", got)
+	}
+}
+
+func TestFailingChunkStopsTheRestWithFailFast(t *testing.T) {
+	for _, failFast := range []bool{false, true} {
+		var requests atomic.Int64
+		p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.WriteHeader(http.StatusBadRequest)
+			writeJSON(t, w, map[string]interface{}{"error": map[string]string{"message": "bad range", "param": "start_time"}})
+		})
+		p.SetMaxConcurrency(1)
+		p.SetFailFast(failFast)
+
+		end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+		_, err := p.GetCosts(end.AddDate(0, 0, -4*costChunkDays), end, nil, true, false)
+		var apiErr *utils.StructuredError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode() != http.StatusBadRequest {
+			t.Fatalf("fail-fast %v: got %v, want the first chunk's 400 error", failFast, err)
+		}
+
+		// By default every chunk is still fetched; fail-fast stops after the first
+		want := int64(4)
+		if failFast {
+			want = 1
+		}
+		if got := requests.Load(); got != want {
+			t.Errorf("fail-fast %v: made %d requests, want %d", failFast, got, want)
+		}
+	}
+}
+
+func TestDecodeLimited(t *testing.T) {
+	body := `{"object":"page","data":[]}`
+	var v OpenAICostResponse
+
+	n, err := decodeLimited(strings.NewReader(body), int64(len(body)), &v)
+	if err != nil || n != int64(len(body)) {
+		t.Errorf("body at the limit: n = %d, err = %v; want %d, nil", n, err, len(body))
+	}
+	if _, err := decodeLimited(strings.NewReader(body), int64(len(body)-1), &v); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("body over the limit: err = %v, want ErrResponseTooLarge", err)
+	}
+	if _, err := decodeLimited(strings.NewReader(body), 0, &v); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("exhausted budget: err = %v, want ErrResponseTooLarge", err)
+	}
+}
+
+func TestOversizedFirstPageFails(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, OpenAICostResponse{Data: make([]OpenAICostBucket, 50)})
+	})
+	p.SetMaxResponseBytes(256)
+
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	if _, err := p.GetCosts(end.AddDate(0, 0, -7), end, nil, true, false); !errors.Is(err, ErrResponseTooLarge) {
+		t.Errorf("GetCosts = %v, want ErrResponseTooLarge", err)
+	}
+}
+
+func TestOversizedLaterPageReturnsUncachedPartialData(t *testing.T) {
+	var requests atomic.Int64
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		// Small first page, then a page that blows the byte budget
+		if r.URL.Query().Get("page") == "" {
+			requests.Add(1)
+			writeJSON(t, w, OpenAICostResponse{Data: []OpenAICostBucket{{StartTime: 1, EndTime: 2}}, HasMore: true, NextPage: "p2"})
+			return
+		}
+		writeJSON(t, w, OpenAICostResponse{Data: make([]OpenAICostBucket, 50)})
+	})
+	p.SetMaxResponseBytes(512)
+
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, 0, -7)
+	for i := 0; i < 2; i++ {
+		resp, err := p.GetCosts(start, end, nil, false, false)
+		if err != nil {
+			t.Fatalf("GetCosts: %v", err)
+		}
+		if len(resp.Data) != 1 {
+			t.Fatalf("got %d buckets, want the 1 from the page within budget", len(resp.Data))
+		}
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("partial result was served from cache: %d first-page requests, want 2", got)
+	}
+}
+
+func TestExtraHeadersSentWithRequests(t *testing.T) {
+	var gateway, auth, org string
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		gateway = r.Header.Get("X-Gateway-Key")
+		auth = r.Header.Get("Authorization")
+		org = r.Header.Get("OpenAI-Organization")
+		writeJSON(t, w, OpenAICostResponse{})
+	})
+	p.orgID = "org-real"
+	p.SetExtraHeaders(map[string]string{
+		"x-gateway-key":       "gw-123",
+		"Authorization":       "Bearer gateway",
+		"OpenAI-Organization": "org-fake",
+	})
+
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	if _, err := p.GetCosts(end.AddDate(0, 0, -7), end, nil, true, false); err != nil {
+		t.Fatalf("GetCosts: %v", err)
+	}
+	if gateway != "gw-123" {
+		t.Errorf("X-Gateway-Key = %q, want gw-123", gateway)
+	}
+	// The standard headers win over extra headers with the same name
+	if auth != "Bearer sk-test" || org != "org-real" {
+		t.Errorf("Authorization = %q, OpenAI-Organization = %q; want the provider's own", auth, org)
+	}
+}
+
+// noEmptyPageDelay removes the pause before re-requesting an empty next_page
+func noEmptyPageDelay(t *testing.T) {
+	t.Helper()
+	saved := emptyPageRetryDelay
+	emptyPageRetryDelay = 0
+	t.Cleanup(func() { emptyPageRetryDelay = saved })
+}
+
+func TestEmptyNextPageRecovers(t *testing.T) {
+	noEmptyPageDelay(t)
+	var firstPage, requests atomic.Int64
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Query().Get("page") == "p2" {
+			writeJSON(t, w, OpenAICostResponse{Data: []OpenAICostBucket{{StartTime: 2, EndTime: 3}}})
+			return
+		}
+		// The first attempt at page 1 drops the token; the retry has it
+		if firstPage.Add(1) == 1 {
+			writeJSON(t, w, OpenAICostResponse{Data: []OpenAICostBucket{{StartTime: 1, EndTime: 2}}, HasMore: true})
+			return
+		}
+		writeJSON(t, w, OpenAICostResponse{Data: []OpenAICostBucket{{StartTime: 1, EndTime: 2}}, HasMore: true, NextPage: "p2"})
+	})
+	p.SetEmptyPageRetries(2)
+
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, 0, -7)
+	resp, err := p.GetCosts(start, end, nil, false, false)
+	if err != nil {
+		t.Fatalf("GetCosts: %v", err)
+	}
+	// The discarded response must not duplicate page 1's buckets
+	if len(resp.Data) != 2 || resp.Data[0].StartTime != 1 || resp.Data[1].StartTime != 2 {
+		t.Fatalf("got buckets %+v, want one from each page", resp.Data)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("made %d requests, want 3 (page 1, its retry, page 2)", got)
+	}
+
+	// The recovered result is complete, so it is cached
+	if _, err := p.GetCosts(start, end, nil, false, false); err != nil {
+		t.Fatalf("GetCosts: %v", err)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("second fetch made %d more requests, want it served from cache", got-3)
+	}
+}
+
+func TestEmptyNextPageGivesUpAfterRetries(t *testing.T) {
+	noEmptyPageDelay(t)
+	var requests atomic.Int64
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		writeJSON(t, w, OpenAICostResponse{Data: []OpenAICostBucket{{StartTime: 1, EndTime: 2}}, HasMore: true})
+	})
+	p.SetEmptyPageRetries(2)
+
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, 0, -7)
+	resp, err := p.GetCosts(start, end, nil, false, false)
+	if err != nil {
+		t.Fatalf("GetCosts: %v", err)
+	}
+	if len(resp.Data) != 1 {
+		t.Errorf("got %d buckets, want the partial page's 1", len(resp.Data))
+	}
+	if got := requests.Load(); got != 3 {
+		t.Errorf("made %d requests, want 3 (the page and 2 retries)", got)
+	}
+
+	// Partial data is not cached
+	if _, err := p.GetCosts(start, end, nil, false, false); err != nil {
+		t.Fatalf("GetCosts: %v", err)
+	}
+	if got := requests.Load(); got != 6 {
+		t.Errorf("partial result was served from cache: %d requests in total, want 6", got)
+	}
+}
+
+func TestProvidersShareHostCircuitBreaker(t *testing.T) {
+	a := NewOpenAIProvider("sk-a", "")
+	b := NewOpenAIProvider("sk-b", "org-b")
+	if a.circuitBreaker != b.circuitBreaker {
+		t.Error("providers for the same host have separate circuit breakers")
+	}
+}
+
+func TestMaxPagesZeroIsUnlimited(t *testing.T) {
+	const pages = 60
+	var requests atomic.Int64
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		resp := OpenAICostResponse{Data: []OpenAICostBucket{{StartTime: n, EndTime: n + 1}}}
+		if n < pages {
+			resp.HasMore = true
+			resp.NextPage = fmt.Sprintf("page-%d", n+1)
+		}
+		writeJSON(t, w, resp)
+	})
+	p.SetMaxPages(0)
+
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	resp, err := p.GetCosts(end.AddDate(0, 0, -7), end, nil, true, false)
+	if err != nil {
+		t.Fatalf("GetCosts: %v", err)
+	}
+	if len(resp.Data) != pages || requests.Load() != pages {
+		t.Errorf("got %d buckets from %d requests, want all %d pages past the default cap of 50", len(resp.Data), requests.Load(), pages)
+	}
+}
+
+func TestRepeatingCursorStopsPagination(t *testing.T) {
+	var requests atomic.Int64
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		if n > 10 {
+			t.Errorf("pagination did not stop at the repeated cursor")
+			writeJSON(t, w, OpenAICostResponse{})
+			return
+		}
+		// Every page points back at the same cursor
+		writeJSON(t, w, OpenAICostResponse{Data: []OpenAICostBucket{{StartTime: n, EndTime: n + 1}}, HasMore: true, NextPage: "again"})
+	})
+	p.SetMaxPages(0)
+
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	resp, err := p.GetCosts(end.AddDate(0, 0, -7), end, nil, true, false)
+	if err != nil {
+		t.Fatalf("GetCosts: %v", err)
+	}
+	// The cursor is followed once, then recognised when it comes back
+	if got := requests.Load(); got != 2 {
+		t.Errorf("made %d requests, want 2", got)
+	}
+	if len(resp.Data) != 2 {
+		t.Errorf("got %d buckets, want the 2 fetched before the loop was detected", len(resp.Data))
+	}
+}
+
+func TestRepeatingCursorResultIsNotCached(t *testing.T) {
+	pinNow(t, time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC))
+	dir := t.TempDir()
+
+	var requests atomic.Int64
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		writeJSON(t, w, OpenAICostResponse{Data: []OpenAICostBucket{{StartTime: n, EndTime: n + 1}}, HasMore: true, NextPage: "again"})
+	})
+	p.SetMaxPages(0)
+	p.EnableDiskCache(dir)
+
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 2; i++ {
+		if _, err := p.GetCosts(end.AddDate(0, 0, -7), end, nil, false, false); err != nil {
+			t.Fatalf("GetCosts: %v", err)
+		}
+	}
+	// Both fetches stop at the loop after 2 requests; neither is served from cache
+	if got := requests.Load(); got != 4 {
+		t.Errorf("made %d requests over two fetches, want 4 with the partial result uncached", got)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 0 {
+		t.Errorf("partial result wrote %d disk cache entries, want 0", len(files))
+	}
+}
+
+func TestStatusErrorsKeepHTTPStatus(t *testing.T) {
+	for _, status := range []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound} {
+		p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		})
+
+		end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+		_, err := p.GetPricing(end.AddDate(0, 0, -7), end, true, false)
+		// Callers wrap provider errors, so the status must survive wrapping
+		err = fmt.Errorf("failed to get pricing data: %w", err)
+
+		var se *utils.StructuredError
+		if !errors.As(err, &se) {
+			t.Fatalf("status %d: error %v is not a StructuredError", status, err)
+		}
+		if se.Type != utils.ErrorTypeAPI || se.StatusCode() != status {
+			t.Errorf("status %d: got type %s, StatusCode() %d", status, se.Type, se.StatusCode())
+		}
+	}
+}
+
+func TestOpenCircuitErrorIsSentinel(t *testing.T) {
+	var requests atomic.Int64
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	var err error
+	for i := 0; i < 6; i++ {
+		_, err = p.GetCosts(end.AddDate(0, 0, -7), end, nil, true, false)
+	}
+	if !errors.Is(fmt.Errorf("wrapped: %w", err), utils.ErrCircuitOpen) {
+		t.Errorf("error after the circuit opened = %v, want ErrCircuitOpen", err)
+	}
+	if got := requests.Load(); got != 5 {
+		t.Errorf("made %d requests, want 5 before the circuit opened", got)
+	}
+}
+
+func TestUsageByDayRefetchesOnlyPartialDays(t *testing.T) {
+	now := time.Date(2024, 3, 10, 15, 0, 0, 0, time.UTC)
+	pinNow(t, now)
+
+	var mu sync.Mutex
+	var ranges []string
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		start, _ := strconv.ParseInt(r.URL.Query().Get("start_time"), 10, 64)
+		end, _ := strconv.ParseInt(r.URL.Query().Get("end_time"), 10, 64)
+		mu.Lock()
+		ranges = append(ranges, time.Unix(start, 0).UTC().Format("01-02T15")+".."+time.Unix(end, 0).UTC().Format("01-02T15"))
+		mu.Unlock()
+
+		// One bucket per day touched by the request
+		var data []OpenAIUsageBucket
+		for day := time.Unix(start, 0).UTC().Truncate(24 * time.Hour); day.Unix() < end; day = day.Add(24 * time.Hour) {
+			data = append(data, OpenAIUsageBucket{StartTime: day.Unix(), EndTime: day.Add(24 * time.Hour).Unix()})
+		}
+		writeJSON(t, w, OpenAIUsageResponse{Data: data})
+	})
+	p.SetCacheCompletedDays(true)
+
+	start := time.Date(2024, 3, 3, 6, 0, 0, 0, time.UTC)
+	fetch := func() []string {
+		t.Helper()
+		mu.Lock()
+		ranges = nil
+		mu.Unlock()
+		// Watch refreshes bypass the cache
+		resp, err := p.GetUsage(start, now, UsageBucketWidth, []string{"model"}, true, false)
+		if err != nil {
+			t.Fatalf("GetUsage: %v", err)
+		}
+		if len(resp.Data) != 8 {
+			t.Errorf("got %d buckets, want one for each of the 8 days", len(resp.Data))
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), ranges...)
+	}
+
+	// The first fetch gets the completed days in one request, with the partial ends on their own
+	first := fetch()
+	if want := "03-03T06..03-04T00,03-04T00..03-10T00,03-10T00..03-10T15"; strings.Join(first, ",") != want {
+		t.Errorf("first fetch requested %v, want %s", first, want)
+	}
+
+	// Later refreshes reuse the completed days and refetch only the partial ends
+	second := fetch()
+	if want := "03-03T06..03-04T00,03-10T00..03-10T15"; strings.Join(second, ",") != want {
+		t.Errorf("refresh requested %v, want %s", second, want)
+	}
+}
+
+func TestExtractModelFromLineItem(t *testing.T) {
+	tests := []struct {
+		lineItem  string
+		wantModel string
+		wantOK    bool
+	}{
+		// Known "model, type" format
+		{"gpt-4o-2024-08-06, input", "gpt-4o-2024-08-06", true},
+		{"gpt-4o-mini, cached input", "gpt-4o-mini", true},
+		{"ft:gpt-4o-mini:acme::abc123, output", "ft:gpt-4o-mini:acme::abc123", true},
+		{"  o1, output  ", "o1", true},
+		// A bare model name
+		{"text-embedding-3-small", "text-embedding-3-small", true},
+		// No model at all, labelled later as unknown
+		{"", "", true},
+		{"   ", "", true},
+		// Partial or unknown formats are kept whole and flagged
+		{"gpt-4o, ", "gpt-4o,", false},
+		{"gpt-4o,input", "gpt-4o,input", false},
+		{"Web search tool calls", "Web search tool calls", false},
+		{"gpt 4o, input", "gpt 4o, input", false},
+		{"gpt-4o, batch, output", "gpt-4o, batch, output", false},
+		{", input", ", input", false},
+	}
+	p := NewOpenAIProvider("sk-test", "")
+	for _, tt := range tests {
+		model, ok := p.extractModelFromLineItem(tt.lineItem)
+		if model != tt.wantModel || ok != tt.wantOK {
+			t.Errorf("extractModelFromLineItem(%q) = %q, %v; want %q, %v", tt.lineItem, model, ok, tt.wantModel, tt.wantOK)
+		}
+	}
+}
+
+func TestGetConsumptionContextCancelsRequestInFlight(t *testing.T) {
+	started := make(chan struct{})
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	_, err := p.GetConsumptionContext(ctx, end.AddDate(0, 0, -7), end, true, false)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+	if p.circuitBreaker.GetState() != utils.StateClosed {
+		t.Errorf("circuit is %s after a cancelled fetch, want closed", p.circuitBreaker.GetState())
+	}
+}
+
+// captureStdout returns what fn writes to os.Stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = saved }()
+
+	out := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		out <- string(b)
+	}()
+	fn()
+	w.Close()
+	return <-out
+}
+
+func TestDebugOutputUsesInjectedIcons(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		// Every page claims there is more, so pagination stops at the page cap with a warning
+		writeJSON(t, w, OpenAICostResponse{Data: []OpenAICostBucket{{StartTime: 1, EndTime: 2}}, HasMore: true, NextPage: "page-" + r.URL.Query().Get("page")})
+	})
+	p.SetMaxPages(2)
+	p.SetIcons(func(name string) string { return "[" + strings.ToUpper(name) + "] " })
+
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	out := captureStdout(t, func() {
+		if _, err := p.GetCosts(end.AddDate(0, 0, -7), end, nil, true, true); err != nil {
+			t.Errorf("GetCosts: %v", err)
+		}
+	})
+
+	for _, want := range []string{"[CHECK] OPENAI", "[WARN"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("debug output is missing %q:\n%s", want, out)
+		}
+	}
+	for i, r := range out {
+		if r > unicode.MaxASCII {
+			t.Fatalf("debug output has non-ASCII %q at %d:\n%s", r, i, out)
+		}
+	}
+}
+
+func TestEmptyNextPageRetryDelayStopsOnCancel(t *testing.T) {
+	saved := emptyPageRetryDelay
+	emptyPageRetryDelay = time.Hour
+	t.Cleanup(func() { emptyPageRetryDelay = saved })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, OpenAICostResponse{Data: []OpenAICostBucket{{StartTime: 1, EndTime: 2}}, HasMore: true})
+		// Cancel once the provider is waiting to re-request the page
+		time.AfterFunc(50*time.Millisecond, cancel)
+	})
+
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	done := make(chan error, 1)
+	go func() {
+		_, err := p.GetPricingContext(ctx, end.AddDate(0, 0, -7), end, true, false)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("error = %v, want context.Canceled", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("fetch kept waiting out the retry delay after its context was cancelled")
+	}
+}
+
+func TestGetProjectCostsSumsPerProject(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query()["group_by"]; len(got) != 1 || got[0] != "project_id" {
+			t.Errorf("group_by = %v, want [project_id]", got)
+		}
+		usd := func(v float64) OpenAICostAmount { return OpenAICostAmount{Value: v, Currency: "usd"} }
+		writeJSON(t, w, OpenAICostResponse{Data: []OpenAICostBucket{
+			{StartTime: 1, EndTime: 2, Results: []OpenAICostResult{
+				{ProjectID: "proj_a", Amount: usd(1.25)},
+				{ProjectID: "proj_b", Amount: usd(0.5)},
+				{Amount: usd(0.1)}, // org-level charge without a project
+			}},
+			{StartTime: 2, EndTime: 3, Results: []OpenAICostResult{
+				{ProjectID: "proj_a", Amount: usd(0.75)},
+				{ProjectID: " ", Amount: usd(0.2)},
+			}},
+		}})
+	})
+
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	costs, err := p.GetProjectCosts(end.AddDate(0, 0, -2), end, true, false)
+	if err != nil {
+		t.Fatalf("GetProjectCosts: %v", err)
+	}
+	want := map[string]float64{"proj_a": 2, "proj_b": 0.5, NoProjectID: 0.3}
+	if len(costs) != len(want) {
+		t.Fatalf("costs = %v, want %v", costs, want)
+	}
+	for project, amount := range want {
+		if math.Abs(costs[project]-amount) > 1e-9 {
+			t.Errorf("cost of %s = %v, want %v", project, costs[project], amount)
+		}
+	}
+}
+
+func TestGetPricingConvertsCentsToDollars(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(t, w, OpenAICostResponse{Data: []OpenAICostBucket{{StartTime: 1, EndTime: 2, Results: []OpenAICostResult{
+			{LineItem: "gpt-4o, input", Amount: OpenAICostAmount{Value: 1250, Currency: "usd", Unit: "cents"}},
+			{LineItem: "gpt-4o, output", Amount: OpenAICostAmount{Value: 75, Currency: "usd", Unit: "Minor"}},
+			{LineItem: "o1, input", Amount: OpenAICostAmount{Value: 3.5, Currency: "usd"}},
+			{LineItem: "o1, output", Amount: OpenAICostAmount{Value: 2, Currency: "usd", Unit: "dollars"}},
+		}}}})
+	})
+
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		inCents bool
+		want    []float64
+	}{
+		// Explicit units always win; unit-less values follow cost_in_cents
+		{inCents: false, want: []float64{12.5, 0.75, 3.5, 2}},
+		{inCents: true, want: []float64{12.5, 0.75, 0.035, 2}},
+	}
+	for _, tt := range tests {
+		p.SetCostInCents(tt.inCents)
+		pricings, err := p.GetPricing(end.AddDate(0, 0, -1), end, true, false)
+		if err != nil {
+			t.Fatalf("GetPricing: %v", err)
+		}
+		if len(pricings) != len(tt.want) {
+			t.Fatalf("cost_in_cents=%v: got %d line items, want %d", tt.inCents, len(pricings), len(tt.want))
+		}
+		for i, want := range tt.want {
+			if math.Abs(pricings[i].Amount-want) > 1e-9 {
+				t.Errorf("cost_in_cents=%v: %s = $%v, want $%v", tt.inCents, pricings[i].LineItem, pricings[i].Amount, want)
+			}
+		}
+	}
+}
+
+func TestProjectHeaderOnlyWhenConfigured(t *testing.T) {
+	var mu sync.Mutex
+	projects := make(map[string][]string) // request path -> OpenAI-Project headers
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		projects[r.URL.Path] = r.Header.Values("OpenAI-Project")
+		mu.Unlock()
+		if strings.Contains(r.URL.Path, "costs") {
+			writeJSON(t, w, OpenAICostResponse{})
+		} else {
+			writeJSON(t, w, OpenAIUsageResponse{})
+		}
+	})
+
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	for _, project := range []string{"proj_abc", ""} {
+		p.SetProject(project)
+		mu.Lock()
+		projects = make(map[string][]string)
+		mu.Unlock()
+		if _, err := p.GetUsage(end.AddDate(0, 0, -7), end, "1d", nil, true, false); err != nil {
+			t.Fatalf("GetUsage: %v", err)
+		}
+		if _, err := p.GetCosts(end.AddDate(0, 0, -7), end, nil, true, false); err != nil {
+			t.Fatalf("GetCosts: %v", err)
+		}
+
+		if len(projects) != 2 {
+			t.Fatalf("requested %v, want a usage and a costs endpoint", projects)
+		}
+		for path, got := range projects {
+			if project == "" && len(got) != 0 {
+				t.Errorf("%s: OpenAI-Project = %q without a project, want no header", path, got)
+			}
+			if project != "" && (len(got) != 1 || got[0] != project) {
+				t.Errorf("%s: OpenAI-Project = %q, want %q", path, got, project)
+			}
+		}
+	}
+}
+
+func TestTotalsFastPathNeedsFewerRequests(t *testing.T) {
+	// Grouped results span three pages per request, ungrouped totals fit in one
+	const groupedPages = 3
+	var requests atomic.Int64
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		var resp OpenAIUsageResponse
+		if len(r.URL.Query()["group_by"]) > 0 {
+			if page, _ := strconv.Atoi(r.URL.Query().Get("page")); page < groupedPages-1 {
+				resp.HasMore = true
+				resp.NextPage = strconv.Itoa(page + 1)
+			}
+		}
+		writeJSON(t, w, resp)
+	})
+
+	end := time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, 0, -7)
+
+	if _, err := p.GetTotals(start, end, true, false); err != nil {
+		t.Fatalf("GetTotals: %v", err)
+	}
+	fast := requests.Swap(0)
+
+	if _, err := p.GetConsumption(start, end, true, false); err != nil {
+		t.Fatalf("GetConsumption: %v", err)
+	}
+	if _, err := p.GetPricing(start, end, true, false); err != nil {
+		t.Fatalf("GetPricing: %v", err)
+	}
+	detailed := requests.Load()
+
+	if fast >= detailed {
+		t.Errorf("fast path made %d requests, detailed report %d; want fewer", fast, detailed)
+	}
+}
@@ -1,9 +1,13 @@
 package providers
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"tokenwatch/pkg/models"
+	"tokenwatch/pkg/utils"
 )
 
 // Provider defines the interface that all platform providers must implement
@@ -17,6 +21,13 @@ type Provider interface {
 	// GetPricing retrieves pricing data for a specific time period
 	GetPricing(startTime, endTime time.Time, bypassCache bool, debug bool) ([]*models.Pricing, error)
 
+	// GetConsumptionContext is GetConsumption with requests made under ctx,
+	// so cancelling ctx stops fetches in flight
+	GetConsumptionContext(ctx context.Context, startTime, endTime time.Time, bypassCache bool, debug bool) ([]*models.Consumption, error)
+
+	// GetPricingContext is GetPricing with requests made under ctx
+	GetPricingContext(ctx context.Context, startTime, endTime time.Time, bypassCache bool, debug bool) ([]*models.Pricing, error)
+
 	// GetConsumptionSummary gets aggregated consumption data for common periods
 	GetConsumptionSummary(period string) (*models.ConsumptionSummary, error)
 
@@ -27,6 +38,26 @@ type Provider interface {
 	IsAvailable() bool
 }
 
+// DefaultMaxConcurrency is the default number of parallel fetches per provider
+const DefaultMaxConcurrency = 4
+
+// Default OpenAI rate limit: 60 requests per minute with a burst of 5
+const (
+	DefaultOpenAIRateLimit = 1.0
+	DefaultOpenAIBurst     = 5
+)
+
+// DefaultMaxPages is the default cap on pages requested by a single paginated
+// fetch; 0 means no cap
+const DefaultMaxPages = 50
+
+// DefaultMaxResponseBytes is the default cap on response bytes read by a single paginated fetch
+const DefaultMaxResponseBytes = 32 << 20
+
+// DefaultEmptyPageRetries is how many times a page that reports has_more without
+// a next_page token is re-requested before pagination gives up
+const DefaultEmptyPageRetries = 1
+
 // Common periods that providers should support
 const (
 	Period7Days  = "7d"
@@ -34,11 +65,177 @@ const (
 	Period90Days = "90d"
 	Period1Year  = "1y"
 	PeriodAll    = "all"
+
+	// PeriodLastQuarter is the calendar quarter before the current one;
+	// "q1".."q4" name the quarters of the current year
+	PeriodLastQuarter = "last-quarter"
 )
 
-// GetPeriodTimeRange returns start and end times for common periods
-func GetPeriodTimeRange(period string) (time.Time, time.Time) {
-	endTime := time.Now()
+// MaxLookback is the longest time range that can be requested (5 years)
+const MaxLookback = 1825 * 24 * time.Hour
+
+// timeNow returns the current time; tests can replace it to pin the clock
+var timeNow = time.Now
+
+// ParseSince parses a Go duration such as "72h" and checks that it is
+// positive and no longer than MaxLookback
+func ParseSince(since string) (time.Duration, error) {
+	d, err := time.ParseDuration(since)
+	if err != nil {
+		return 0, utils.NewValidationError("since", fmt.Sprintf("invalid duration: %s. Use a Go duration such as 36h or 90m", since))
+	}
+	if d <= 0 {
+		return 0, utils.NewValidationError("since", "duration must be positive")
+	}
+	if d > MaxLookback {
+		return 0, utils.NewValidationError("since", fmt.Sprintf("duration %s exceeds the maximum of %s (5 years)", since, MaxLookback))
+	}
+	return d, nil
+}
+
+// IsQuarterPeriod reports whether period names a calendar quarter: "q1".."q4"
+// or PeriodLastQuarter
+func IsQuarterPeriod(period string) bool {
+	switch period {
+	case "q1", "q2", "q3", "q4", PeriodLastQuarter:
+		return true
+	}
+	return false
+}
+
+// QuarterRange returns the UTC boundaries of a quarter period. A past quarter
+// runs from its first day to the first day of the next quarter; the current
+// quarter ends now. Quarters of the current year that haven't started yet are
+// rejected.
+func QuarterRange(period string) (time.Time, time.Time, error) {
+	return quarterRange(period, timeNow())
+}
+
+// quarterRange is QuarterRange relative to now
+func quarterRange(period string, now time.Time) (time.Time, time.Time, error) {
+	now = now.UTC()
+	year := now.Year()
+	current := (int(now.Month())-1)/3 + 1
+
+	var quarter int
+	switch period {
+	case "q1", "q2", "q3", "q4":
+		quarter = int(period[1] - '0')
+	case PeriodLastQuarter:
+		quarter = current - 1
+		if quarter == 0 {
+			quarter = 4
+			year--
+		}
+	default:
+		return time.Time{}, time.Time{}, utils.NewValidationError("period", fmt.Sprintf("invalid quarter: %s", period))
+	}
+	if year == now.Year() && quarter > current {
+		return time.Time{}, time.Time{}, utils.NewValidationError("period", fmt.Sprintf("%s %d has not started yet", period, year))
+	}
+
+	start := time.Date(year, time.Month(3*(quarter-1)+1), 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 3, 0)
+	if end.After(now) {
+		end = now
+	}
+	return start, end, nil
+}
+
+// DateRangeSeparator joins the bounds of a date range period, e.g. "2024-01-01..2024-01-31"
+const DateRangeSeparator = ".."
+
+// dateLayout is the layout of date-only range bounds
+const dateLayout = "2006-01-02"
+
+// IsDateRange reports whether period is a date range such as "2024-01-01..2024-01-31"
+func IsDateRange(period string) bool {
+	return strings.Contains(period, DateRangeSeparator)
+}
+
+// ParseDateRange parses a date range period "FROM..TO". Each bound is a UTC
+// date (YYYY-MM-DD) or an RFC3339 time; a date as TO includes that whole day.
+// An empty TO (or one in the future) ends now. There is no API for when an
+// account started, so the oldest data OpenAI keeps, MaxLookback before now,
+// stands in for it: an empty FROM starts there and an earlier FROM is rejected.
+func ParseDateRange(spec string) (time.Time, time.Time, error) {
+	from, to, ok := strings.Cut(spec, DateRangeSeparator)
+	if !ok {
+		return time.Time{}, time.Time{}, fmt.Errorf("invalid date range %q: use FROM..TO", spec)
+	}
+	now := timeNow().UTC()
+
+	end := now
+	if to != "" {
+		t, dateOnly, err := parseRangeBound(to)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end %q: use YYYY-MM-DD or RFC3339", to)
+		}
+		if dateOnly {
+			// The end date is inclusive, so the range runs to the following midnight
+			t = t.AddDate(0, 0, 1)
+		}
+		if t.Before(end) {
+			end = t
+		}
+	}
+
+	earliest := now.Add(-MaxLookback)
+	start := earliest
+	if from != "" {
+		t, _, err := parseRangeBound(from)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid start %q: use YYYY-MM-DD or RFC3339", from)
+		}
+		if t.Before(earliest) {
+			return time.Time{}, time.Time{}, fmt.Errorf("start %s is more than %s (5 years) ago, the maximum lookback", from, MaxLookback)
+		}
+		start = t
+	}
+
+	if !start.Before(now) {
+		return time.Time{}, time.Time{}, fmt.Errorf("start %s is in the future", from)
+	}
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("start %s is not before end %s", from, to)
+	}
+	return start, end, nil
+}
+
+// parseRangeBound parses a date or RFC3339 time, reporting whether it was a date
+func parseRangeBound(s string) (time.Time, bool, error) {
+	if t, err := time.Parse(dateLayout, s); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	return t.UTC(), false, err
+}
+
+// GetPeriodTimeRange returns start and end times for common periods.
+// A Go duration accepted by ParseSince (e.g. "36h") covers that long up to now,
+// quarter periods cover the calendar quarter (see QuarterRange), and date
+// ranges cover exactly the dates given (see ParseDateRange).
+// With completeDays the range keeps its length but ends at the previous UTC midnight
+// instead of now; a quarter keeps its start and only drops the partial current day.
+func GetPeriodTimeRange(period string, completeDays bool) (time.Time, time.Time) {
+	if IsDateRange(period) {
+		if start, end, err := ParseDateRange(period); err == nil {
+			return start, end
+		}
+	}
+	if IsQuarterPeriod(period) {
+		if start, end, err := QuarterRange(period); err == nil {
+			if midnight := timeNow().UTC().Truncate(24 * time.Hour); completeDays && end.After(midnight) {
+				end = midnight
+			}
+			return start, end
+		}
+	}
+
+	endTime := timeNow()
+	if completeDays {
+		endTime = endTime.UTC().Truncate(24 * time.Hour)
+	}
 	var startTime time.Time
 
 	switch period {
@@ -55,9 +252,52 @@ func GetPeriodTimeRange(period string) (time.Time, time.Time) {
 	case "all":
 		startTime = endTime.AddDate(0, 0, -1825) // Last 5 years (maximum practical limit)
 	default:
-		// Default to 7 days if period is not recognized
-		startTime = endTime.AddDate(0, 0, -7)
+		if d, err := ParseSince(period); err == nil {
+			startTime = endTime.Add(-d)
+		} else {
+			// Default to 7 days if period is not recognized
+			startTime = endTime.AddDate(0, 0, -7)
+		}
 	}
 
 	return startTime, endTime
 }
+
+// completedDays returns the UTC midnights starting the whole days within
+// [start, end) that ended before now's UTC day began
+func completedDays(start, end, now time.Time) []time.Time {
+	today := now.UTC().Truncate(24 * time.Hour)
+	if end.After(today) {
+		end = today
+	}
+	day := start.UTC().Truncate(24 * time.Hour)
+	if day.Before(start) {
+		day = day.Add(24 * time.Hour)
+	}
+
+	var days []time.Time
+	for ; !day.Add(24 * time.Hour).After(end); day = day.Add(24 * time.Hour) {
+		days = append(days, day)
+	}
+	return days
+}
+
+// chunkRange splits the range [start, end) into sequential sub-ranges of at most
+// maxDays days each. The final chunk ends exactly at end.
+func chunkRange(start, end time.Time, maxDays int) [][2]time.Time {
+	if maxDays <= 0 || !end.After(start) {
+		return [][2]time.Time{{start, end}}
+	}
+
+	var chunks [][2]time.Time
+	for chunkStart := start; chunkStart.Before(end); {
+		chunkEnd := chunkStart.AddDate(0, 0, maxDays)
+		if chunkEnd.After(end) {
+			chunkEnd = end
+		}
+		chunks = append(chunks, [2]time.Time{chunkStart, chunkEnd})
+		chunkStart = chunkEnd
+	}
+
+	return chunks
+}
@@ -0,0 +1,231 @@
+package providers
+
+import (
+	"testing"
+	"time"
+)
+
+// day returns midnight UTC on the given date
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestChunkRange(t *testing.T) {
+	start := day(2024, 1, 1)
+	tests := []struct {
+		name    string
+		end     time.Time
+		maxDays int
+		want    [][2]time.Time
+	}{
+		{
+			name:    "exact multiple",
+			end:     day(2024, 1, 7),
+			maxDays: 3,
+			want:    [][2]time.Time{{start, day(2024, 1, 4)}, {day(2024, 1, 4), day(2024, 1, 7)}},
+		},
+		{
+			name:    "short final chunk ends at end",
+			end:     day(2024, 1, 8).Add(6 * time.Hour),
+			maxDays: 3,
+			want: [][2]time.Time{
+				{start, day(2024, 1, 4)},
+				{day(2024, 1, 4), day(2024, 1, 7)},
+				{day(2024, 1, 7), day(2024, 1, 8).Add(6 * time.Hour)},
+			},
+		},
+		{
+			name:    "shorter than one chunk",
+			end:     day(2024, 1, 2),
+			maxDays: 30,
+			want:    [][2]time.Time{{start, day(2024, 1, 2)}},
+		},
+		{
+			name:    "no limit",
+			end:     day(2024, 6, 1),
+			maxDays: 0,
+			want:    [][2]time.Time{{start, day(2024, 6, 1)}},
+		},
+		{
+			name:    "empty range",
+			end:     start,
+			maxDays: 3,
+			want:    [][2]time.Time{{start, start}},
+		},
+	}
+	for _, tt := range tests {
+		got := chunkRange(start, tt.end, tt.maxDays)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %d chunks %v, want %d", tt.name, len(got), got, len(tt.want))
+			continue
+		}
+		for i := range tt.want {
+			if !got[i][0].Equal(tt.want[i][0]) || !got[i][1].Equal(tt.want[i][1]) {
+				t.Errorf("%s: chunk %d = %v, want %v", tt.name, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestCompletedDays(t *testing.T) {
+	now := day(2024, 3, 10).Add(15 * time.Hour)
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       []time.Time
+	}{
+		{
+			name:  "whole days before today",
+			start: day(2024, 3, 7),
+			end:   now,
+			want:  []time.Time{day(2024, 3, 7), day(2024, 3, 8), day(2024, 3, 9)},
+		},
+		{
+			name:  "partial first day is skipped",
+			start: day(2024, 3, 7).Add(12 * time.Hour),
+			end:   day(2024, 3, 10),
+			want:  []time.Time{day(2024, 3, 8), day(2024, 3, 9)},
+		},
+		{
+			name:  "partial last day is skipped",
+			start: day(2024, 3, 7),
+			end:   day(2024, 3, 8).Add(12 * time.Hour),
+			want:  []time.Time{day(2024, 3, 7)},
+		},
+		{
+			name:  "today only",
+			start: day(2024, 3, 10),
+			end:   now,
+		},
+	}
+	for _, tt := range tests {
+		got := completedDays(tt.start, tt.end, now)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range tt.want {
+			if !got[i].Equal(tt.want[i]) {
+				t.Errorf("%s: day %d = %v, want %v", tt.name, i, got[i], tt.want[i])
+			}
+		}
+	}
+}
+
+func TestQuarterRange(t *testing.T) {
+	now := day(2024, 5, 15).Add(9 * time.Hour)
+	tests := []struct {
+		period     string
+		start, end time.Time
+		wantErr    bool
+	}{
+		{period: "q1", start: day(2024, 1, 1), end: day(2024, 4, 1)},
+		{period: "q2", start: day(2024, 4, 1), end: now}, // the current quarter ends now
+		{period: "q3", wantErr: true},                    // not started yet
+		{period: PeriodLastQuarter, start: day(2024, 1, 1), end: day(2024, 4, 1)},
+		{period: "q5", wantErr: true},
+	}
+	for _, tt := range tests {
+		start, end, err := quarterRange(tt.period, now)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: got %v..%v, want an error", tt.period, start, end)
+			}
+			continue
+		}
+		if err != nil || !start.Equal(tt.start) || !end.Equal(tt.end) {
+			t.Errorf("%s: got %v..%v, %v; want %v..%v", tt.period, start, end, err, tt.start, tt.end)
+		}
+	}
+
+	// Last quarter in Q1 is Q4 of the previous year
+	start, end, err := quarterRange(PeriodLastQuarter, day(2024, 2, 1))
+	if err != nil || !start.Equal(day(2023, 10, 1)) || !end.Equal(day(2024, 1, 1)) {
+		t.Errorf("last-quarter in February: got %v..%v, %v; want Q4 2023", start, end, err)
+	}
+}
+
+func TestParseDateRange(t *testing.T) {
+	now := day(2024, 3, 10).Add(15 * time.Hour)
+	pinNow(t, now)
+
+	tests := []struct {
+		spec       string
+		start, end time.Time
+		wantErr    bool
+	}{
+		// A date as the end includes that whole day
+		{spec: "2024-01-01..2024-01-31", start: day(2024, 1, 1), end: day(2024, 2, 1)},
+		{spec: "2024-01-01T06:00:00Z..2024-01-02T12:00:00Z", start: day(2024, 1, 1).Add(6 * time.Hour), end: day(2024, 1, 2).Add(12 * time.Hour)},
+		// An open or future end stops at now
+		{spec: "2024-03-01..", start: day(2024, 3, 1), end: now},
+		{spec: "2024-03-01..2024-12-31", start: day(2024, 3, 1), end: now},
+		// An open start reaches back MaxLookback from now, and no further start is allowed
+		{spec: "..2024-01-31", start: now.Add(-MaxLookback), end: day(2024, 2, 1)},
+		{spec: "1990-01-01..2024-01-31", wantErr: true},
+		{spec: "2019-01-01..", wantErr: true},
+		{spec: "2024-01-01", wantErr: true},
+		{spec: "2024-13-01..", wantErr: true},
+		{spec: "2024-01-01..soon", wantErr: true},
+		{spec: "2024-04-01..", wantErr: true}, // starts in the future
+		{spec: "2024-02-01..2024-01-01", wantErr: true},
+	}
+	for _, tt := range tests {
+		start, end, err := ParseDateRange(tt.spec)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q: got %v..%v, want an error", tt.spec, start, end)
+			}
+			continue
+		}
+		if err != nil || !start.Equal(tt.start) || !end.Equal(tt.end) {
+			t.Errorf("%q: got %v..%v, %v; want %v..%v", tt.spec, start, end, err, tt.start, tt.end)
+		}
+	}
+}
+
+func TestGetPeriodTimeRangeCompleteDays(t *testing.T) {
+	now := day(2024, 3, 10).Add(15 * time.Hour)
+	pinNow(t, now)
+
+	start, end := GetPeriodTimeRange("7d", false)
+	if !end.Equal(now) || !start.Equal(now.AddDate(0, 0, -7)) {
+		t.Errorf("7d = %v..%v, want the 7 days up to now", start, end)
+	}
+
+	start, end = GetPeriodTimeRange("7d", true)
+	if !end.Equal(day(2024, 3, 10)) || !start.Equal(day(2024, 3, 3)) {
+		t.Errorf("7d with complete days = %v..%v, want 2024-03-03..2024-03-10", start, end)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := day(2024, 3, 10).Add(15 * time.Hour)
+	pinNow(t, now)
+
+	for _, since := range []string{"36h", "90m", "72h30m"} {
+		d, err := ParseSince(since)
+		if err != nil {
+			t.Errorf("ParseSince(%q): %v", since, err)
+			continue
+		}
+		start, end := GetPeriodTimeRange(since, false)
+		if !end.Equal(now) || !start.Equal(now.Add(-d)) || end.Sub(start) != d {
+			t.Errorf("%q = %v..%v, want the %v up to %v", since, start, end, d, now)
+		}
+	}
+
+	start, _ := GetPeriodTimeRange("36h", false)
+	if want := day(2024, 3, 9).Add(3 * time.Hour); !start.Equal(want) {
+		t.Errorf("36h starts at %v, want %v", start, want)
+	}
+	if d, err := ParseSince(MaxLookback.String()); err != nil || d != MaxLookback {
+		t.Errorf("ParseSince(MaxLookback) = %v, %v; want it accepted", d, err)
+	}
+
+	for _, since := range []string{"0s", "0", "-1h", (MaxLookback + time.Hour).String(), "3d", "soon", ""} {
+		if d, err := ParseSince(since); err == nil {
+			t.Errorf("ParseSince(%q) = %v, want an error", since, d)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+
+	"tokenwatch/pkg/utils"
+)
+
+// sendTimeout bounds how long a single telemetry request may take
+const sendTimeout = 2 * time.Second
+
+// Event is an anonymized, aggregate record of a single command run.
+// It deliberately carries no API keys, organization IDs, or token/cost data.
+type Event struct {
+	Command string `json:"command"`
+	Period  string `json:"period,omitempty"`
+	Success bool   `json:"success"`
+	Version string `json:"version"`
+	OS      string `json:"os"`
+	Arch    string `json:"arch"`
+}
+
+// NewEvent creates an event for a command run on the current platform
+func NewEvent(command, period string, success bool, version string) Event {
+	return Event{
+		Command: command,
+		Period:  period,
+		Success: success,
+		Version: version,
+		OS:      runtime.GOOS,
+		Arch:    runtime.GOARCH,
+	}
+}
+
+// Client sends events to the configured endpoint. It is a no-op unless
+// telemetry is enabled and an endpoint is configured.
+type Client struct {
+	enabled  bool
+	endpoint string
+	client   *utils.RateLimitedClient
+	wg       sync.WaitGroup
+}
+
+// NewClient creates a new telemetry client
+func NewClient(enabled bool, endpoint string) *Client {
+	// One request per run at most, and never retried
+	retryConfig := utils.RetryConfig{
+		MaxRetries:     0,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Second,
+		BackoffFactor:  1.0,
+	}
+
+	return &Client{
+		enabled:  enabled,
+		endpoint: endpoint,
+		client:   utils.NewRateLimitedClientWithConfig(1.0, 1, sendTimeout, retryConfig),
+	}
+}
+
+// IsEnabled reports whether events will actually be sent
+func (c *Client) IsEnabled() bool {
+	return c.enabled && c.endpoint != ""
+}
+
+// Send posts the event in the background. Failures are logged at debug level
+// and never affect the command being run.
+func (c *Client) Send(event Event) {
+	if !c.IsEnabled() {
+		return
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		if err := c.post(event); err != nil {
+			utils.Debug("Telemetry not sent", map[string]interface{}{
+				"error": err.Error(),
+			})
+		}
+	}()
+}
+
+// Wait blocks until pending events are sent or the timeout elapses
+func (c *Client) Wait(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		c.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout):
+	}
+}
+
+// post sends a single event to the endpoint
+func (c *Client) post(event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
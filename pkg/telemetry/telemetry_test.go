@@ -0,0 +1,63 @@
+package telemetry
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// trackedBody records whether it was closed
+type trackedBody struct {
+	io.Reader
+	closed *atomic.Bool
+}
+
+func (b trackedBody) Close() error {
+	b.closed.Store(true)
+	return nil
+}
+
+// statusTransport answers every request with status and a body that tracks Close
+type statusTransport struct {
+	status int
+	closed atomic.Bool
+}
+
+func (t *statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{
+		StatusCode: t.status,
+		Header:     make(http.Header),
+		Body:       trackedBody{Reader: strings.NewReader("error"), closed: &t.closed},
+		Request:    req,
+	}, nil
+}
+
+func TestPostClosesBodyOnServerError(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusInternalServerError, http.StatusServiceUnavailable} {
+		transport := &statusTransport{status: status}
+		c := NewClient(true, "http://telemetry.invalid/events")
+		c.client.SetTransport(transport)
+
+		err := c.post(NewEvent("tokenwatch usage", "7d", true, "v0.0.0"))
+		if status >= 500 && err == nil {
+			t.Errorf("status %d: expected an error", status)
+		}
+		if !transport.closed.Load() {
+			t.Errorf("status %d: response body was not closed", status)
+		}
+	}
+}
+
+func TestDisabledClientSendsNothing(t *testing.T) {
+	transport := &statusTransport{status: http.StatusOK}
+	c := NewClient(false, "http://telemetry.invalid/events")
+	c.client.SetTransport(transport)
+
+	c.Send(NewEvent("tokenwatch usage", "", true, "v0.0.0"))
+	c.Wait(sendTimeout)
+	if transport.closed.Load() {
+		t.Error("a disabled client sent an event")
+	}
+}
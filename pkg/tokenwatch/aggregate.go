@@ -0,0 +1,287 @@
+package tokenwatch
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"tokenwatch/pkg/models"
+	"tokenwatch/pkg/money"
+	"tokenwatch/pkg/providers"
+)
+
+// CalculateTotals computes the totals across all models
+func CalculateTotals(stats []models.ModelStats) models.TotalStats {
+	var totals models.TotalStats
+	var cost, inputCost, outputCost money.Amount
+
+	for _, m := range stats {
+		if totals.Currency == "" {
+			totals.Currency = m.Currency
+		}
+		totals.TotalInput += m.InputTokens
+		totals.TotalCached += m.CachedTokens
+		totals.TotalOutput += m.OutputTokens
+		totals.TotalTokens += m.TotalTokens
+		totals.TotalRequests += m.Requests
+		cost = cost.AddFloat(m.Cost)
+		inputCost = inputCost.AddFloat(m.InputCost)
+		outputCost = outputCost.AddFloat(m.OutputCost)
+	}
+	totals.TotalCost = cost.Float()
+	totals.TotalInputCost = inputCost.Float()
+	totals.TotalOutputCost = outputCost.Float()
+
+	return totals
+}
+
+// DetermineCostStatus classifies cost data as billed, free tier, or unavailable.
+// pricingAvailable is false when the costs API could not be queried.
+func DetermineCostStatus(period string, totalCost float64, pricingAvailable bool) string {
+	switch {
+	case totalCost > 0:
+		return models.CostStatusBilled
+	case !pricingAvailable || period == "30d":
+		return models.CostStatusUnavailable
+	default:
+		return models.CostStatusFreeTier
+	}
+}
+
+// UnknownModel labels usage and costs that arrive without a model name
+const UnknownModel = "(unknown)"
+
+// snapshotSuffix matches the date suffix of a model snapshot, e.g. "-2024-08-06" or "-0613"
+var snapshotSuffix = regexp.MustCompile(`-(\d{4}-\d{2}-\d{2}|\d{4})$`)
+
+// NormalizeModelName maps a model name to the key its usage and costs are merged
+// under. The usage API reports snapshots ("gpt-4o-2024-08-06") where the costs API
+// may report the base model ("gpt-4o"), so snapshot suffixes are dropped. Empty or
+// whitespace-only names map to UnknownModel so they are grouped together instead
+// of showing as blank rows.
+func NormalizeModelName(model string) string {
+	model = strings.ToLower(strings.TrimSpace(model))
+	if model == "" {
+		return UnknownModel
+	}
+	return snapshotSuffix.ReplaceAllString(model, "")
+}
+
+// AggregateModelStats merges consumption and pricing records into per-model stats,
+// sorted by total tokens (descending) and then by cost
+func AggregateModelStats(consumptions []*models.Consumption, pricings []*models.Pricing) ([]models.ModelStats, error) {
+	// Amounts in different currencies can't be summed, so all costs must share one
+	currency, err := CommonCurrency(pricings)
+	if err != nil {
+		return nil, err
+	}
+
+	// Accounts report the same few models in thousands of buckets, so each raw
+	// name and line item is parsed once and every record updates its model in place
+	names := make(map[string]string)
+	normalize := func(model string) string {
+		name, ok := names[model]
+		if !ok {
+			name = NormalizeModelName(model)
+			names[model] = name
+		}
+		return name
+	}
+	// Costs are summed exactly and converted to float64 at the end
+	type modelEntry struct {
+		stats               models.ModelStats
+		cost, input, output money.Amount
+	}
+	modelMap := make(map[string]*modelEntry)
+	entryFor := func(model string) *modelEntry {
+		entry, ok := modelMap[model]
+		if !ok {
+			entry = &modelEntry{stats: models.ModelStats{Model: model}}
+			modelMap[model] = entry
+		}
+		return entry
+	}
+
+	for _, c := range consumptions {
+		stats := &entryFor(normalize(c.Model)).stats
+		stats.InputTokens += c.InputTokens
+		stats.CachedTokens += c.CachedTokens
+		stats.OutputTokens += c.OutputTokens
+		stats.TotalTokens += c.TotalTokens
+		stats.Requests += c.RequestCount
+	}
+
+	// Add pricing data, splitting input and output costs. Models with costs but
+	// no usage get an entry too (shouldn't happen normally).
+	directions := make(map[string]string)
+	for _, p := range pricings {
+		entry := entryFor(normalize(p.Model))
+		entry.cost = entry.cost.AddFloat(p.Amount)
+		entry.stats.Currency = currency
+
+		direction, ok := directions[p.LineItem]
+		if !ok {
+			direction = models.CostDirection(models.LineItemType(p.LineItem))
+			directions[p.LineItem] = direction
+		}
+		switch direction {
+		case "input":
+			entry.input = entry.input.AddFloat(p.Amount)
+		case "output":
+			entry.output = entry.output.AddFloat(p.Amount)
+		}
+	}
+
+	// Convert to slice and sort by total tokens (descending)
+	result := make([]models.ModelStats, 0, len(modelMap))
+	for _, entry := range modelMap {
+		stats := entry.stats
+		stats.Cost = entry.cost.Float()
+		stats.InputCost = entry.input.Float()
+		stats.OutputCost = entry.output.Float()
+		// Include models that have either tokens or costs (including credits)
+		if stats.TotalTokens > 0 || stats.Cost != 0 {
+			result = append(result, stats)
+		}
+	}
+	SortModelStats(result)
+
+	return result, nil
+}
+
+// AggregateBuckets merges consumption and pricing records into per-model stats
+// for each time bucket, sorted by bucket start and then by model. Records are
+// matched to a bucket by their start time.
+func AggregateBuckets(consumptions []*models.Consumption, pricings []*models.Pricing) ([]models.BucketStats, error) {
+	// Amounts in different currencies can't be summed, so all costs must share one
+	if _, err := CommonCurrency(pricings); err != nil {
+		return nil, err
+	}
+
+	type bucketKey struct {
+		start int64
+		model string
+	}
+	buckets := make(map[bucketKey]*models.BucketStats)
+	bucket := func(start, end time.Time, model string) *models.BucketStats {
+		model = NormalizeModelName(model)
+		key := bucketKey{start: start.Unix(), model: model}
+		if b, ok := buckets[key]; ok {
+			return b
+		}
+		b := &models.BucketStats{StartTime: start.UTC(), EndTime: end.UTC(), Model: model}
+		buckets[key] = b
+		return b
+	}
+
+	for _, c := range consumptions {
+		b := bucket(c.StartTime, c.EndTime, c.Model)
+		b.InputTokens += c.InputTokens
+		b.OutputTokens += c.OutputTokens
+		b.TotalTokens += c.TotalTokens
+		b.Requests += c.RequestCount
+	}
+	costs := make(map[*models.BucketStats]money.Amount)
+	for _, p := range pricings {
+		b := bucket(p.StartTime, p.EndTime, p.Model)
+		costs[b] = costs[b].AddFloat(p.Amount)
+	}
+
+	result := make([]models.BucketStats, 0, len(buckets))
+	for _, b := range buckets {
+		b.Cost = costs[b].Float()
+		result = append(result, *b)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].StartTime.Equal(result[j].StartTime) {
+			return result[i].StartTime.Before(result[j].StartTime)
+		}
+		return result[i].Model < result[j].Model
+	})
+	return result, nil
+}
+
+// NoGroupValue labels usage without a project or API key when grouping by them
+const NoGroupValue = "(none)"
+
+// AggregateUsageGroups sums consumptions by the groupBy dimensions
+// (providers.GroupByModel, GroupByProject, GroupByAPIKey), sorted by total
+// tokens (descending) and then by values. Model names are normalized as in
+// AggregateModelStats.
+func AggregateUsageGroups(consumptions []*models.Consumption, groupBy []string) []models.UsageGroup {
+	groups := make(map[string]*models.UsageGroup)
+	var order []*models.UsageGroup
+	for _, c := range consumptions {
+		values := make([]string, len(groupBy))
+		for i, dim := range groupBy {
+			switch dim {
+			case providers.GroupByModel:
+				values[i] = NormalizeModelName(c.Model)
+			case providers.GroupByProject:
+				values[i] = c.ProjectID
+			case providers.GroupByAPIKey:
+				values[i] = c.APIKeyID
+			}
+			if values[i] == "" {
+				values[i] = NoGroupValue
+			}
+		}
+
+		// Values can't contain a NUL, so it safely separates them in the key
+		key := strings.Join(values, "\x00")
+		g, ok := groups[key]
+		if !ok {
+			g = &models.UsageGroup{Values: values}
+			groups[key] = g
+			order = append(order, g)
+		}
+		g.InputTokens += c.InputTokens
+		g.OutputTokens += c.OutputTokens
+		g.TotalTokens += c.TotalTokens
+		g.Requests += c.RequestCount
+	}
+
+	result := make([]models.UsageGroup, 0, len(order))
+	for _, g := range order {
+		result = append(result, *g)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TotalTokens != result[j].TotalTokens {
+			return result[i].TotalTokens > result[j].TotalTokens
+		}
+		return strings.Join(result[i].Values, "\x00") < strings.Join(result[j].Values, "\x00")
+	})
+	return result
+}
+
+// CommonCurrency returns the upper-cased currency shared by all pricings, or an
+// error if they mix currencies. Pricings without a currency are ignored.
+func CommonCurrency(pricings []*models.Pricing) (string, error) {
+	var currency string
+	for _, p := range pricings {
+		c := strings.TrimSpace(p.Currency)
+		// Compare before upper-casing, which allocates, since most records match
+		if c == "" || strings.EqualFold(c, currency) {
+			continue
+		}
+		c = strings.ToUpper(c)
+		if currency == "" {
+			currency = c
+		} else {
+			return "", fmt.Errorf("cost data mixes currencies (%s and %s), which can't be summed", currency, c)
+		}
+	}
+	return currency, nil
+}
+
+// SortModelStats sorts by total tokens (descending) and then by cost
+func SortModelStats(stats []models.ModelStats) {
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].TotalTokens != stats[j].TotalTokens {
+			return stats[i].TotalTokens > stats[j].TotalTokens
+		}
+		return stats[i].Cost > stats[j].Cost
+	})
+}
@@ -0,0 +1,165 @@
+package tokenwatch
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"tokenwatch/pkg/models"
+	"tokenwatch/pkg/money"
+)
+
+// syntheticRecords builds usage and cost records for a large account: a few
+// models, including snapshot names, reported across many daily buckets
+func syntheticRecords(usageCount, costCount int) ([]*models.Consumption, []*models.Pricing) {
+	names := []string{"gpt-4o-2024-08-06", "gpt-4o", "GPT-4o-mini", "o1-2024-12-17", "text-embedding-3-small", " ", "gpt-3.5-turbo-0613"}
+	lineItems := []string{"input", "output", "cached input", "batch output", "fine-tuning"}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	consumptions := make([]*models.Consumption, usageCount)
+	for i := range consumptions {
+		day := start.AddDate(0, 0, i%365)
+		consumptions[i] = &models.Consumption{
+			Model:        names[i%len(names)],
+			InputTokens:  int64(100 + i%97),
+			CachedTokens: int64(i % 13),
+			OutputTokens: int64(50 + i%31),
+			TotalTokens:  int64(150 + i%97 + i%31),
+			RequestCount: int64(1 + i%5),
+			StartTime:    day,
+			EndTime:      day.AddDate(0, 0, 1),
+		}
+	}
+
+	pricings := make([]*models.Pricing, costCount)
+	for i := range pricings {
+		day := start.AddDate(0, 0, i%365)
+		name := names[i%len(names)]
+		pricings[i] = &models.Pricing{
+			Model:     name,
+			LineItem:  fmt.Sprintf("%s, %s", name, lineItems[i%len(lineItems)]),
+			Amount:    0.0001 * float64(1+i%1000),
+			Currency:  "usd",
+			StartTime: day,
+			EndTime:   day.AddDate(0, 0, 1),
+		}
+	}
+	return consumptions, pricings
+}
+
+// aggregateReference is the straightforward per-record form of
+// AggregateModelStats, kept to check the optimized version against
+func aggregateReference(consumptions []*models.Consumption, pricings []*models.Pricing) ([]models.ModelStats, error) {
+	currency, err := CommonCurrency(pricings)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make(map[string]*models.ModelStats)
+	cost := make(map[string]money.Amount)
+	input := make(map[string]money.Amount)
+	output := make(map[string]money.Amount)
+	get := func(model string) *models.ModelStats {
+		if s, ok := stats[model]; ok {
+			return s
+		}
+		s := &models.ModelStats{Model: model}
+		stats[model] = s
+		return s
+	}
+
+	for _, c := range consumptions {
+		s := get(NormalizeModelName(c.Model))
+		s.InputTokens += c.InputTokens
+		s.CachedTokens += c.CachedTokens
+		s.OutputTokens += c.OutputTokens
+		s.TotalTokens += c.TotalTokens
+		s.Requests += c.RequestCount
+	}
+	for _, p := range pricings {
+		model := NormalizeModelName(p.Model)
+		get(model).Currency = currency
+		cost[model] = cost[model].AddFloat(p.Amount)
+		switch models.CostDirection(models.LineItemType(p.LineItem)) {
+		case "input":
+			input[model] = input[model].AddFloat(p.Amount)
+		case "output":
+			output[model] = output[model].AddFloat(p.Amount)
+		}
+	}
+
+	var result []models.ModelStats
+	for model, s := range stats {
+		s.Cost = cost[model].Float()
+		s.InputCost = input[model].Float()
+		s.OutputCost = output[model].Float()
+		if s.TotalTokens > 0 || s.Cost != 0 {
+			result = append(result, *s)
+		}
+	}
+	SortModelStats(result)
+	return result, nil
+}
+
+func TestAggregateModelStatsMatchesReference(t *testing.T) {
+	consumptions, pricings := syntheticRecords(3000, 6000)
+
+	got, err := AggregateModelStats(consumptions, pricings)
+	if err != nil {
+		t.Fatalf("AggregateModelStats: %v", err)
+	}
+	want, err := aggregateReference(consumptions, pricings)
+	if err != nil {
+		t.Fatalf("aggregateReference: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("AggregateModelStats differs from the reference\n got: %+v\nwant: %+v", got, want)
+	}
+
+	// Snapshots merge into their base model and blank names into UnknownModel
+	seen := make(map[string]bool)
+	for _, s := range got {
+		seen[s.Model] = true
+	}
+	for _, model := range []string{"gpt-4o", "gpt-4o-mini", "o1", "gpt-3.5-turbo", UnknownModel} {
+		if !seen[model] {
+			t.Errorf("missing model %q in %v", model, seen)
+		}
+	}
+	if len(got) != 6 {
+		t.Errorf("got %d models, want 6", len(got))
+	}
+}
+
+func TestAggregateModelStatsMixedCurrencies(t *testing.T) {
+	pricings := []*models.Pricing{
+		{Model: "gpt-4o", Amount: 1, Currency: "usd"},
+		{Model: "gpt-4o", Amount: 1, Currency: "EUR"},
+	}
+	if _, err := AggregateModelStats(nil, pricings); err == nil {
+		t.Error("expected an error for mixed currencies")
+	}
+}
+
+func BenchmarkAggregateModelStats(b *testing.B) {
+	consumptions, pricings := syntheticRecords(30000, 60000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := AggregateModelStats(consumptions, pricings); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAggregateModelStatsReference(b *testing.B) {
+	consumptions, pricings := syntheticRecords(30000, 60000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := aggregateReference(consumptions, pricings); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package tokenwatch
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"tokenwatch/pkg/export"
+	"tokenwatch/pkg/models"
+)
+
+// budgetMonth is the period a model budget covers
+const budgetMonth = 30 * 24 * time.Hour
+
+// PeriodBudget scales a monthly budget to the length of startTime..endTime, so
+// a 7-day report is checked against 7/30 of the monthly amount
+func PeriodBudget(monthly float64, startTime, endTime time.Time) float64 {
+	return monthly * float64(endTime.Sub(startTime)) / float64(budgetMonth)
+}
+
+// CheckModelBudgets returns the models whose cost between startTime and endTime
+// exceeds their monthly budget scaled to that range, ordered by how far they
+// are over. Budget keys are matched after NormalizeModelName, so a budget for
+// "gpt-4o" also covers its snapshots. Models without a budget are never flagged.
+func CheckModelBudgets(stats []models.ModelStats, budgets map[string]float64, startTime, endTime time.Time) []export.BudgetBreach {
+	if len(budgets) == 0 {
+		return nil
+	}
+
+	monthly := make(map[string]float64, len(budgets))
+	for model, amount := range budgets {
+		monthly[NormalizeModelName(model)] += amount
+	}
+
+	var breaches []export.BudgetBreach
+	for _, m := range stats {
+		amount, ok := monthly[m.Model]
+		if !ok {
+			continue
+		}
+		budget := PeriodBudget(amount, startTime, endTime)
+		if m.Cost > budget {
+			breaches = append(breaches, export.BudgetBreach{
+				Model:         m.Model,
+				Cost:          m.Cost,
+				Budget:        budget,
+				MonthlyBudget: amount,
+			})
+		}
+	}
+
+	sort.SliceStable(breaches, func(i, j int) bool {
+		return breaches[i].Cost-breaches[i].Budget > breaches[j].Cost-breaches[j].Budget
+	})
+	return breaches
+}
+
+// BudgetBreachMessage describes a breach for warnings
+func BudgetBreachMessage(b export.BudgetBreach) string {
+	return fmt.Sprintf("%s cost $%.2f, over its $%.2f budget for this period ($%.2f/month)", b.Model, b.Cost, b.Budget, b.MonthlyBudget)
+}
@@ -0,0 +1,93 @@
+package tokenwatch
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"tokenwatch/pkg/models"
+)
+
+func TestPeriodBudgetProratesMonthly(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		end  time.Time
+		want float64
+	}{
+		{"full month", start.Add(budgetMonth), 300},
+		{"one week", start.AddDate(0, 0, 7), 70},
+		{"one day", start.AddDate(0, 0, 1), 10},
+		{"half a day", start.Add(12 * time.Hour), 5},
+		{"two months", start.Add(2 * budgetMonth), 600},
+		{"empty range", start, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PeriodBudget(300, start, tt.end); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("PeriodBudget(300) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckModelBudgets(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 3) // a tenth of the budget month
+	stats := []models.ModelStats{
+		{Model: "gpt-4o", Cost: 12},         // budget 100/month -> 10 for the period
+		{Model: "gpt-4o-mini", Cost: 2},     // budget 30/month -> 3, under
+		{Model: "gpt-3.5-turbo", Cost: 4.5}, // budget 20/month -> 2, furthest over
+		{Model: "o1", Cost: 500},            // no budget
+	}
+	budgets := map[string]float64{
+		"gpt-4o":        100,
+		"GPT-4o-mini":   30,
+		"gpt-3.5-turbo": 20,
+	}
+
+	breaches := CheckModelBudgets(stats, budgets, start, end)
+	if len(breaches) != 2 {
+		t.Fatalf("got %d breaches, want 2: %+v", len(breaches), breaches)
+	}
+	// Ordered by how far over budget they are
+	if breaches[0].Model != "gpt-3.5-turbo" || breaches[1].Model != "gpt-4o" {
+		t.Errorf("breach order = %s, %s; want gpt-3.5-turbo, gpt-4o", breaches[0].Model, breaches[1].Model)
+	}
+	b := breaches[1]
+	if math.Abs(b.Budget-10) > 1e-9 || b.MonthlyBudget != 100 || b.Cost != 12 {
+		t.Errorf("gpt-4o breach = %+v, want cost 12 over a 10 budget (100/month)", b)
+	}
+	if msg := BudgetBreachMessage(b); !strings.Contains(msg, "gpt-4o cost $12.00, over its $10.00 budget") {
+		t.Errorf("BudgetBreachMessage = %q", msg)
+	}
+}
+
+func TestCheckModelBudgetsWithoutBreaches(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(budgetMonth)
+	stats := []models.ModelStats{{Model: "gpt-4o", Cost: 100}}
+
+	if got := CheckModelBudgets(stats, map[string]float64{"gpt-4o": 100}, start, end); len(got) != 0 {
+		t.Errorf("cost equal to the budget was flagged: %+v", got)
+	}
+	if got := CheckModelBudgets(stats, nil, start, end); got != nil {
+		t.Errorf("no budgets flagged %+v", got)
+	}
+}
+
+func TestCheckModelBudgetsMatchesSnapshotsAndDottedNames(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(budgetMonth)
+	stats := []models.ModelStats{
+		{Model: NormalizeModelName("gpt-3.5-turbo-0613"), Cost: 25},
+		{Model: NormalizeModelName("gpt-4.1-2025-04-14"), Cost: 5},
+	}
+	budgets := map[string]float64{"gpt-3.5-turbo": 20, "gpt-4.1": 10}
+
+	breaches := CheckModelBudgets(stats, budgets, start, end)
+	if len(breaches) != 1 || breaches[0].Model != "gpt-3.5-turbo" {
+		t.Errorf("breaches = %+v, want only gpt-3.5-turbo", breaches)
+	}
+}
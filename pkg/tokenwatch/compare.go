@@ -0,0 +1,141 @@
+package tokenwatch
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"tokenwatch/pkg/models"
+)
+
+// ModelDelta is one model's cost and tokens in two consecutive periods
+type ModelDelta struct {
+	Model          string
+	PreviousCost   float64
+	CurrentCost    float64
+	PreviousTokens int64
+	CurrentTokens  int64
+}
+
+// CostChange returns the current cost minus the previous cost
+func (d ModelDelta) CostChange() float64 {
+	return d.CurrentCost - d.PreviousCost
+}
+
+// SummaryDiff compares the per-model stats of a period with the period before it
+type SummaryDiff struct {
+	Models       []ModelDelta // sorted by cost change, largest increase first
+	PreviousCost float64
+	CurrentCost  float64
+}
+
+// CostChange returns the current total cost minus the previous total cost
+func (d SummaryDiff) CostChange() float64 {
+	return d.CurrentCost - d.PreviousCost
+}
+
+// CostChangePercent returns the total cost change as a percentage of the
+// previous cost. ok is false when the previous period cost nothing, since no
+// percentage describes growth from zero.
+func (d SummaryDiff) CostChangePercent() (percent float64, ok bool) {
+	if d.PreviousCost == 0 {
+		return 0, false
+	}
+	return d.CostChange() / d.PreviousCost * 100, true
+}
+
+// DiffSummaries pairs each model in previous and current, which must both come
+// from AggregateModelStats. Models present in only one period get zero values
+// for the other.
+func DiffSummaries(previous, current []models.ModelStats) SummaryDiff {
+	var diff SummaryDiff
+	byModel := make(map[string]*ModelDelta)
+	entry := func(model string) *ModelDelta {
+		d, ok := byModel[model]
+		if !ok {
+			d = &ModelDelta{Model: model}
+			byModel[model] = d
+		}
+		return d
+	}
+
+	for _, m := range previous {
+		d := entry(m.Model)
+		d.PreviousCost += m.Cost
+		d.PreviousTokens += m.TotalTokens
+		diff.PreviousCost += m.Cost
+	}
+	for _, m := range current {
+		d := entry(m.Model)
+		d.CurrentCost += m.Cost
+		d.CurrentTokens += m.TotalTokens
+		diff.CurrentCost += m.Cost
+	}
+
+	diff.Models = make([]ModelDelta, 0, len(byModel))
+	for _, d := range byModel {
+		diff.Models = append(diff.Models, *d)
+	}
+	sort.Slice(diff.Models, func(i, j int) bool {
+		if ci, cj := diff.Models[i].CostChange(), diff.Models[j].CostChange(); ci != cj {
+			return ci > cj
+		}
+		return diff.Models[i].Model < diff.Models[j].Model
+	})
+	return diff
+}
+
+// DeltaThreshold is the allowed growth in cost between two periods, either a
+// percentage of the previous cost or an absolute amount in USD
+type DeltaThreshold struct {
+	Value   float64
+	Percent bool
+}
+
+// ParseDeltaThreshold parses "10%" as a percentage and "5.00" (or "$5.00") as
+// an absolute amount in USD. Negative values are rejected.
+func ParseDeltaThreshold(s string) (DeltaThreshold, error) {
+	s = strings.TrimSpace(s)
+	var t DeltaThreshold
+	if strings.HasSuffix(s, "%") {
+		t.Percent = true
+		s = strings.TrimSpace(strings.TrimSuffix(s, "%"))
+	} else {
+		s = strings.TrimPrefix(s, "$")
+	}
+
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return DeltaThreshold{}, fmt.Errorf("invalid delta threshold %q: use a percentage such as 10%% or an amount in USD such as 5.00", s)
+	}
+	if value < 0 {
+		return DeltaThreshold{}, fmt.Errorf("invalid delta threshold %q: must not be negative", s)
+	}
+	t.Value = value
+	return t, nil
+}
+
+// String formats the threshold as it is written on the command line
+func (t DeltaThreshold) String() string {
+	if t.Percent {
+		return strconv.FormatFloat(t.Value, 'f', -1, 64) + "%"
+	}
+	return fmt.Sprintf("$%.2f", t.Value)
+}
+
+// Exceeded reports whether the cost in diff grew by more than the threshold.
+// A percentage threshold trips on any increase when the previous period cost
+// nothing, since any growth from zero is unbounded.
+func (t DeltaThreshold) Exceeded(diff SummaryDiff) bool {
+	change := diff.CostChange()
+	if !t.Percent {
+		return change > t.Value
+	}
+	percent, ok := diff.CostChangePercent()
+	if !ok {
+		return change > 0
+	}
+	return percent > t.Value
+}
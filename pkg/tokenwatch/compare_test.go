@@ -0,0 +1,118 @@
+package tokenwatch
+
+import (
+	"testing"
+
+	"tokenwatch/pkg/models"
+)
+
+func TestDiffSummaries(t *testing.T) {
+	previous := []models.ModelStats{
+		{Model: "gpt-4o", Cost: 10, TotalTokens: 1000},
+		{Model: "gpt-3.5-turbo", Cost: 2, TotalTokens: 500},
+	}
+	current := []models.ModelStats{
+		{Model: "gpt-4o", Cost: 15, TotalTokens: 1500},
+		{Model: "o1", Cost: 1, TotalTokens: 50},
+	}
+
+	diff := DiffSummaries(previous, current)
+	if diff.PreviousCost != 12 || diff.CurrentCost != 16 {
+		t.Errorf("totals = %v -> %v, want 12 -> 16", diff.PreviousCost, diff.CurrentCost)
+	}
+
+	want := []ModelDelta{
+		{Model: "gpt-4o", PreviousCost: 10, CurrentCost: 15, PreviousTokens: 1000, CurrentTokens: 1500},
+		{Model: "o1", CurrentCost: 1, CurrentTokens: 50},
+		{Model: "gpt-3.5-turbo", PreviousCost: 2, PreviousTokens: 500},
+	}
+	if len(diff.Models) != len(want) {
+		t.Fatalf("got %d models, want %d: %+v", len(diff.Models), len(want), diff.Models)
+	}
+	for i := range want {
+		if diff.Models[i] != want[i] {
+			t.Errorf("model %d = %+v, want %+v", i, diff.Models[i], want[i])
+		}
+	}
+}
+
+func TestParseDeltaThreshold(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    DeltaThreshold
+		wantErr bool
+	}{
+		{in: "10%", want: DeltaThreshold{Value: 10, Percent: true}},
+		{in: " 2.5 % ", want: DeltaThreshold{Value: 2.5, Percent: true}},
+		{in: "5.00", want: DeltaThreshold{Value: 5}},
+		{in: "$5", want: DeltaThreshold{Value: 5}},
+		{in: "0", want: DeltaThreshold{}},
+		{in: "-1%", wantErr: true},
+		{in: "ten", wantErr: true},
+		{in: "%", wantErr: true},
+		{in: "NaN", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDeltaThreshold(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseDeltaThreshold(%q) = %+v, want an error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ParseDeltaThreshold(%q) = %+v, %v; want %+v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestDeltaThresholdPercent(t *testing.T) {
+	threshold := DeltaThreshold{Value: 10, Percent: true}
+	tests := []struct {
+		previous, current float64
+		want              bool
+	}{
+		{previous: 100, current: 105, want: false},
+		{previous: 100, current: 110, want: false}, // exactly at the threshold
+		{previous: 100, current: 110.5, want: true},
+		{previous: 100, current: 50, want: false},
+		{previous: 0, current: 0.01, want: true}, // any increase from zero trips
+		{previous: 0, current: 0, want: false},
+	}
+	for _, tt := range tests {
+		diff := SummaryDiff{PreviousCost: tt.previous, CurrentCost: tt.current}
+		if got := threshold.Exceeded(diff); got != tt.want {
+			t.Errorf("10%% threshold, $%v -> $%v: Exceeded = %v, want %v", tt.previous, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestDeltaThresholdAbsolute(t *testing.T) {
+	threshold := DeltaThreshold{Value: 5}
+	tests := []struct {
+		previous, current float64
+		want              bool
+	}{
+		{previous: 100, current: 104, want: false},
+		{previous: 100, current: 105, want: false},
+		{previous: 100, current: 105.01, want: true},
+		{previous: 0, current: 4, want: false}, // absolute thresholds don't special-case zero
+		{previous: 0, current: 6, want: true},
+		{previous: 10, current: 0, want: false},
+	}
+	for _, tt := range tests {
+		diff := SummaryDiff{PreviousCost: tt.previous, CurrentCost: tt.current}
+		if got := threshold.Exceeded(diff); got != tt.want {
+			t.Errorf("$5 threshold, $%v -> $%v: Exceeded = %v, want %v", tt.previous, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestDeltaThresholdString(t *testing.T) {
+	if got := (DeltaThreshold{Value: 10, Percent: true}).String(); got != "10%" {
+		t.Errorf("String() = %q, want 10%%", got)
+	}
+	if got := (DeltaThreshold{Value: 5}).String(); got != "$5.00" {
+		t.Errorf("String() = %q, want $5.00", got)
+	}
+}
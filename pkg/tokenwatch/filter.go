@@ -0,0 +1,67 @@
+package tokenwatch
+
+import (
+	"strings"
+
+	"tokenwatch/pkg/models"
+)
+
+// ModelFilter hides models from a report: the models named in Exclude and
+// those below MinTokens or MinCost. Hidden models still count towards the
+// totals unless ExcludeFromTotal is set. The zero value hides nothing.
+type ModelFilter struct {
+	// Exclude lists model names to hide, matched case-insensitively with or
+	// without a snapshot date
+	Exclude []string
+
+	// MinTokens and MinCost hide models with fewer input + output tokens or a
+	// lower cost; zero disables them
+	MinTokens int64
+	MinCost   float64
+
+	// ExcludeFromTotal leaves hidden models out of the totals as well
+	ExcludeFromTotal bool
+}
+
+// Active reports whether the filter hides anything
+func (f ModelFilter) Active() bool {
+	return len(f.Exclude) > 0 || f.MinTokens > 0 || f.MinCost > 0
+}
+
+// Keep reports whether m is shown
+func (f ModelFilter) Keep(m models.ModelStats) bool {
+	if m.InputTokens+m.OutputTokens < f.MinTokens || m.Cost < f.MinCost {
+		return false
+	}
+	model := strings.ToLower(m.Model)
+	for _, name := range f.Exclude {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" && (model == name || model == NormalizeModelName(name)) {
+			return false
+		}
+	}
+	return true
+}
+
+// Apply returns the stats the filter keeps and the number it hid
+func (f ModelFilter) Apply(stats []models.ModelStats) ([]models.ModelStats, int) {
+	if !f.Active() {
+		return stats, 0
+	}
+	var kept []models.ModelStats
+	for _, m := range stats {
+		if f.Keep(m) {
+			kept = append(kept, m)
+		}
+	}
+	return kept, len(stats) - len(kept)
+}
+
+// Totals returns the totals of a report whose models were filtered from all
+// down to kept: those of all, or of kept with ExcludeFromTotal
+func (f ModelFilter) Totals(all, kept []models.ModelStats) models.TotalStats {
+	if f.ExcludeFromTotal {
+		return CalculateTotals(kept)
+	}
+	return CalculateTotals(all)
+}
@@ -0,0 +1,61 @@
+package tokenwatch
+
+import (
+	"testing"
+
+	"tokenwatch/pkg/models"
+)
+
+func filterStats() []models.ModelStats {
+	return []models.ModelStats{
+		{Model: "gpt-4o", InputTokens: 8000, OutputTokens: 2000, TotalTokens: 10000, Requests: 10, Cost: 5},
+		{Model: "gpt-3.5-turbo", InputTokens: 900, OutputTokens: 100, TotalTokens: 1000, Requests: 4, Cost: 0.01},
+		{Model: "text-embedding-3-small", InputTokens: 50, TotalTokens: 50, Requests: 1, Cost: 0.001},
+	}
+}
+
+func TestModelFilterExcludesNamedModels(t *testing.T) {
+	stats := filterStats()
+	tests := []struct {
+		name             string
+		excludeFromTotal bool
+		wantTokens       int64
+		wantCost         float64
+	}{
+		{name: "still in total", wantTokens: 11050, wantCost: 5.011},
+		{name: "excluded from total", excludeFromTotal: true, wantTokens: 10000, wantCost: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A snapshot name still matches its model
+			f := ModelFilter{Exclude: []string{"GPT-3.5-Turbo-0125", "text-embedding-3-small"}, ExcludeFromTotal: tt.excludeFromTotal}
+			kept, hidden := f.Apply(stats)
+			if hidden != 2 || len(kept) != 1 || kept[0].Model != "gpt-4o" {
+				t.Fatalf("kept %v (%d hidden), want only gpt-4o", kept, hidden)
+			}
+			totals := f.Totals(stats, kept)
+			if totals.TotalTokens != tt.wantTokens || totals.TotalCost != tt.wantCost {
+				t.Errorf("totals = %d tokens, $%v; want %d tokens, $%v", totals.TotalTokens, totals.TotalCost, tt.wantTokens, tt.wantCost)
+			}
+		})
+	}
+}
+
+func TestModelFilterThresholds(t *testing.T) {
+	stats := filterStats()
+	tests := []struct {
+		name   string
+		filter ModelFilter
+		want   int
+	}{
+		{name: "zero value", filter: ModelFilter{}, want: 3},
+		{name: "min tokens", filter: ModelFilter{MinTokens: 1000}, want: 2},
+		{name: "min cost", filter: ModelFilter{MinCost: 0.01}, want: 2},
+		{name: "both", filter: ModelFilter{MinTokens: 100, MinCost: 1}, want: 1},
+	}
+	for _, tt := range tests {
+		if kept, _ := tt.filter.Apply(stats); len(kept) != tt.want {
+			t.Errorf("%s: kept %d models, want %d", tt.name, len(kept), tt.want)
+		}
+	}
+}
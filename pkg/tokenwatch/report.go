@@ -0,0 +1,186 @@
+// Package tokenwatch fetches and aggregates token usage for use from other Go
+// programs without going through the CLI. It never prints: warnings are passed
+// to Options.OnWarning instead.
+package tokenwatch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"tokenwatch/pkg/export"
+	"tokenwatch/pkg/providers"
+)
+
+// Config says where a report's data comes from
+type Config struct {
+	// Provider fetches usage and costs. When nil, an OpenAI provider is
+	// created from APIKey, OrganizationID and ProjectID.
+	Provider providers.Provider
+
+	APIKey         string
+	OrganizationID string
+	ProjectID      string
+}
+
+// ErrNoProvider is returned by Report when Config has neither a Provider nor an APIKey
+var ErrNoProvider = errors.New("tokenwatch: config needs a Provider or an APIKey")
+
+// provider returns the configured provider, creating an OpenAI one if needed
+func (cfg Config) provider() (providers.Provider, error) {
+	if cfg.Provider != nil {
+		return cfg.Provider, nil
+	}
+	if cfg.APIKey == "" {
+		return nil, ErrNoProvider
+	}
+	provider := providers.NewOpenAIProvider(cfg.APIKey, cfg.OrganizationID)
+	provider.SetProject(cfg.ProjectID)
+	return provider, nil
+}
+
+// Options selects the data a report covers
+type Options struct {
+	// Period labels the report and, when StartTime is zero, selects the time
+	// range: 1d, 7d, 30d, 90d, 1y, or all. Defaults to 7d.
+	Period string
+
+	// StartTime and EndTime select an explicit range instead of Period
+	StartTime time.Time
+	EndTime   time.Time
+
+	// CompleteDays ends a Period at the previous UTC midnight, leaving out the
+	// partial current day. It doesn't apply to StartTime and EndTime.
+	CompleteDays bool
+
+	// Filter hides models from the report's model list, and from its totals
+	// with Filter.ExcludeFromTotal
+	Filter ModelFilter
+
+	// BypassCache fetches fresh data even if a cached response exists
+	BypassCache bool
+
+	// ModelBudgets maps model names to monthly budgets. Models whose cost is
+	// over their budget, scaled to the report's range, are listed in the
+	// report's BudgetBreaches and passed to OnWarning.
+	ModelBudgets map[string]float64
+
+	// Buckets adds each model's per-bucket usage to the report
+	Buckets bool
+
+	// Debug turns on the provider's request logging, which does write to stdout
+	Debug bool
+
+	// OnWarning, if set, receives problems that don't fail the report, such
+	// as cost data that couldn't be fetched
+	OnWarning func(msg string)
+}
+
+// Report fetches usage and costs from the configured provider and aggregates
+// them per model. Requests are made under ctx, so cancelling it stops fetches
+// in flight. If costs can't be fetched the report is still returned, with its
+// cost status set to unavailable and the error passed to OnWarning.
+func Report(ctx context.Context, cfg Config, opts Options) (*export.UsageReport, error) {
+	provider, err := cfg.provider()
+	if err != nil {
+		return nil, err
+	}
+
+	period := opts.Period
+	if period == "" {
+		period = providers.Period7Days
+	}
+	startTime, endTime := opts.StartTime, opts.EndTime
+	if startTime.IsZero() {
+		startTime, endTime = providers.GetPeriodTimeRange(period, opts.CompleteDays)
+	}
+
+	consumptions, err := provider.GetConsumptionContext(ctx, startTime, endTime, opts.BypassCache, opts.Debug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get consumption data: %w", err)
+	}
+
+	// A cancelled pricing fetch fails the report rather than leaving it without costs
+	pricings, err := provider.GetPricingContext(ctx, startTime, endTime, opts.BypassCache, opts.Debug)
+	if err != nil && ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	pricingAvailable := err == nil
+	if err != nil && opts.OnWarning != nil {
+		opts.OnWarning(fmt.Sprintf("Could not fetch pricing data: %v", err))
+	}
+
+	stats, err := AggregateModelStats(consumptions, pricings)
+	if err != nil {
+		return nil, err
+	}
+	kept, _ := opts.Filter.Apply(stats)
+	totals := opts.Filter.Totals(stats, kept)
+
+	report := &export.UsageReport{
+		Platform:  provider.GetPlatform(),
+		Period:    period,
+		StartTime: startTime,
+		EndTime:   endTime,
+		Models:    make([]export.ModelUsage, 0, len(kept)),
+		Totals: export.UsageTotals{
+			InputTokens:  totals.TotalInput,
+			CachedTokens: totals.TotalCached,
+			OutputTokens: totals.TotalOutput,
+			TotalTokens:  totals.TotalTokens,
+			Requests:     totals.TotalRequests,
+			Cost:         totals.TotalCost,
+			Currency:     totals.Currency,
+		},
+		CostStatus: DetermineCostStatus(period, totals.TotalCost, pricingAvailable),
+	}
+	for _, m := range kept {
+		report.Models = append(report.Models, export.ModelUsage{
+			Model:        m.Model,
+			InputTokens:  m.InputTokens,
+			CachedTokens: m.CachedTokens,
+			OutputTokens: m.OutputTokens,
+			TotalTokens:  m.TotalTokens,
+			Requests:     m.Requests,
+			Cost:         m.Cost,
+		})
+	}
+
+	report.BudgetBreaches = CheckModelBudgets(stats, opts.ModelBudgets, startTime, endTime)
+	if opts.OnWarning != nil {
+		for _, b := range report.BudgetBreaches {
+			opts.OnWarning(BudgetBreachMessage(b))
+		}
+	}
+
+	if opts.Buckets {
+		buckets, err := AggregateBuckets(consumptions, pricings)
+		if err != nil {
+			return nil, err
+		}
+		// Models are merged under the same names as stats, so only buckets of
+		// filtered out models have no model
+		index := make(map[string]int, len(report.Models))
+		for i, m := range report.Models {
+			index[m.Model] = i
+		}
+		for _, b := range buckets {
+			i, ok := index[b.Model]
+			if !ok {
+				continue
+			}
+			report.Models[i].Buckets = append(report.Models[i].Buckets, export.Bucket{
+				StartTime:    b.StartTime,
+				EndTime:      b.EndTime,
+				InputTokens:  b.InputTokens,
+				OutputTokens: b.OutputTokens,
+				TotalTokens:  b.TotalTokens,
+				Requests:     b.Requests,
+				Cost:         b.Cost,
+			})
+		}
+	}
+
+	return report, nil
+}
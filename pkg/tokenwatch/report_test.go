@@ -0,0 +1,219 @@
+package tokenwatch_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"tokenwatch/pkg/export"
+	"tokenwatch/pkg/models"
+	"tokenwatch/pkg/tokenwatch"
+)
+
+// fakeProvider serves fixed usage and costs, or a pricing error. Fetches fail
+// once their context is done, and with block set they wait for that.
+type fakeProvider struct {
+	consumptions []*models.Consumption
+	pricings     []*models.Pricing
+	pricingErr   error
+	block        bool
+}
+
+func (f *fakeProvider) GetPlatform() string { return "openai" }
+func (f *fakeProvider) IsAvailable() bool   { return true }
+func (f *fakeProvider) GetConsumption(startTime, endTime time.Time, bypassCache, debug bool) ([]*models.Consumption, error) {
+	return f.consumptions, nil
+}
+func (f *fakeProvider) GetPricing(startTime, endTime time.Time, bypassCache, debug bool) ([]*models.Pricing, error) {
+	return f.pricings, f.pricingErr
+}
+func (f *fakeProvider) GetConsumptionContext(ctx context.Context, startTime, endTime time.Time, bypassCache, debug bool) ([]*models.Consumption, error) {
+	if f.block {
+		<-ctx.Done()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return f.GetConsumption(startTime, endTime, bypassCache, debug)
+}
+func (f *fakeProvider) GetPricingContext(ctx context.Context, startTime, endTime time.Time, bypassCache, debug bool) ([]*models.Pricing, error) {
+	if f.block {
+		<-ctx.Done()
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return f.GetPricing(startTime, endTime, bypassCache, debug)
+}
+func (f *fakeProvider) GetConsumptionSummary(period string) (*models.ConsumptionSummary, error) {
+	return nil, nil
+}
+func (f *fakeProvider) GetPricingSummary(period string) (*models.PricingSummary, error) {
+	return nil, nil
+}
+
+func newFakeProvider() *fakeProvider {
+	end := time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, 0, -1)
+	return &fakeProvider{
+		consumptions: []*models.Consumption{
+			{Model: "gpt-4o-2024-08-06", InputTokens: 100, OutputTokens: 50, TotalTokens: 150, RequestCount: 2, StartTime: start, EndTime: end},
+			{Model: "gpt-4o-mini", InputTokens: 10, OutputTokens: 5, TotalTokens: 15, RequestCount: 1, StartTime: start, EndTime: end},
+		},
+		pricings: []*models.Pricing{
+			{Model: "gpt-4o", LineItem: "gpt-4o, input", Amount: 0.25, Currency: "usd", StartTime: start, EndTime: end},
+			{Model: "gpt-4o", LineItem: "gpt-4o, output", Amount: 0.5, Currency: "usd", StartTime: start, EndTime: end},
+		},
+	}
+}
+
+// captureStdout runs fn and returns anything it wrote to os.Stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	stdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	fn()
+	w.Close()
+	out, _ := io.ReadAll(r)
+	return string(out)
+}
+
+func TestReportWritesNothingToStdout(t *testing.T) {
+	var report *export.UsageReport
+	var err error
+	leaked := captureStdout(t, func() {
+		report, err = tokenwatch.Report(context.Background(), tokenwatch.Config{Provider: newFakeProvider()}, tokenwatch.Options{Period: "7d", Buckets: true})
+	})
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if leaked != "" {
+		t.Errorf("Report wrote to stdout:\n%s", leaked)
+	}
+
+	if report.Platform != "openai" || report.Period != "7d" {
+		t.Errorf("platform, period = %q, %q; want openai, 7d", report.Platform, report.Period)
+	}
+	if len(report.Models) != 2 {
+		t.Fatalf("got %d models, want 2: %+v", len(report.Models), report.Models)
+	}
+	if report.Totals.TotalTokens != 165 || report.Totals.Requests != 3 {
+		t.Errorf("totals = %+v, want 165 tokens over 3 requests", report.Totals)
+	}
+	if report.Totals.Cost != 0.75 {
+		t.Errorf("total cost = %v, want 0.75", report.Totals.Cost)
+	}
+	if report.CostStatus != models.CostStatusBilled {
+		t.Errorf("cost status = %q, want %q", report.CostStatus, models.CostStatusBilled)
+	}
+	for _, m := range report.Models {
+		if len(m.Buckets) != 1 {
+			t.Errorf("model %s has %d buckets, want 1", m.Model, len(m.Buckets))
+		}
+	}
+}
+
+func TestReportPassesPricingErrorsToOnWarning(t *testing.T) {
+	provider := newFakeProvider()
+	provider.pricings = nil
+	provider.pricingErr = errors.New("costs API down")
+
+	var warnings []string
+	report, err := tokenwatch.Report(context.Background(), tokenwatch.Config{Provider: provider}, tokenwatch.Options{
+		Period:    "7d",
+		OnWarning: func(msg string) { warnings = append(warnings, msg) },
+	})
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	if report.CostStatus != models.CostStatusUnavailable {
+		t.Errorf("cost status = %q, want %q", report.CostStatus, models.CostStatusUnavailable)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "costs API down") {
+		t.Errorf("warnings = %q, want one naming the pricing error", warnings)
+	}
+}
+
+func TestReportStopsOnCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := tokenwatch.Report(ctx, tokenwatch.Config{Provider: newFakeProvider()}, tokenwatch.Options{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+}
+
+func TestReportCancelsFetchesInFlight(t *testing.T) {
+	provider := newFakeProvider()
+	provider.block = true
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, err := tokenwatch.Report(ctx, tokenwatch.Config{Provider: provider}, tokenwatch.Options{})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("error = %v, want context.DeadlineExceeded", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Report did not return after its context ended")
+	}
+}
+
+func TestReportNeedsProviderOrAPIKey(t *testing.T) {
+	if _, err := tokenwatch.Report(context.Background(), tokenwatch.Config{}, tokenwatch.Options{}); !errors.Is(err, tokenwatch.ErrNoProvider) {
+		t.Errorf("error = %v, want ErrNoProvider", err)
+	}
+}
+
+func TestReportCompleteDaysEndsAtMidnight(t *testing.T) {
+	before := time.Now().UTC().Truncate(24 * time.Hour)
+	report, err := tokenwatch.Report(context.Background(), tokenwatch.Config{Provider: newFakeProvider()}, tokenwatch.Options{Period: "7d", CompleteDays: true})
+	if err != nil {
+		t.Fatalf("Report: %v", err)
+	}
+	after := time.Now().UTC().Truncate(24 * time.Hour)
+
+	if !report.EndTime.Equal(before) && !report.EndTime.Equal(after) {
+		t.Errorf("end = %v, want the previous UTC midnight", report.EndTime)
+	}
+	if got := report.EndTime.Sub(report.StartTime); got != 7*24*time.Hour {
+		t.Errorf("range = %v, want 7 days", got)
+	}
+}
+
+func TestReportFilterHidesModels(t *testing.T) {
+	for _, excludeFromTotal := range []bool{false, true} {
+		opts := tokenwatch.Options{Filter: tokenwatch.ModelFilter{Exclude: []string{"gpt-4o-mini"}, ExcludeFromTotal: excludeFromTotal}}
+		report, err := tokenwatch.Report(context.Background(), tokenwatch.Config{Provider: newFakeProvider()}, opts)
+		if err != nil {
+			t.Fatalf("Report: %v", err)
+		}
+		if len(report.Models) != 1 || report.Models[0].Model != "gpt-4o" {
+			t.Errorf("models = %+v, want only gpt-4o", report.Models)
+		}
+
+		// gpt-4o has 150 tokens and gpt-4o-mini 15
+		want := int64(165)
+		if excludeFromTotal {
+			want = 150
+		}
+		if report.Totals.TotalTokens != want {
+			t.Errorf("ExcludeFromTotal=%v: total tokens = %d, want %d", excludeFromTotal, report.Totals.TotalTokens, want)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+// Package update checks whether a newer TokenWatch release is available
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"tokenwatch/pkg/utils"
+)
+
+// DefaultReleaseURL is the GitHub API endpoint for the latest release
+const DefaultReleaseURL = "https://api.github.com/repos/mboss37/tokenwatch/releases/latest"
+
+// checkTimeout bounds a single release check, including retries
+const checkTimeout = 5 * time.Second
+
+// maxReleaseBytes caps the size of a release document
+const maxReleaseBytes = 1 << 20
+
+// Release is the part of a GitHub release document used by the check. Other
+// release URLs must serve the same fields.
+type Release struct {
+	TagName string `json:"tag_name"`
+	HTMLURL string `json:"html_url"`
+}
+
+// Result is the outcome of comparing the running version with the latest release
+type Result struct {
+	Current         string
+	Latest          string
+	URL             string
+	UpdateAvailable bool
+}
+
+// Check fetches the latest release from url and compares it with current
+func Check(current, url string) (*Result, error) {
+	release, err := LatestRelease(url)
+	if err != nil {
+		return nil, err
+	}
+
+	cmp, err := CompareVersions(release.TagName, current)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{
+		Current:         current,
+		Latest:          release.TagName,
+		URL:             release.HTMLURL,
+		UpdateAvailable: cmp > 0,
+	}, nil
+}
+
+// LatestRelease downloads the release document at url
+func LatestRelease(url string) (*Release, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, utils.NewValidationError("update.release_url", fmt.Sprintf("invalid URL: %v", err))
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	client := utils.NewRateLimitedClient(1.0, 1, checkTimeout)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, utils.NewNetworkError("failed to fetch the latest release", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("release source returned status %d", resp.StatusCode)
+	}
+
+	var release Release
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxReleaseBytes)).Decode(&release); err != nil {
+		return nil, fmt.Errorf("failed to decode release: %w", err)
+	}
+	if release.TagName == "" {
+		return nil, fmt.Errorf("release document has no tag_name")
+	}
+	return &release, nil
+}
+
+// CompareVersions compares two versions such as "v0.1.3" or "1.2", returning
+// -1, 0 or 1. Missing components count as zero and a pre-release or build
+// suffix ("-rc1", "+abc") is ignored.
+func CompareVersions(a, b string) (int, error) {
+	pa, err := parseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	pb, err := parseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		switch {
+		case x < y:
+			return -1, nil
+		case x > y:
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// parseVersion splits a version into its numeric components
+func parseVersion(v string) ([]int, error) {
+	s := strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+
+	var parts []int
+	for _, field := range strings.Split(s, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid version: %q", v)
+		}
+		parts = append(parts, n)
+	}
+	return parts, nil
+}
@@ -0,0 +1,69 @@
+package update
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{a: "v0.1.3", b: "v0.1.3", want: 0},
+		{a: "v0.2.0", b: "v0.1.9", want: 1},
+		{a: "v0.1.9", b: "v0.2.0", want: -1},
+		{a: "v0.10.0", b: "v0.9.0", want: 1}, // numeric, not lexical
+		{a: "1.2", b: "v1.2.0", want: 0},     // missing components are zero
+		{a: "v1.0.0-rc1", b: "v1.0.0", want: 0},
+		{a: "v1.0.1+abc", b: "v1.0.0", want: 1},
+	}
+	for _, tt := range tests {
+		got, err := CompareVersions(tt.a, tt.b)
+		if err != nil || got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, %v; want %d", tt.a, tt.b, got, err, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "latest", "v1.x", "v-1"} {
+		if _, err := CompareVersions(bad, "v1.0.0"); err == nil {
+			t.Errorf("CompareVersions(%q) accepted an invalid version", bad)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want bool
+	}{
+		{tag: "v0.2.0", want: true},
+		{tag: "v0.1.3", want: false},
+		{tag: "v0.1.0", want: false},
+	}
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"tag_name":"` + tt.tag + `","html_url":"https://example.com/r"}`))
+		}))
+		result, err := Check("v0.1.3", server.URL)
+		server.Close()
+		if err != nil {
+			t.Fatalf("%s: Check: %v", tt.tag, err)
+		}
+		if result.UpdateAvailable != tt.want || result.Latest != tt.tag || result.URL != "https://example.com/r" {
+			t.Errorf("%s: got %+v, want UpdateAvailable %v", tt.tag, result, tt.want)
+		}
+	}
+}
+
+func TestLatestReleaseWithoutTag(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"html_url":"https://example.com/r"}`))
+	}))
+	defer server.Close()
+
+	if _, err := LatestRelease(server.URL); err == nil {
+		t.Error("expected an error for a release without tag_name")
+	}
+}
@@ -1,11 +1,19 @@
 package utils
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sync"
 	"time"
 )
 
+// ErrCircuitOpen is returned by CircuitBreaker.Call while the circuit is open
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
 // CircuitState represents the state of a circuit breaker
 type CircuitState int
 
@@ -30,6 +38,17 @@ type CircuitBreaker struct {
 	maxFailures      int
 	resetTimeout     time.Duration
 	halfOpenRequests int
+	probes           int // half-open trial calls in flight
+
+	// statePath is where state is persisted between runs (empty disables persistence)
+	statePath string
+}
+
+// CircuitSnapshot is the persisted form of a circuit breaker's state
+type CircuitSnapshot struct {
+	State           CircuitState `json:"state"`
+	Failures        int          `json:"failures"`
+	LastFailureTime time.Time    `json:"last_failure_time"`
 }
 
 // NewCircuitBreaker creates a new circuit breaker
@@ -42,35 +61,133 @@ func NewCircuitBreaker(maxFailures int, resetTimeout time.Duration) *CircuitBrea
 	}
 }
 
-// Call executes the given function with circuit breaker protection
+// Call executes the given function with circuit breaker protection. The lock
+// is only held to check and record state, so calls through a shared breaker
+// run concurrently. While half-open, a single probe call is let through and
+// the others fail with ErrCircuitOpen until it finishes.
 func (cb *CircuitBreaker) Call(fn func() error) error {
+	probe, err := cb.beforeCall()
+	if err != nil {
+		return err
+	}
+
+	err = fn()
+
+	cb.afterCall(probe, err)
+	return err
+}
+
+// beforeCall checks whether a call may proceed, moving an open circuit to
+// half-open once the reset timeout has passed. probe reports whether the call
+// is the half-open trial.
+func (cb *CircuitBreaker) beforeCall() (probe bool, err error) {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
 
-	// Check current state
 	switch cb.state {
 	case StateOpen:
 		// Check if we should transition to half-open
-		if time.Since(cb.lastFailureTime) > cb.resetTimeout {
-			cb.state = StateHalfOpen
-			cb.successes = 0
-			cb.failures = 0
-		} else {
-			return fmt.Errorf("circuit breaker is open")
+		if time.Since(cb.lastFailureTime) <= cb.resetTimeout {
+			return false, ErrCircuitOpen
 		}
+		cb.state = StateHalfOpen
+		cb.successes = 0
+		cb.failures = 0
+		cb.probes = 0
+	case StateHalfOpen:
+		if cb.probes >= cb.halfOpenRequests {
+			return false, ErrCircuitOpen
+		}
+	default:
+		return false, nil
 	}
 
-	// Execute the function
-	err := fn()
+	cb.probes++
+	return true, nil
+}
 
-	// Update state based on result
+// afterCall records the result of a call let through by beforeCall
+func (cb *CircuitBreaker) afterCall(probe bool, err error) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if probe {
+		cb.probes--
+	}
+
+	// A cancelled call says nothing about the service, so it counts as neither
+	// a failure nor a success. A cancelled probe frees the slot for the next call.
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	// While half-open only the probe decides the state; calls that started
+	// before the circuit opened don't
+	if cb.state == StateHalfOpen && !probe {
+		return
+	}
 	if err != nil {
 		cb.recordFailure()
 	} else {
 		cb.recordSuccess()
 	}
+	cb.persist()
+}
 
-	return err
+// EnablePersistence loads any previously saved state from path and saves
+// state back to it after every call, so consecutive CLI runs respect an open circuit
+func (cb *CircuitBreaker) EnablePersistence(path string) error {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	cb.statePath = path
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read circuit state: %w", err)
+	}
+
+	var snapshot CircuitSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("failed to parse circuit state: %w", err)
+	}
+
+	cb.state = snapshot.State
+	cb.failures = snapshot.Failures
+	cb.lastFailureTime = snapshot.LastFailureTime
+	return nil
+}
+
+// persist writes the current state to the state file, if persistence is enabled.
+// Must be called with cb.mu held.
+func (cb *CircuitBreaker) persist() {
+	if cb.statePath == "" {
+		return
+	}
+
+	data, err := json.Marshal(CircuitSnapshot{
+		State:           cb.state,
+		Failures:        cb.failures,
+		LastFailureTime: cb.lastFailureTime,
+	})
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cb.statePath), 0700); err != nil {
+		Debug("Failed to create circuit state directory", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+	if err := os.WriteFile(cb.statePath, data, 0600); err != nil {
+		Debug("Failed to persist circuit state", map[string]interface{}{
+			"path":  cb.statePath,
+			"error": err.Error(),
+		})
+	}
 }
 
 // recordFailure records a failure and potentially opens the circuit
@@ -118,6 +235,7 @@ func (cb *CircuitBreaker) Reset() {
 	cb.state = StateClosed
 	cb.failures = 0
 	cb.successes = 0
+	cb.persist()
 }
 
 // String returns a string representation of the circuit state
@@ -0,0 +1,189 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPersistedOpenCircuitShortCircuits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "circuit.json")
+	const resetTimeout = 100 * time.Millisecond
+
+	// A previous run opened the circuit and saved it
+	first := NewCircuitBreaker(2, resetTimeout)
+	if err := first.EnablePersistence(path); err != nil {
+		t.Fatalf("EnablePersistence: %v", err)
+	}
+	failing := errors.New("server error")
+	for i := 0; i < 2; i++ {
+		first.Call(func() error { return failing })
+	}
+	if first.GetState() != StateOpen {
+		t.Fatalf("state after 2 failures = %s, want open", first.GetState())
+	}
+
+	// The next run loads the open circuit and refuses calls without making them
+	second := NewCircuitBreaker(2, resetTimeout)
+	if err := second.EnablePersistence(path); err != nil {
+		t.Fatalf("EnablePersistence: %v", err)
+	}
+	calls := 0
+	if err := second.Call(func() error { calls++; return nil }); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Call before reset timeout = %v, want ErrCircuitOpen", err)
+	}
+	if calls != 0 {
+		t.Fatalf("fn ran %d times while the circuit was open", calls)
+	}
+
+	// Once the reset timeout has passed a trial call goes through and closes it
+	time.Sleep(resetTimeout + 20*time.Millisecond)
+	if err := second.Call(func() error { calls++; return nil }); err != nil {
+		t.Fatalf("Call after reset timeout = %v, want nil", err)
+	}
+	if calls != 1 || second.GetState() != StateClosed {
+		t.Errorf("after reset timeout: %d calls, state %s; want 1 call, closed", calls, second.GetState())
+	}
+
+	// The closed state is saved for the run after that
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snapshot CircuitSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.State != StateClosed || snapshot.Failures != 0 {
+		t.Errorf("persisted %+v, want closed with no failures", snapshot)
+	}
+}
+
+func TestPersistedStaleOpenCircuitAllowsTrial(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "circuit.json")
+	data, _ := json.Marshal(CircuitSnapshot{
+		State:           StateOpen,
+		Failures:        5,
+		LastFailureTime: time.Now().Add(-2 * time.Minute),
+	})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cb := NewCircuitBreaker(5, time.Minute)
+	if err := cb.EnablePersistence(path); err != nil {
+		t.Fatalf("EnablePersistence: %v", err)
+	}
+	if err := cb.Call(func() error { return nil }); err != nil {
+		t.Errorf("Call after the saved reset timeout elapsed = %v, want nil", err)
+	}
+}
+
+func TestEnablePersistenceMissingFile(t *testing.T) {
+	cb := NewCircuitBreaker(5, time.Minute)
+	if err := cb.EnablePersistence(filepath.Join(t.TempDir(), "none", "circuit.json")); err != nil {
+		t.Fatalf("EnablePersistence with no saved state: %v", err)
+	}
+	if cb.GetState() != StateClosed {
+		t.Errorf("state = %s, want closed", cb.GetState())
+	}
+}
+
+func TestEnablePersistenceCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "circuit.json")
+	if err := os.WriteFile(path, []byte("{"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewCircuitBreaker(5, time.Minute).EnablePersistence(path); err == nil {
+		t.Error("expected an error for corrupt circuit state")
+	}
+}
+
+func TestCancelledCallsDontOpenTheCircuit(t *testing.T) {
+	cb := NewCircuitBreaker(2, time.Minute)
+	for i := 0; i < 3; i++ {
+		err := cb.Call(func() error { return fmt.Errorf("request aborted: %w", context.Canceled) })
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Call = %v, want the cancellation passed through", err)
+		}
+	}
+	if cb.GetState() != StateClosed {
+		t.Errorf("state after cancelled calls = %s, want closed", cb.GetState())
+	}
+}
+
+func TestCallsRunConcurrently(t *testing.T) {
+	cb := NewCircuitBreaker(5, time.Minute)
+	arrived := make(chan struct{}, 2)
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cb.Call(func() error {
+				arrived <- struct{}{}
+				<-release
+				return nil
+			})
+		}()
+	}
+
+	// Both calls must be inside fn at once, which a lock held across fn prevents
+	for i := 0; i < 2; i++ {
+		select {
+		case <-arrived:
+		case <-time.After(2 * time.Second):
+			close(release)
+			t.Fatalf("only %d of 2 calls started; Call serializes fn", i)
+		}
+	}
+	close(release)
+	wg.Wait()
+}
+
+func TestHalfOpenAllowsSingleProbe(t *testing.T) {
+	const resetTimeout = 20 * time.Millisecond
+	cb := NewCircuitBreaker(1, resetTimeout)
+	cb.Call(func() error { return errors.New("server error") })
+	if cb.GetState() != StateOpen {
+		t.Fatalf("state after a failure = %s, want open", cb.GetState())
+	}
+	time.Sleep(resetTimeout + 10*time.Millisecond)
+
+	inProbe := make(chan struct{})
+	release := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- cb.Call(func() error {
+			close(inProbe)
+			<-release
+			return nil
+		})
+	}()
+	<-inProbe
+
+	// A second call while the probe is in flight is refused without running
+	calls := 0
+	if err := cb.Call(func() error { calls++; return nil }); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Call during the probe = %v, want ErrCircuitOpen", err)
+	}
+	if calls != 0 {
+		t.Errorf("fn ran %d times during the probe, want 0", calls)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("probe Call = %v, want nil", err)
+	}
+	if cb.GetState() != StateClosed {
+		t.Errorf("state after a successful probe = %s, want closed", cb.GetState())
+	}
+}
@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"context"
+	"sync"
+)
+
+// RunBoundedContext calls fn for every index in [0, n) with at most limit calls
+// running at once, and returns the first error encountered once the calls it
+// started have finished. A limit below 1 is treated as 1.
+//
+// By default every call runs even after one fails. With failFast the first
+// error cancels the context passed to every call and no further calls are
+// started. Cancelling ctx stops the run either way.
+func RunBoundedContext(ctx context.Context, n, limit int, failFast bool, fn func(ctx context.Context, i int) error) error {
+	if limit < 1 {
+		limit = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			if failFast {
+				cancel()
+			}
+		})
+	}
+	semaphore := make(chan struct{}, limit)
+
+	for i := 0; i < n; i++ {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			if err := fn(ctx, i); err != nil {
+				fail(err)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	if firstErr == nil {
+		// Nothing failed, so any cancellation came from the caller's context
+		return ctx.Err()
+	}
+	return firstErr
+}
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRunBoundedContextNeverExceedsLimit(t *testing.T) {
+	var running, peak, calls atomic.Int64
+	err := RunBoundedContext(context.Background(), 20, 2, false, func(ctx context.Context, i int) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		calls.Add(1)
+		time.Sleep(5 * time.Millisecond)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunBoundedContext: %v", err)
+	}
+	if calls.Load() != 20 {
+		t.Errorf("fn called %d times, want 20", calls.Load())
+	}
+	if p := peak.Load(); p > 2 {
+		t.Errorf("%d calls ran at once, want at most 2", p)
+	} else if p < 2 {
+		t.Errorf("calls never overlapped (peak %d), want 2 at once", p)
+	}
+}
+
+func TestRunBoundedContextCollectsAllByDefault(t *testing.T) {
+	failure := errors.New("chunk failed")
+	var calls atomic.Int64
+	err := RunBoundedContext(context.Background(), 5, 0, false, func(ctx context.Context, i int) error {
+		calls.Add(1)
+		if i == 2 {
+			return failure
+		}
+		return nil
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("RunBoundedContext = %v, want %v", err, failure)
+	}
+	// Every call still runs, and a limit below 1 runs them one at a time
+	if calls.Load() != 5 {
+		t.Errorf("fn called %d times, want 5", calls.Load())
+	}
+}
+
+func TestRunBoundedContextFailsFast(t *testing.T) {
+	failure := errors.New("chunk failed")
+	var started, cancelled atomic.Int64
+	err := RunBoundedContext(context.Background(), 10, 2, true, func(ctx context.Context, i int) error {
+		started.Add(1)
+		if i == 0 {
+			return failure
+		}
+		// Other calls run until the failure cancels them
+		select {
+		case <-ctx.Done():
+			cancelled.Add(1)
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+			return nil
+		}
+	})
+	if !errors.Is(err, failure) {
+		t.Errorf("RunBoundedContext = %v, want %v", err, failure)
+	}
+	if n := started.Load(); n >= 10 {
+		t.Errorf("all %d calls started, want the failure to stop the rest", n)
+	}
+	if started.Load() > 1 && cancelled.Load() != started.Load()-1 {
+		t.Errorf("%d of %d running calls saw the cancellation", cancelled.Load(), started.Load()-1)
+	}
+}
+
+func TestRunBoundedContextRunsEverythingWithoutErrors(t *testing.T) {
+	var calls atomic.Int64
+	err := RunBoundedContext(context.Background(), 5, 2, false, func(ctx context.Context, i int) error {
+		calls.Add(1)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunBoundedContext: %v", err)
+	}
+	if calls.Load() != 5 {
+		t.Errorf("fn called %d times, want 5", calls.Load())
+	}
+}
+
+func TestRunBoundedContextStopsWhenCallerCancels(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var calls atomic.Int64
+	err := RunBoundedContext(ctx, 5, 1, false, func(ctx context.Context, i int) error {
+		calls.Add(1)
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("RunBoundedContext = %v, want context.Canceled", err)
+	}
+	if calls.Load() != 0 {
+		t.Errorf("fn called %d times after the context was cancelled, want 0", calls.Load())
+	}
+}
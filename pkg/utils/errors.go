@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 )
@@ -23,6 +24,8 @@ const (
 	ErrorTypeValidation ErrorType = "VALIDATION"
 	// ErrorTypeInternal indicates an internal error
 	ErrorTypeInternal ErrorType = "INTERNAL"
+	// ErrorTypeRetriesExhausted indicates a request that failed on every retry
+	ErrorTypeRetriesExhausted ErrorType = "RETRIES_EXHAUSTED"
 )
 
 // StructuredError provides detailed error information with actionable suggestions
@@ -59,6 +62,17 @@ func (e *StructuredError) Unwrap() error {
 	return e.Cause
 }
 
+// StatusCode returns the HTTP status the error was built from, or 0 if it
+// has none. Use errors.As to reach a StructuredError wrapped by other errors.
+func (e *StructuredError) StatusCode() int {
+	for _, key := range []string{"status_code", "last_status"} {
+		if code, ok := e.Context[key].(int); ok && code != 0 {
+			return code
+		}
+	}
+	return 0
+}
+
 // NewConfigError creates a configuration error
 func NewConfigError(message string, cause error) *StructuredError {
 	return &StructuredError{
@@ -169,6 +183,47 @@ func NewRateLimitError(platform string, retryAfter string) *StructuredError {
 	}
 }
 
+// NewRetriesExhaustedError creates an error for a request that still failed after
+// all retries. lastStatus is the final HTTP status, or 0 if no response was received.
+func NewRetriesExhaustedError(attempts, lastStatus int, cause error) *StructuredError {
+	message := fmt.Sprintf("Request failed after %d attempts", attempts)
+	if lastStatus != 0 {
+		message = fmt.Sprintf("Request failed with status %d after %d attempts", lastStatus, attempts)
+	}
+
+	var suggestions []string
+	switch {
+	case lastStatus == 429:
+		suggestions = []string{
+			"The rate limit did not reset while retrying - wait a minute and try again",
+			"Lower settings.max_concurrency to send fewer requests at once",
+		}
+	case lastStatus >= 500:
+		suggestions = []string{
+			"The API service is experiencing issues",
+			"Check the platform's status page for outages",
+			"Try again in a few minutes",
+		}
+	default:
+		suggestions = []string{
+			"Check your internet connection",
+			"Verify you can reach the API endpoint",
+			"Try again in a few moments",
+		}
+	}
+
+	return &StructuredError{
+		Type:        ErrorTypeRetriesExhausted,
+		Message:     message,
+		Cause:       cause,
+		Suggestions: suggestions,
+		Context: map[string]interface{}{
+			"attempts":    attempts,
+			"last_status": lastStatus,
+		},
+	}
+}
+
 // NewValidationError creates a validation error
 func NewValidationError(field, message string) *StructuredError {
 	return &StructuredError{
@@ -186,8 +241,9 @@ func FormatError(err error) string {
 		return ""
 	}
 
-	// Check if it's already a structured error
-	if se, ok := err.(*StructuredError); ok {
+	// Check if it's already a structured error, possibly wrapped
+	var se *StructuredError
+	if errors.As(err, &se) {
 		return se.Error()
 	}
 
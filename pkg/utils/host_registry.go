@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Limiters and circuit breakers are shared by every client talking to the same
+// host, so several providers in one process can't exceed the host's rate
+// limit together and all of them see when its circuit opens.
+var (
+	hostRegistryMu sync.Mutex
+	hostLimiters   = make(map[string]*rate.Limiter)
+	hostBreakers   = make(map[string]*CircuitBreaker)
+)
+
+// HostLimiter returns the rate limiter shared by all clients of host. It is
+// created with requestsPerSecond and burst on first use; later calls return
+// the existing limiter unchanged.
+func HostLimiter(host string, requestsPerSecond float64, burst int) *rate.Limiter {
+	hostRegistryMu.Lock()
+	defer hostRegistryMu.Unlock()
+
+	limiter, ok := hostLimiters[host]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Limit(requestsPerSecond), burst)
+		hostLimiters[host] = limiter
+	}
+	return limiter
+}
+
+// HostCircuitBreaker returns the circuit breaker shared by all clients of
+// host, creating it with maxFailures and resetTimeout on first use
+func HostCircuitBreaker(host string, maxFailures int, resetTimeout time.Duration) *CircuitBreaker {
+	hostRegistryMu.Lock()
+	defer hostRegistryMu.Unlock()
+
+	breaker, ok := hostBreakers[host]
+	if !ok {
+		breaker = NewCircuitBreaker(maxFailures, resetTimeout)
+		hostBreakers[host] = breaker
+	}
+	return breaker
+}
+
+// NewHostRateLimitedClient creates a rate-limited HTTP client whose limiter is
+// shared with every other client of host (see HostLimiter)
+func NewHostRateLimitedClient(host string, requestsPerSecond float64, burst int, readTimeout time.Duration) *RateLimitedClient {
+	return &RateLimitedClient{
+		client: &http.Client{
+			Transport: NewTransport(DefaultTimeoutConfig()),
+		},
+		rateLimiter: HostLimiter(host, requestsPerSecond, burst),
+		retryConfig: DefaultRetryConfig(),
+		readTimeout: readTimeout,
+	}
+}
@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHostRegistrySharesPerHost(t *testing.T) {
+	const n = 20
+	limiters := make(chan interface{}, n)
+	breakers := make(chan interface{}, n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limiters <- HostLimiter("registry.test", 10, 1)
+			breakers <- HostCircuitBreaker("registry.test", 5, time.Minute)
+		}()
+	}
+	wg.Wait()
+	close(limiters)
+	close(breakers)
+
+	first := HostLimiter("registry.test", 99, 99)
+	for l := range limiters {
+		if l != first {
+			t.Fatal("clients of one host got different limiters")
+		}
+	}
+	firstBreaker := HostCircuitBreaker("registry.test", 1, time.Second)
+	for b := range breakers {
+		if b != firstBreaker {
+			t.Fatal("clients of one host got different circuit breakers")
+		}
+	}
+
+	if HostLimiter("other.test", 10, 1) == first {
+		t.Error("different hosts share a limiter")
+	}
+	if HostCircuitBreaker("other.test", 5, time.Minute) == firstBreaker {
+		t.Error("different hosts share a circuit breaker")
+	}
+}
+
+func TestHostClientsShareRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// 20 requests per second with no burst: each request after the first waits 50ms
+	const perClient = 3
+	clients := []*RateLimitedClient{
+		NewHostRateLimitedClient("shared-limit.test", 20, 1, 5*time.Second),
+		NewHostRateLimitedClient("shared-limit.test", 20, 1, 5*time.Second),
+	}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for _, c := range clients {
+		for i := 0; i < perClient; i++ {
+			wg.Add(1)
+			go func(c *RateLimitedClient) {
+				defer wg.Done()
+				req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL, nil)
+				resp, err := c.Do(req)
+				if err != nil {
+					t.Errorf("Do: %v", err)
+					return
+				}
+				resp.Body.Close()
+			}(c)
+		}
+	}
+	wg.Wait()
+
+	// Separate limiters would finish in about 100ms; a shared one needs 250ms
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("%d requests across two clients took %v, want the shared limit to space them to at least 200ms", 2*perClient, elapsed)
+	}
+}
@@ -2,8 +2,14 @@ package utils
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/time/rate"
@@ -27,35 +33,204 @@ func DefaultRetryConfig() RetryConfig {
 	}
 }
 
+// TimeoutConfig holds the timeouts for each stage of a request. There is no
+// total timeout: a slow but progressing response body is read to the end,
+// while a connection that stalls at any stage fails fast.
+type TimeoutConfig struct {
+	DialTimeout           time.Duration
+	ResponseHeaderTimeout time.Duration
+	// ReadTimeout is how long the response body may go without delivering
+	// any data. Zero keeps the client's current read timeout.
+	ReadTimeout time.Duration
+}
+
+// DefaultTimeoutConfig returns sensible defaults for connection timeouts
+func DefaultTimeoutConfig() TimeoutConfig {
+	return TimeoutConfig{
+		DialTimeout:           10 * time.Second,
+		ResponseHeaderTimeout: 20 * time.Second,
+	}
+}
+
+// ErrReadTimeout is returned when a response body delivers no data for
+// longer than the client's read timeout
+var ErrReadTimeout = errors.New("response body read timed out")
+
+// idleTimeoutBody cancels its request when no data arrives for timeout.
+// Every read that returns data pushes the deadline back.
+type idleTimeoutBody struct {
+	body    io.ReadCloser
+	timeout time.Duration
+	timer   *time.Timer
+	cancel  context.CancelFunc
+	expired atomic.Bool
+}
+
+func newIdleTimeoutBody(body io.ReadCloser, timeout time.Duration, cancel context.CancelFunc) *idleTimeoutBody {
+	b := &idleTimeoutBody{body: body, timeout: timeout, cancel: cancel}
+	b.timer = time.AfterFunc(timeout, func() {
+		b.expired.Store(true)
+		cancel()
+	})
+	return b
+}
+
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	if b.expired.Load() {
+		return n, fmt.Errorf("%w: no data for %s", ErrReadTimeout, b.timeout)
+	}
+	if n > 0 {
+		b.timer.Reset(b.timeout)
+	}
+	return n, err
+}
+
+func (b *idleTimeoutBody) Close() error {
+	b.timer.Stop()
+	err := b.body.Close()
+	b.cancel()
+	return err
+}
+
+// NewTransport creates an HTTP transport with the given connection timeouts
+func NewTransport(timeouts TimeoutConfig) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   timeouts.DialTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	transport.ResponseHeaderTimeout = timeouts.ResponseHeaderTimeout
+	return transport
+}
+
 // RateLimitedClient wraps an HTTP client with rate limiting and retry logic
 type RateLimitedClient struct {
 	client      *http.Client
 	rateLimiter *rate.Limiter
 	retryConfig RetryConfig
+	readTimeout time.Duration // see TimeoutConfig.ReadTimeout; zero disables it
+
+	// Accumulated counters, read via Stats
+	requests        atomic.Int64
+	retries         atomic.Int64
+	retriedRequests atomic.Int64
+	failedRequests  atomic.Int64
+	maxBackoffHits  atomic.Int64
+	backoffTime     atomic.Int64 // nanoseconds
+	rateLimitWait   atomic.Int64 // nanoseconds
+	attempts        [MaxTrackedAttempts]atomic.Int64
+}
+
+// MaxTrackedAttempts is the number of attempt counts tracked separately in
+// ClientStats.AttemptsToSuccess; the last entry also counts any higher attempt
+const MaxTrackedAttempts = 8
+
+// ClientStats summarizes the time a client has spent retrying and throttling
+type ClientStats struct {
+	Requests        int64 // HTTP requests sent, counting each retry
+	Retries         int64 // retry attempts made, across all requests
+	RetriedRequests int64 // requests that needed at least one retry
+	FailedRequests  int64 // requests that ran out of retries
+	MaxBackoffHits  int64 // backoff waits capped at RetryConfig.MaxBackoff
+	BackoffTime     time.Duration
+	RateLimitWait   time.Duration
+
+	// AttemptsToSuccess[i] counts requests that got a response on attempt
+	// i+1. The last entry also counts later attempts; trailing zeros are omitted.
+	AttemptsToSuccess []int64
 }
 
-// NewRateLimitedClient creates a new rate-limited HTTP client
-func NewRateLimitedClient(requestsPerSecond float64, burst int, timeout time.Duration) *RateLimitedClient {
+// Stats returns the retries and wait times accumulated by the client
+func (c *RateLimitedClient) Stats() ClientStats {
+	stats := ClientStats{
+		Requests:        c.requests.Load(),
+		Retries:         c.retries.Load(),
+		RetriedRequests: c.retriedRequests.Load(),
+		FailedRequests:  c.failedRequests.Load(),
+		MaxBackoffHits:  c.maxBackoffHits.Load(),
+		BackoffTime:     time.Duration(c.backoffTime.Load()),
+		RateLimitWait:   time.Duration(c.rateLimitWait.Load()),
+	}
+	last := -1
+	counts := make([]int64, MaxTrackedAttempts)
+	for i := range c.attempts {
+		counts[i] = c.attempts[i].Load()
+		if counts[i] > 0 {
+			last = i
+		}
+	}
+	stats.AttemptsToSuccess = counts[:last+1]
+	return stats
+}
+
+// recordOutcome updates the per-request counters once a request is finished.
+// attempt is zero-based; succeeded is false when retries ran out.
+func (c *RateLimitedClient) recordOutcome(attempt int, succeeded bool) {
+	if attempt > 0 {
+		c.retriedRequests.Add(1)
+	}
+	if !succeeded {
+		c.failedRequests.Add(1)
+		return
+	}
+	if attempt >= MaxTrackedAttempts {
+		attempt = MaxTrackedAttempts - 1
+	}
+	c.attempts[attempt].Add(1)
+}
+
+// NewRateLimitedClient creates a new rate-limited HTTP client. readTimeout
+// bounds how long a response body may stall (see TimeoutConfig.ReadTimeout).
+func NewRateLimitedClient(requestsPerSecond float64, burst int, readTimeout time.Duration) *RateLimitedClient {
 	return &RateLimitedClient{
 		client: &http.Client{
-			Timeout: timeout,
+			Transport: NewTransport(DefaultTimeoutConfig()),
 		},
 		rateLimiter: rate.NewLimiter(rate.Limit(requestsPerSecond), burst),
 		retryConfig: DefaultRetryConfig(),
+		readTimeout: readTimeout,
 	}
 }
 
 // NewRateLimitedClientWithConfig creates a new rate-limited HTTP client with custom retry config
-func NewRateLimitedClientWithConfig(requestsPerSecond float64, burst int, timeout time.Duration, retryConfig RetryConfig) *RateLimitedClient {
+func NewRateLimitedClientWithConfig(requestsPerSecond float64, burst int, readTimeout time.Duration, retryConfig RetryConfig) *RateLimitedClient {
 	return &RateLimitedClient{
 		client: &http.Client{
-			Timeout: timeout,
+			Transport: NewTransport(DefaultTimeoutConfig()),
 		},
 		rateLimiter: rate.NewLimiter(rate.Limit(requestsPerSecond), burst),
 		retryConfig: retryConfig,
+		readTimeout: readTimeout,
+	}
+}
+
+// SetTimeouts replaces the client's transport with one using the given
+// connection timeouts, and its read timeout when one is given
+func (c *RateLimitedClient) SetTimeouts(timeouts TimeoutConfig) {
+	c.client.Transport = NewTransport(timeouts)
+	if timeouts.ReadTimeout > 0 {
+		c.readTimeout = timeouts.ReadTimeout
 	}
 }
 
+// SetRateLimit replaces the client's request rate and burst. For a client
+// from NewHostRateLimitedClient this changes the limiter shared by the host.
+func (c *RateLimitedClient) SetRateLimit(requestsPerSecond float64, burst int) {
+	c.rateLimiter.SetLimit(rate.Limit(requestsPerSecond))
+	c.rateLimiter.SetBurst(burst)
+}
+
+// RateLimit returns the client's request rate and burst
+func (c *RateLimitedClient) RateLimit() (float64, int) {
+	return float64(c.rateLimiter.Limit()), c.rateLimiter.Burst()
+}
+
+// SetTransport replaces the client's transport, e.g. to serve recorded responses
+func (c *RateLimitedClient) SetTransport(transport http.RoundTripper) {
+	c.client.Transport = transport
+}
+
 // Do executes an HTTP request with rate limiting and retry logic
 func (c *RateLimitedClient) Do(req *http.Request) (*http.Response, error) {
 	return c.DoWithContext(req.Context(), req)
@@ -69,52 +244,92 @@ func (c *RateLimitedClient) DoWithContext(ctx context.Context, req *http.Request
 
 	for attempt := 0; attempt <= c.retryConfig.MaxRetries; attempt++ {
 		// Wait for rate limiter
+		waitStart := time.Now()
 		if err := c.rateLimiter.Wait(ctx); err != nil {
 			return nil, fmt.Errorf("rate limiter error: %w", err)
 		}
+		c.rateLimitWait.Add(int64(time.Since(waitStart)))
 
-		// Clone the request for each attempt
-		reqClone := req.Clone(ctx)
+		// Clone the request for each attempt, rewinding any body. Each attempt
+		// gets its own context so a stalled body can be cancelled on its own.
+		attemptCtx, cancel := context.WithCancel(ctx)
+		reqClone := req.Clone(attemptCtx)
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				cancel()
+				return nil, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+			reqClone.Body = body
+		}
 
 		// Execute request
+		c.requests.Add(1)
 		resp, err = c.client.Do(reqClone)
+		if err != nil {
+			cancel()
+		} else if c.readTimeout > 0 {
+			resp.Body = newIdleTimeoutBody(resp.Body, c.readTimeout, cancel)
+		} else {
+			resp.Body = cancelOnClose{resp.Body, cancel}
+		}
 
 		// Check if we should retry
-		if err == nil && resp.StatusCode < 500 {
+		if err == nil && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
 			// Success or client error - don't retry
+			c.recordOutcome(attempt, true)
 			return resp, nil
 		}
 
 		// Log retry attempt
 		if attempt < c.retryConfig.MaxRetries {
+			// A Retry-After from the server replaces the computed backoff
+			wait := backoff
+			if err == nil {
+				if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+					wait = retryAfter
+				}
+			}
+			capped := wait >= c.retryConfig.MaxBackoff
+			if wait > c.retryConfig.MaxBackoff {
+				wait = c.retryConfig.MaxBackoff
+			}
+
 			if err != nil {
 				// Network error
 				Debug("Request failed, retrying", map[string]interface{}{
 					"attempt":     attempt + 1,
 					"maxAttempts": c.retryConfig.MaxRetries + 1,
 					"error":       err.Error(),
-					"backoff":     backoff.String(),
+					"backoff":     wait.String(),
 					"url":         req.URL.String(),
 				})
 			} else {
-				// Server error
+				// Server error or rate limit
 				Debug("Request failed with server error, retrying", map[string]interface{}{
 					"attempt":     attempt + 1,
 					"maxAttempts": c.retryConfig.MaxRetries + 1,
 					"status":      resp.StatusCode,
-					"backoff":     backoff.String(),
+					"backoff":     wait.String(),
 					"url":         req.URL.String(),
 				})
 				resp.Body.Close()
 			}
 
 			// Wait before retry with exponential backoff
+			c.retries.Add(1)
+			if capped {
+				c.maxBackoffHits.Add(1)
+			}
+			backoffStart := time.Now()
 			select {
-			case <-time.After(backoff):
+			case <-time.After(wait):
 				// Continue to next attempt
 			case <-ctx.Done():
+				c.backoffTime.Add(int64(time.Since(backoffStart)))
 				return nil, ctx.Err()
 			}
+			c.backoffTime.Add(int64(time.Since(backoffStart)))
 
 			// Increase backoff for next attempt
 			backoff = time.Duration(float64(backoff) * c.retryConfig.BackoffFactor)
@@ -124,11 +339,49 @@ func (c *RateLimitedClient) DoWithContext(ctx context.Context, req *http.Request
 		}
 	}
 
-	// All retries exhausted
+	// All retries exhausted. The last status travels in the error, so the
+	// response is closed here rather than handed back alongside it.
+	c.recordOutcome(c.retryConfig.MaxRetries, false)
 	if err != nil {
-		return nil, fmt.Errorf("request failed after %d attempts: %w", c.retryConfig.MaxRetries+1, err)
+		return nil, NewRetriesExhaustedError(c.retryConfig.MaxRetries+1, 0, err)
+	}
+	resp.Body.Close()
+	return nil, NewRetriesExhaustedError(c.retryConfig.MaxRetries+1, resp.StatusCode, nil)
+}
+
+// cancelOnClose releases a request's context once its body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// parseRetryAfter parses a Retry-After header value, either delay-seconds
+// ("2") or an HTTP date, into how long to wait from now. Dates in the past
+// mean no wait.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := at.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
 	}
-	return resp, fmt.Errorf("request failed with status %d after %d attempts", resp.StatusCode, c.retryConfig.MaxRetries+1)
+	return 0, false
 }
 
 // IsRetryableError determines if an error or status code is retryable
@@ -0,0 +1,313 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fastRetries keeps retry tests quick
+var fastRetries = RetryConfig{
+	MaxRetries:     2,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     10 * time.Millisecond,
+	BackoffFactor:  2,
+}
+
+func TestNewTransportTimeouts(t *testing.T) {
+	transport := NewTransport(TimeoutConfig{DialTimeout: 3 * time.Second, ResponseHeaderTimeout: 7 * time.Second})
+	if transport.ResponseHeaderTimeout != 7*time.Second {
+		t.Errorf("ResponseHeaderTimeout = %v, want 7s", transport.ResponseHeaderTimeout)
+	}
+	if transport.DialContext == nil {
+		t.Error("DialContext is not set")
+	}
+}
+
+func TestSetTimeoutsReplacesTransport(t *testing.T) {
+	c := NewRateLimitedClient(1000, 1000, time.Minute)
+	c.SetTimeouts(TimeoutConfig{DialTimeout: time.Second, ResponseHeaderTimeout: 2 * time.Second})
+
+	transport, ok := c.client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("transport is %T, want *http.Transport", c.client.Transport)
+	}
+	if transport.ResponseHeaderTimeout != 2*time.Second {
+		t.Errorf("ResponseHeaderTimeout = %v, want 2s", transport.ResponseHeaderTimeout)
+	}
+	if c.readTimeout != time.Minute {
+		t.Errorf("read timeout = %v, want it left at 1m", c.readTimeout)
+	}
+	if c.client.Timeout != 0 {
+		t.Errorf("total timeout = %v, want none", c.client.Timeout)
+	}
+}
+
+// trickleServer writes chunks of body, pausing for interval before each one
+func trickleServer(t *testing.T, chunks int, interval time.Duration) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		for i := 0; i < chunks; i++ {
+			select {
+			case <-time.After(interval):
+			case <-r.Context().Done():
+				return
+			}
+			fmt.Fprint(w, "chunk;")
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestSlowProgressingBodyIsReadToTheEnd(t *testing.T) {
+	// The body takes ~1s in total, far beyond the 200ms read timeout, but
+	// never stalls for longer than 50ms
+	server := trickleServer(t, 20, 50*time.Millisecond)
+	c := NewRateLimitedClientWithConfig(1000, 1000, 200*time.Millisecond, RetryConfig{MaxRetries: 0, BackoffFactor: 1})
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading a slow but progressing body: %v", err)
+	}
+	if got := strings.Count(string(body), "chunk;"); got != 20 {
+		t.Errorf("read %d chunks, want 20", got)
+	}
+}
+
+func TestStalledBodyHitsReadTimeout(t *testing.T) {
+	server := trickleServer(t, 2, 500*time.Millisecond)
+	c := NewRateLimitedClientWithConfig(1000, 1000, 100*time.Millisecond, RetryConfig{MaxRetries: 0, BackoffFactor: 1})
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	defer resp.Body.Close()
+	if _, err := io.ReadAll(resp.Body); !errors.Is(err, ErrReadTimeout) {
+		t.Errorf("error = %v, want ErrReadTimeout", err)
+	}
+}
+
+func TestResponseHeaderTimeoutFailsFast(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	c := NewRateLimitedClientWithConfig(1000, 1000, time.Minute, RetryConfig{MaxRetries: 0, BackoffFactor: 1})
+	c.SetTimeouts(TimeoutConfig{DialTimeout: time.Second, ResponseHeaderTimeout: 50 * time.Millisecond})
+
+	req, _ := http.NewRequest("GET", server.URL, nil)
+	start := time.Now()
+	_, err := c.Do(req)
+	if err == nil {
+		t.Fatal("expected a timeout error")
+	}
+	if !strings.Contains(err.Error(), "timeout awaiting response headers") {
+		t.Errorf("error = %v, want a response header timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("request took %v; the header timeout should fail it long before the 1m read timeout", elapsed)
+	}
+}
+
+func TestDialTimeoutBoundsConnect(t *testing.T) {
+	transport := NewTransport(TimeoutConfig{DialTimeout: 100 * time.Millisecond, ResponseHeaderTimeout: time.Second})
+
+	// A non-routable address never answers, so only the dial timeout ends the attempt
+	start := time.Now()
+	conn, err := transport.DialContext(context.Background(), "tcp", "10.255.255.1:81")
+	if err == nil {
+		conn.Close()
+		t.Skip("the test address is reachable from this network")
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("dial took %v, want it bounded by the 100ms dial timeout", elapsed)
+	}
+}
+
+// retryAfterServer answers the first request with 429 and Retry-After, then 200
+func retryAfterServer(t *testing.T, retryAfter string) (*httptest.Server, *atomic.Int64) {
+	t.Helper()
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", retryAfter)
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+func TestRetryAfterIsHonored(t *testing.T) {
+	server, requests := retryAfterServer(t, "2")
+	client := NewRateLimitedClientWithConfig(100, 10, 10*time.Second, RetryConfig{
+		MaxRetries:     1,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		BackoffFactor:  2,
+	})
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	start := time.Now()
+	resp, err := client.Do(req)
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || requests.Load() != 2 {
+		t.Errorf("got status %d after %d requests, want 200 after 2", resp.StatusCode, requests.Load())
+	}
+	// The server's 2s replaces the 1ms computed backoff
+	if elapsed < 1900*time.Millisecond {
+		t.Errorf("retried after %v, want the Retry-After of 2s", elapsed)
+	}
+}
+
+func TestRetryAfterIsCappedByMaxBackoff(t *testing.T) {
+	server, requests := retryAfterServer(t, "2")
+	client := NewRateLimitedClientWithConfig(100, 10, 10*time.Second, fastRetries)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	start := time.Now()
+	resp, err := client.Do(req)
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	resp.Body.Close()
+
+	if requests.Load() != 2 {
+		t.Errorf("made %d requests, want 2", requests.Load())
+	}
+	if elapsed > time.Second {
+		t.Errorf("retried after %v, want the wait capped at MaxBackoff (%v)", elapsed, fastRetries.MaxBackoff)
+	}
+	if hits := client.Stats().MaxBackoffHits; hits != 1 {
+		t.Errorf("MaxBackoffHits = %d, want 1", hits)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOK bool
+	}{
+		{value: "2", want: 2 * time.Second, wantOK: true},
+		{value: " 0 ", want: 0, wantOK: true},
+		{value: now.Add(90 * time.Second).Format(http.TimeFormat), want: 90 * time.Second, wantOK: true},
+		{value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0, wantOK: true}, // already passed
+		{value: ""},
+		{value: "-1"},
+		{value: "soon"},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("parseRetryAfter(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestRetriesExhaustedKeepsLastStatus(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	client := NewRateLimitedClientWithConfig(100, 10, 10*time.Second, fastRetries)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := client.Do(req)
+	if resp != nil {
+		resp.Body.Close()
+		t.Fatal("expected no response alongside the error; its body would leak")
+	}
+
+	var se *StructuredError
+	if !errors.As(fmt.Errorf("fetch failed: %w", err), &se) {
+		t.Fatalf("error %v is not a StructuredError", err)
+	}
+	if se.StatusCode() != http.StatusServiceUnavailable {
+		t.Errorf("StatusCode() = %d, want 503", se.StatusCode())
+	}
+	if want := int64(fastRetries.MaxRetries + 1); requests.Load() != want {
+		t.Errorf("made %d requests, want %d", requests.Load(), want)
+	}
+}
+
+// failingServer answers the first failures requests with 503, then 200
+func failingServer(t *testing.T, failures int64) *httptest.Server {
+	t.Helper()
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= failures {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestBackoffTimeAccumulatesAcrossRetries(t *testing.T) {
+	server := failingServer(t, 1)
+	client := NewRateLimitedClientWithConfig(100, 10, 10*time.Second, fastRetries)
+
+	var previous time.Duration
+	for i := 1; i <= 2; i++ {
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("request %d: %v", i, err)
+		}
+		resp.Body.Close()
+
+		stats := client.Stats()
+		if i == 1 && stats.BackoffTime <= previous {
+			t.Errorf("BackoffTime = %v after a forced retry, want it above %v", stats.BackoffTime, previous)
+		}
+		if i == 2 && stats.BackoffTime != previous {
+			t.Errorf("BackoffTime = %v after a request without retries, want it to stay %v", stats.BackoffTime, previous)
+		}
+		previous = stats.BackoffTime
+	}
+
+	server = failingServer(t, 2)
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	resp.Body.Close()
+	if stats := client.Stats(); stats.BackoffTime <= previous || stats.Retries != 3 {
+		t.Errorf("after two more retries: BackoffTime = %v, Retries = %d; want above %v and 3", stats.BackoffTime, stats.Retries, previous)
+	}
+}
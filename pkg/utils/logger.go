@@ -74,6 +74,13 @@ func (l *Logger) SetLevel(level LogLevel) {
 	l.level = level
 }
 
+// SetColorize turns ANSI colors for log levels on or off
+func (l *Logger) SetColorize(colorize bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.colorize = colorize
+}
+
 // log writes a log entry
 func (l *Logger) log(level LogLevel, msg string, fields map[string]interface{}) {
 	if level < l.level {
@@ -195,12 +202,53 @@ func (l *Logger) Error(msg string, fields ...map[string]interface{}) {
 	l.log(ErrorLevel, msg, mergeFields(fields...))
 }
 
-// Fatal logs a fatal error message and exits
+// Fatal logs a fatal error message, flushes the output and exits
 func (l *Logger) Fatal(msg string, fields ...map[string]interface{}) {
 	l.log(FatalLevel, msg, mergeFields(fields...))
+	l.Close()
 	os.Exit(1)
 }
 
+// Flush writes out any entries buffered by the output. Outputs that buffer
+// (files, async writers) implement Flush or Sync; plain writers need nothing.
+func (l *Logger) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.flush()
+}
+
+// flush flushes the output; the caller must hold l.mu
+func (l *Logger) flush() error {
+	switch out := l.output.(type) {
+	case interface{ Flush() error }:
+		return out.Flush()
+	case interface{ Sync() error }:
+		// Sync on a terminal or pipe fails harmlessly, so only files report errors
+		if err := out.Sync(); err != nil && out != os.Stdout && out != os.Stderr {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close flushes the output and closes it if it can be closed. The standard
+// streams are flushed but never closed. Call it before the process exits.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.flush(); err != nil {
+		return err
+	}
+	if l.output == os.Stdout || l.output == os.Stderr {
+		return nil
+	}
+	if closer, ok := l.output.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
 // WithFields creates a new logger with default fields
 func (l *Logger) WithFields(fields map[string]interface{}) *Logger {
 	newLogger := &Logger{
@@ -239,8 +287,9 @@ func Info(msg string, fields ...map[string]interface{}) {
 	}
 }
 
-// Warn logs a warning message using the default logger
+// Warn logs a warning message using the default logger and records it (see RecordWarning)
 func Warn(msg string, fields ...map[string]interface{}) {
+	RecordWarning(msg)
 	if DefaultLogger != nil {
 		DefaultLogger.Warn(msg, fields...)
 	}
@@ -263,6 +312,14 @@ func Fatal(msg string, fields ...map[string]interface{}) {
 	}
 }
 
+// CloseLogger flushes and closes the default logger's output
+func CloseLogger() error {
+	if DefaultLogger != nil {
+		return DefaultLogger.Close()
+	}
+	return nil
+}
+
 // ParseLogLevel parses a string log level
 func ParseLogLevel(level string) LogLevel {
 	switch strings.ToLower(level) {
@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// asyncWriter hands writes to a background goroutine that appends them to buf
+// after a delay, like a network or batching log sink. Flush waits until every
+// write so far has landed.
+type asyncWriter struct {
+	entries chan []byte
+	pending sync.WaitGroup
+	done    chan struct{}
+
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	closed bool
+}
+
+func newAsyncWriter() *asyncWriter {
+	w := &asyncWriter{entries: make(chan []byte, 64), done: make(chan struct{})}
+	go func() {
+		defer close(w.done)
+		for p := range w.entries {
+			time.Sleep(time.Millisecond)
+			w.mu.Lock()
+			w.buf.Write(p)
+			w.mu.Unlock()
+			w.pending.Done()
+		}
+	}()
+	return w
+}
+
+func (w *asyncWriter) Write(p []byte) (int, error) {
+	w.pending.Add(1)
+	w.entries <- append([]byte(nil), p...)
+	return len(p), nil
+}
+
+func (w *asyncWriter) Flush() error {
+	w.pending.Wait()
+	return nil
+}
+
+func (w *asyncWriter) Close() error {
+	close(w.entries)
+	<-w.done
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.closed = true
+	return nil
+}
+
+func (w *asyncWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestLoggerCloseFlushesAsyncOutput(t *testing.T) {
+	out := newAsyncWriter()
+	logger := NewLogger(DebugLevel, out, "", false)
+
+	const n = 20
+	for i := 0; i < n; i++ {
+		logger.Info(fmt.Sprintf("entry %d", i))
+	}
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	got := out.String()
+	for i := 0; i < n; i++ {
+		if !strings.Contains(got, fmt.Sprintf("entry %d\n", i)) {
+			t.Errorf("entry %d missing after Close:\n%s", i, got)
+		}
+	}
+	if !out.closed {
+		t.Error("Close did not close the output")
+	}
+}
+
+func TestLoggerFlushWritesBufferedOutput(t *testing.T) {
+	var buf bytes.Buffer
+	out := bufio.NewWriterSize(&buf, 4096)
+	logger := NewLogger(DebugLevel, out, "", false)
+
+	logger.Warn("disk almost full")
+	if buf.Len() != 0 {
+		t.Fatalf("entry reached the buffer before Flush: %q", buf.String())
+	}
+	if err := logger.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if !strings.Contains(buf.String(), "WARN disk almost full") {
+		t.Errorf("output after Flush = %q, want the warning", buf.String())
+	}
+}
+
+func TestCloseLoggerWithoutDefaultLogger(t *testing.T) {
+	saved := DefaultLogger
+	DefaultLogger = nil
+	t.Cleanup(func() { DefaultLogger = saved })
+
+	if err := CloseLogger(); err != nil {
+		t.Errorf("CloseLogger = %v, want nil", err)
+	}
+}
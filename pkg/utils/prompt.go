@@ -3,23 +3,46 @@ package utils
 import (
 	"bufio"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	"golang.org/x/term"
 )
 
+// promptInput is where prompts read their answers. One buffered reader is
+// shared by all prompts so input read ahead by one isn't lost to the next.
+var promptInput = bufio.NewReader(os.Stdin)
+
+// promptFromStdin reports whether promptInput reads stdin, whose echo can be
+// turned off for masked prompts
+var promptFromStdin = true
+
+// SetPromptInput makes prompts read their answers from r, e.g. scripted input
+// in tests. Masked prompts read from r as plain lines unless r is stdin.
+func SetPromptInput(r io.Reader) {
+	promptInput = bufio.NewReader(r)
+	promptFromStdin = r == os.Stdin
+}
+
+// readLine reads a line of input without its surrounding whitespace
+func readLine() string {
+	input, _ := promptInput.ReadString('\n')
+	return strings.TrimSpace(input)
+}
+
 // Prompt displays a prompt and reads user input
 func Prompt(label string) string {
 	fmt.Print(label)
-	reader := bufio.NewReader(os.Stdin)
-	input, _ := reader.ReadString('\n')
-	return strings.TrimSpace(input)
+	return readLine()
 }
 
 // PromptMasked displays a prompt and reads user input without echoing (for passwords)
 func PromptMasked(label string) string {
 	fmt.Print(label)
+	if !promptFromStdin {
+		return readLine()
+	}
 
 	// Get terminal file descriptor
 	fd := int(os.Stdin.Fd())
@@ -29,9 +52,7 @@ func PromptMasked(label string) string {
 	if err != nil {
 		// Fallback to regular input if terminal doesn't support password input
 		fmt.Print(" (fallback mode): ")
-		reader := bufio.NewReader(os.Stdin)
-		input, _ := reader.ReadString('\n')
-		return strings.TrimSpace(input)
+		return readLine()
 	}
 
 	fmt.Println() // Add newline after masked input
@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/olekukonko/tablewriter/tw"
+)
+
+// tableStyles maps display.table_style values to tablewriter border styles
+var tableStyles = map[string]tw.BorderStyle{
+	"fancy":    tw.StyleLight,
+	"ascii":    tw.StyleASCII,
+	"rounded":  tw.StyleRounded,
+	"markdown": tw.StyleMarkdown,
+}
+
+// Table buffers a header and rows and renders them with tablewriter. It is the
+// only place that calls the tablewriter API, so version changes stay here.
+type Table struct {
+	w        io.Writer
+	style    tw.BorderStyle
+	numeric  []bool
+	header   []string
+	rows     [][]string
+	rendered bool
+}
+
+// NewTable creates a table that renders to w in the named style (fancy, ascii,
+// rounded, or markdown; unknown styles fall back to fancy). Columns marked in
+// numeric are right-aligned and the rest left-aligned.
+func NewTable(w io.Writer, style string, numeric []bool) *Table {
+	borders, ok := tableStyles[style]
+	if !ok {
+		borders = tw.StyleLight
+	}
+	return &Table{w: w, style: borders, numeric: numeric}
+}
+
+// AddHeader sets the column headers
+func (t *Table) AddHeader(headers []string) {
+	t.header = headers
+}
+
+// AddRow appends a row of cells
+func (t *Table) AddRow(row []string) {
+	t.rows = append(t.rows, row)
+}
+
+// Render writes the table to its writer. A table can only be rendered once.
+func (t *Table) Render() error {
+	if t.rendered {
+		return fmt.Errorf("table already rendered")
+	}
+	t.rendered = true
+
+	// tablewriter measures cells without their color codes, so colored numbers stay aligned
+	align := make([]tw.Align, len(t.numeric))
+	for i, numeric := range t.numeric {
+		align[i] = tw.AlignLeft
+		if numeric {
+			align[i] = tw.AlignRight
+		}
+	}
+
+	table := tablewriter.NewTable(t.w,
+		tablewriter.WithSymbols(tw.NewSymbols(t.style)),
+		tablewriter.WithRowAlignmentConfig(tw.CellAlignment{Global: tw.AlignLeft, PerColumn: align}),
+	)
+	if len(t.header) > 0 {
+		table.Header(t.header)
+	}
+	if err := table.Bulk(t.rows); err != nil {
+		return fmt.Errorf("failed to build table: %w", err)
+	}
+	if err := table.Render(); err != nil {
+		return fmt.Errorf("failed to render table: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTableRendersIntoBuffer(t *testing.T) {
+	var buf bytes.Buffer
+	table := NewTable(&buf, "ascii", []bool{false, true})
+	table.AddHeader([]string{"Model", "Tokens"})
+	table.AddRow([]string{"gpt-4o", "1500"})
+	table.AddRow([]string{"o1", "7"})
+	if err := table.Render(); err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	want := []string{
+		"+--------+--------+",
+		"| MODEL  | TOKENS |",
+		"+--------+--------+",
+		"| gpt-4o |   1500 |",
+		"| o1     |      7 |",
+		"+--------+--------+",
+	}
+	if strings.Join(lines, "\n") != strings.Join(want, "\n") {
+		t.Errorf("rendered table:\n%s\nwant:\n%s", buf.String(), strings.Join(want, "\n"))
+	}
+
+	if err := table.Render(); err == nil {
+		t.Error("second Render succeeded, want an error")
+	}
+}
+
+func TestTableStyles(t *testing.T) {
+	tests := map[string]string{
+		"fancy":    "┌",
+		"rounded":  "╭",
+		"markdown": "|--------|--------|",
+		"unknown":  "┌", // falls back to fancy
+	}
+	for style, want := range tests {
+		var buf bytes.Buffer
+		table := NewTable(&buf, style, []bool{false, true})
+		table.AddHeader([]string{"Model", "Tokens"})
+		table.AddRow([]string{"gpt-4o", "1500"})
+		if err := table.Render(); err != nil {
+			t.Fatalf("%s: Render: %v", style, err)
+		}
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("%s: output has no %q:\n%s", style, want, buf.String())
+		}
+	}
+}
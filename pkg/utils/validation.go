@@ -8,6 +8,40 @@ import (
 	"time"
 )
 
+// validationHeaders are extra headers sent with key validation requests,
+// e.g. for an API gateway in front of OpenAI
+var validationHeaders map[string]string
+
+// SetValidationHeaders sets extra headers sent with every key validation request
+func SetValidationHeaders(headers map[string]string) {
+	validationHeaders = headers
+}
+
+// validationBaseURL is the OpenAI API that key validation requests go to
+var validationBaseURL = "https://api.openai.com/v1"
+
+// SetValidationBaseURL points key validation requests at another
+// OpenAI-compatible API, such as a test server
+func SetValidationBaseURL(url string) {
+	validationBaseURL = strings.TrimSuffix(url, "/")
+}
+
+// ApplyHeaders sets each header in headers on req
+func ApplyHeaders(req *http.Request, headers map[string]string) {
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+}
+
+// RedactedHeaders returns the header names in headers for logging, with values hidden
+func RedactedHeaders(headers map[string]string) map[string]string {
+	redacted := make(map[string]string, len(headers))
+	for name := range headers {
+		redacted[http.CanonicalHeaderKey(name)] = "[REDACTED]"
+	}
+	return redacted
+}
+
 // ValidateOpenAIKey validates an OpenAI API key by making a test request
 func ValidateOpenAIKey(apiKey string) error {
 	if apiKey == "" {
@@ -23,11 +57,12 @@ func ValidateOpenAIKey(apiKey string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.openai.com/v1/models", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", validationBaseURL+"/models", nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
+	ApplyHeaders(req, validationHeaders)
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
 
 	client := &http.Client{Timeout: 10 * time.Second}
@@ -61,14 +96,15 @@ func validateOpenAIUsageAccess(apiKey string) error {
 	endTime := time.Now()
 	startTime := endTime.Add(-24 * time.Hour)
 
-	url := fmt.Sprintf("https://api.openai.com/v1/organization/usage/completions?start_time=%d&end_time=%d",
-		startTime.Unix(), endTime.Unix())
+	url := fmt.Sprintf("%s/organization/usage/completions?start_time=%d&end_time=%d",
+		validationBaseURL, startTime.Unix(), endTime.Unix())
 
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
+	ApplyHeaders(req, validationHeaders)
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
 
 	client := &http.Client{Timeout: 10 * time.Second}
@@ -124,3 +160,24 @@ func validateGenericKey(platform, apiKey string) error {
 	Warn(fmt.Sprintf("%s platform validation not yet implemented, accepting key", platform))
 	return nil
 }
+
+// ValidateDateFormat checks that layout is a usable Go reference-time layout
+// (e.g. "2006-01-02 15:04:05")
+func ValidateDateFormat(layout string) error {
+	if strings.TrimSpace(layout) == "" {
+		return NewValidationError("date format", "layout cannot be empty")
+	}
+
+	// A layout without any reference-time elements formats to itself
+	sample := time.Date(2001, time.February, 3, 4, 5, 6, 0, time.UTC)
+	formatted := sample.Format(layout)
+	if formatted == layout {
+		return NewValidationError("date format", fmt.Sprintf("%q contains no date or time elements; use Go reference time such as 2006-01-02 15:04:05", layout))
+	}
+
+	if _, err := time.Parse(layout, formatted); err != nil {
+		return NewValidationError("date format", fmt.Sprintf("%q is not a valid Go time layout: %v", layout, err))
+	}
+
+	return nil
+}
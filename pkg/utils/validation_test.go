@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestApplyHeaders(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	ApplyHeaders(req, map[string]string{"x-gateway-key": "gw-123", "X-Team": "billing"})
+	if got := req.Header.Get("X-Gateway-Key"); got != "gw-123" {
+		t.Errorf("X-Gateway-Key = %q, want gw-123", got)
+	}
+	if got := req.Header.Get("X-Team"); got != "billing" {
+		t.Errorf("X-Team = %q, want billing", got)
+	}
+}
+
+func TestRedactedHeadersHidesValues(t *testing.T) {
+	got := RedactedHeaders(map[string]string{"x-gateway-key": "gw-123"})
+	if len(got) != 1 || got["X-Gateway-Key"] != "[REDACTED]" {
+		t.Errorf("RedactedHeaders = %v, want the canonical name with a redacted value", got)
+	}
+}
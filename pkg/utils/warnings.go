@@ -0,0 +1,30 @@
+package utils
+
+import "sync"
+
+var (
+	warningsMu sync.Mutex
+	warnings   []string
+)
+
+// RecordWarning remembers msg as a warning raised during this run, so strict
+// mode can fail once output has been rendered. Warn records automatically.
+func RecordWarning(msg string) {
+	warningsMu.Lock()
+	defer warningsMu.Unlock()
+	warnings = append(warnings, msg)
+}
+
+// Warnings returns the warnings recorded so far, in order
+func Warnings() []string {
+	warningsMu.Lock()
+	defer warningsMu.Unlock()
+	return append([]string(nil), warnings...)
+}
+
+// ResetWarnings forgets all recorded warnings
+func ResetWarnings() {
+	warningsMu.Lock()
+	defer warningsMu.Unlock()
+	warnings = nil
+}